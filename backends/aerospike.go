@@ -3,6 +3,7 @@ package backends
 import (
 	"context"
 	"errors"
+	"io"
 	"time"
 
 	as "github.com/aerospike/aerospike-client-go/v6"
@@ -38,6 +39,12 @@ func (db AerospikeDBClient) Put(policy *as.WritePolicy, key *as.Key, binMap as.B
 	return db.client.Put(policy, key, binMap)
 }
 
+// Close closes the connections of the as.Client to the Aerospike cluster
+func (db *AerospikeDBClient) Close() error {
+	db.client.Close()
+	return nil
+}
+
 // NewUUIDKey creates an aerospike key so we can store data under it
 func (db *AerospikeDBClient) NewUUIDKey(namespace string, key string) (*as.Key, error) {
 	return as.NewKey(namespace, setName, key)
@@ -105,7 +112,8 @@ func NewAerospikeBackend(cfg config.Aerospike, metrics *metrics.Metrics) *Aerosp
 }
 
 // Get creates an aerospike key based on the UUID key parameter, perfomrs the client's Get call
-// and validates results. Can return a KEY_NOT_FOUND error or other Aerospike server errors
+// and validates results. Can return a KEY_NOT_FOUND error or other Aerospike server errors. The
+// time the record expires at is recorded in the context.
 func (a *AerospikeBackend) Get(ctx context.Context, key string) (string, error) {
 	asKey, err := a.client.NewUUIDKey(a.namespace, key)
 	if err != nil {
@@ -129,6 +137,11 @@ func (a *AerospikeBackend) Get(ctx context.Context, key string) (string, error)
 		return "", errors.New("Unexpected non-string value found")
 	}
 
+	// The client reports the seconds the record has left to live, or TTLDontExpire
+	if rec.Expiration > 0 && rec.Expiration != as.TTLDontExpire {
+		utils.RecordValueExpiration(ctx, time.Now().Add(time.Duration(rec.Expiration)*time.Second))
+	}
+
 	return str, nil
 }
 
@@ -167,3 +180,11 @@ func classifyAerospikeError(err error) error {
 	}
 	return err
 }
+
+// Close releases the connections to the Aerospike cluster
+func (a *AerospikeBackend) Close() error {
+	if closer, ok := a.client.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	as "github.com/aerospike/aerospike-client-go/v6"
 	as_types "github.com/aerospike/aerospike-client-go/v6/types"
@@ -151,7 +152,7 @@ func TestAerospikeClientGet(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 	aerospikeBackend := &AerospikeBackend{
@@ -226,11 +227,55 @@ func TestAerospikeClientGet(t *testing.T) {
 	}
 }
 
+func TestAerospikeClientGetExpiration(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		inExpiration   uint32
+		expectedExpiry bool
+	}{
+		{
+			desc:           "Record expires",
+			inExpiration:   90,
+			expectedExpiry: true,
+		},
+		{
+			desc:         "Record doesn't expire",
+			inExpiration: as.TTLDontExpire,
+		},
+	}
+
+	for _, tc := range testCases {
+		aerospikeBackend := &AerospikeBackend{
+			client: &goodAerospikeClient{
+				records: map[string]*as.Record{
+					"defaultKey": {
+						Bins:       as.BinMap{binValue: "Default value"},
+						Expiration: tc.inExpiration,
+					},
+				},
+			},
+		}
+		expiration := &utils.ValueExpiration{}
+
+		before := time.Now()
+		_, err := aerospikeBackend.Get(utils.WithValueExpiration(context.Background(), expiration), "defaultKey")
+		after := time.Now()
+
+		assert.NoError(t, err, tc.desc)
+		expiresAt, ok := expiration.ExpiresAt()
+		if assert.Equal(t, tc.expectedExpiry, ok, tc.desc) && ok {
+			ttl := time.Duration(tc.inExpiration) * time.Second
+			assert.False(t, expiresAt.Before(before.Add(ttl)), tc.desc)
+			assert.False(t, expiresAt.After(after.Add(ttl)), tc.desc)
+		}
+	}
+}
+
 func TestClientPut(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 	aerospikeBackend := &AerospikeBackend{
@@ -2,10 +2,26 @@ package backends
 
 import (
 	"context"
+
+	"github.com/prebid/prebid-cache/config"
 )
 
-// Backend interface for storing data
+// Backend interface for storing data. Backends that keep connections to a storage service open
+// also implement io.Closer, so those connections can be released when Prebid Cache shuts down.
 type Backend interface {
 	Put(ctx context.Context, key string, value string, ttlSeconds int) error
 	Get(ctx context.Context, key string) (string, error)
 }
+
+// TTLLimiter is implemented by backends that store values with another time-to-live than the one
+// they were put with, such as the one enforcing the request limits
+type TTLLimiter interface {
+	// LimitTTL returns the time-to-live values put with requestTTLSeconds are stored with
+	LimitTTL(requestTTLSeconds int) int
+}
+
+// CredentialsUpdater is implemented by backends that can switch to rotated credentials without
+// reconnecting. Backends that don't implement it use the credentials found at startup.
+type CredentialsUpdater interface {
+	UpdateCredentials(cfg config.Backend)
+}
@@ -2,6 +2,7 @@ package backends
 
 import (
 	"context"
+	"io"
 
 	"github.com/gocql/gocql"
 	"github.com/prebid/prebid-cache/config"
@@ -48,6 +49,14 @@ func (c *CassandraDBClient) Put(ctx context.Context, key string, value string, t
 		ScanCAS(&insertedKey, &insertedValue)
 }
 
+// Close closes the Cassandra session along with its connections
+func (c *CassandraDBClient) Close() error {
+	if c.session != nil {
+		c.session.Close()
+	}
+	return nil
+}
+
 // Init initializes Cassandra cluster and session with the configuration
 // loaded from environment variables or configuration files at startup
 func (c *CassandraDBClient) Init() error {
@@ -106,3 +115,11 @@ func (back *CassandraBackend) Put(ctx context.Context, key string, value string,
 	}
 	return err
 }
+
+// Close releases the connections to the Cassandra cluster
+func (back *CassandraBackend) Close() error {
+	if closer, ok := back.client.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
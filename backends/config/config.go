@@ -15,8 +15,27 @@ import (
 )
 
 func NewBackend(cfg config.Configuration, appMetrics *metrics.Metrics) backends.Backend {
-	backend := newBaseBackend(cfg.Backend, appMetrics)
+	return DecorateBackend(cfg, NewBaseBackend(cfg, appMetrics), appMetrics)
+}
+
+// NewBaseBackend connects to the storage configured in cfg.Backend, without any decorators
+func NewBaseBackend(cfg config.Configuration, appMetrics *metrics.Metrics) backends.Backend {
+	return newBaseBackend(cfg.Backend, appMetrics)
+}
+
+// DecorateBackend wraps a base backend with tracing, compression, metadata, size and TTL limits and metrics. Decorators
+// hold no connections, so they can be re-applied to the same base backend when the request limits
+// change at runtime.
+func DecorateBackend(cfg config.Configuration, backend backends.Backend, appMetrics *metrics.Metrics) backends.Backend {
+	if cfg.Tracing.Enabled {
+		// Traced right around the storage, so the backend spans leave out the compression
+		backend = decorators.TraceBackend(backend, string(cfg.Backend.Type))
+	}
 	backend = applyCompression(cfg.Compression, backend)
+	if cfg.Backend.StoreMetadata {
+		// The metadata is compressed along with the value
+		backend = decorators.StoreMetadata(backend, appMetrics)
+	}
 	if cfg.RequestLimits.MaxSize > 0 {
 		backend = decorators.EnforceSizeLimit(backend, cfg.RequestLimits.MaxSize)
 	}
@@ -94,7 +94,7 @@ func TestNewMemoryOrMemcacheBackend(t *testing.T) {
 		mockMetrics := metricstest.CreateMockMetrics()
 		m := &metrics.Metrics{
 			MetricEngines: []metrics.CacheMetrics{
-				&mockMetrics,
+				mockMetrics,
 			},
 		}
 
@@ -161,7 +161,7 @@ func TestNewBaseBackend(t *testing.T) {
 		mockMetrics := metricstest.CreateMockMetrics()
 		m := &metrics.Metrics{
 			MetricEngines: []metrics.CacheMetrics{
-				&mockMetrics,
+				mockMetrics,
 			},
 		}
 
@@ -184,6 +184,62 @@ func TestNewBaseBackend(t *testing.T) {
 	}
 }
 
+func TestDecorateBackendSharesBaseBackend(t *testing.T) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			mockMetrics,
+		},
+	}
+	cfg := config.Configuration{
+		Backend:       config.Backend{Type: config.BackendMemory},
+		Compression:   config.Compression{Type: config.CompressionSnappy},
+		RequestLimits: config.RequestLimits{MaxSize: 10, MaxTTLSeconds: 60},
+	}
+	base := NewBaseBackend(cfg, m)
+
+	// Store a value under the initial request limits
+	initialBackend := DecorateBackend(cfg, base, m)
+	assert.NoError(t, initialBackend.Put(context.Background(), "small", "xml<a/>", 0), "Value within the initial size limit")
+	assert.Error(t, initialBackend.Put(context.Background(), "large", "xml<vast></vast>", 0), "Value exceeds the initial size limit")
+
+	// Raise the size limit and decorate the same base backend again
+	cfg.RequestLimits.MaxSize = 100
+	reloadedBackend := DecorateBackend(cfg, base, m)
+	assert.NoError(t, reloadedBackend.Put(context.Background(), "large", "xml<vast></vast>", 0), "Value within the new size limit")
+
+	value, err := reloadedBackend.Get(context.Background(), "small")
+	assert.NoError(t, err, "Values stored before decorating again should still be there")
+	assert.Equal(t, "xml<a/>", value)
+}
+
+func TestDecorateBackendStoresMetadata(t *testing.T) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			mockMetrics,
+		},
+	}
+	cfg := config.Configuration{
+		Backend:       config.Backend{Type: config.BackendMemory, StoreMetadata: true},
+		Compression:   config.Compression{Type: config.CompressionNone},
+		RequestLimits: config.RequestLimits{MaxTTLSeconds: 60},
+	}
+	base := NewBaseBackend(cfg, m)
+	backend := DecorateBackend(cfg, base, m)
+
+	assert.NoError(t, backend.Put(context.Background(), "foo", "xml<a/>", 0))
+
+	stored, err := base.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^pbc1:\d+:60:xml<a/>$`, stored, "The metadata holds the TTL after it's limited")
+
+	value, err := backend.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "xml<a/>", value)
+	mockMetrics.AssertCalled(t, "RecordGetBackendValueAge")
+}
+
 func TestGetMaxTTLSeconds(t *testing.T) {
 	const SIXTY_SECONDS = 60
 	type testCases struct {
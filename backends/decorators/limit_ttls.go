@@ -30,12 +30,15 @@ type ttlLimited struct {
 // Put will make the delegate.Put() call with the default l.maxTTLSeconds whenever the
 // request-defined ttl value is out of bounds
 func (l ttlLimited) Put(ctx context.Context, key string, value string, requestTTLSeconds int) error {
-	ttl := l.maxTTLSeconds
+	return l.Backend.Put(ctx, key, value, l.LimitTTL(requestTTLSeconds))
+}
 
+// LimitTTL returns the request-defined ttl value, or l.maxTTLSeconds if it's out of bounds
+func (l ttlLimited) LimitTTL(requestTTLSeconds int) int {
 	if l.maxTTLSeconds > requestTTLSeconds && requestTTLSeconds > 0 {
-		ttl = requestTTLSeconds
+		return requestTTLSeconds
 	}
-	return l.Backend.Put(ctx, key, value, ttl)
+	return l.maxTTLSeconds
 }
 
 // Get will somply make the delegate.Get() call given that no TTL check is needed on the GET side
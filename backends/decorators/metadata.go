@@ -0,0 +1,105 @@
+package decorators
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// metadataPrefix starts the values stored with metadata. Values put by Prebid Cache always start
+// with "json" or "xml", so they can't be mistaken for one of these.
+const metadataPrefix = "pbc1:"
+
+// valueMetadata describes a stored value
+type valueMetadata struct {
+	createdAt  time.Time
+	ttlSeconds int
+}
+
+type backendWithMetadata struct {
+	delegate backends.Backend
+	metrics  *metrics.Metrics
+	now      func() time.Time
+}
+
+// StoreMetadata stores the time a value was put and its TTL along with it, as a
+// "pbc1:<unix millis>:<ttl seconds>:" header, and records the age of the values when they are
+// read, along with the time they expire at. Values stored without the header are returned as
+// they are.
+func StoreMetadata(backend backends.Backend, m *metrics.Metrics) backends.Backend {
+	return &backendWithMetadata{
+		delegate: backend,
+		metrics:  m,
+		now:      time.Now,
+	}
+}
+
+func (b *backendWithMetadata) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	metadata := valueMetadata{createdAt: b.now(), ttlSeconds: ttlSeconds}
+	return b.delegate.Put(ctx, key, encodeMetadata(metadata)+value, ttlSeconds)
+}
+
+func (b *backendWithMetadata) Get(ctx context.Context, key string) (string, error) {
+	stored, err := b.delegate.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, value, ok := decodeMetadata(stored)
+	if !ok {
+		return stored, nil
+	}
+
+	age := b.now().Sub(metadata.createdAt)
+	if age < 0 {
+		// The clocks of the instances that put and got the value disagree
+		age = 0
+	}
+	if !utils.InternalRead(ctx) {
+		b.metrics.RecordGetBackendValueAge(age)
+	}
+	if metadata.ttlSeconds > 0 {
+		utils.RecordValueExpiration(ctx, metadata.createdAt.Add(time.Duration(metadata.ttlSeconds)*time.Second))
+	}
+	return value, nil
+}
+
+func encodeMetadata(metadata valueMetadata) string {
+	var header strings.Builder
+	header.WriteString(metadataPrefix)
+	header.WriteString(strconv.FormatInt(metadata.createdAt.UnixNano()/int64(time.Millisecond), 10))
+	header.WriteString(":")
+	header.WriteString(strconv.Itoa(metadata.ttlSeconds))
+	header.WriteString(":")
+	return header.String()
+}
+
+// decodeMetadata splits a stored value into its metadata and the value that was put. It returns
+// false if the value has no valid metadata header.
+func decodeMetadata(stored string) (valueMetadata, string, bool) {
+	if !strings.HasPrefix(stored, metadataPrefix) {
+		return valueMetadata{}, "", false
+	}
+	fields := strings.SplitN(stored[len(metadataPrefix):], ":", 3)
+	if len(fields) != 3 {
+		return valueMetadata{}, "", false
+	}
+	createdAtMillis, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return valueMetadata{}, "", false
+	}
+	ttlSeconds, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return valueMetadata{}, "", false
+	}
+	metadata := valueMetadata{
+		createdAt:  time.Unix(0, createdAtMillis*int64(time.Millisecond)),
+		ttlSeconds: ttlSeconds,
+	}
+	return metadata, fields[2], true
+}
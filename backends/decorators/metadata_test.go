@@ -0,0 +1,118 @@
+package decorators
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	prometheus "github.com/prebid/prebid-cache/metrics/prometheus"
+	"github.com/prebid/prebid-cache/utils"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreMetadata(t *testing.T) {
+	putTime := time.Date(2022, 5, 4, 10, 11, 12, 0, time.UTC)
+
+	testCases := []struct {
+		desc             string
+		inStoredValue    string
+		inGetTime        time.Time
+		inInternalRead   bool
+		expectedValue    string
+		expectedAgeCount uint64
+		expectedAgeSum   float64
+		expectedExpiry   time.Time
+	}{
+		{
+			desc:             "Value stored with metadata",
+			inStoredValue:    "pbc1:1651659072000:60:json{}",
+			inGetTime:        putTime.Add(45 * time.Second),
+			expectedValue:    "json{}",
+			expectedAgeCount: 1,
+			expectedAgeSum:   45,
+			expectedExpiry:   putTime.Add(time.Minute),
+		},
+		{
+			desc:             "Value stored with metadata by an instance with a clock ahead",
+			inStoredValue:    "pbc1:1651659072000:60:json{}",
+			inGetTime:        putTime.Add(-time.Second),
+			expectedValue:    "json{}",
+			expectedAgeCount: 1,
+			expectedAgeSum:   0,
+			expectedExpiry:   putTime.Add(time.Minute),
+		},
+		{
+			desc:           "Value read by Prebid Cache itself",
+			inStoredValue:  "pbc1:1651659072000:60:json{}",
+			inGetTime:      putTime.Add(45 * time.Second),
+			inInternalRead: true,
+			expectedValue:  "json{}",
+			expectedExpiry: putTime.Add(time.Minute),
+		},
+		{
+			desc:          "Value stored before metadata was enabled",
+			inStoredValue: "xml<vast></vast>",
+			inGetTime:     putTime,
+			expectedValue: "xml<vast></vast>",
+		},
+		{
+			desc:          "Malformed metadata",
+			inStoredValue: "pbc1:yesterday:60:json{}",
+			inGetTime:     putTime,
+			expectedValue: "pbc1:yesterday:60:json{}",
+		},
+	}
+
+	for _, tc := range testCases {
+		promMetrics := prometheus.CreatePrometheusMetrics(config.PrometheusMetrics{})
+		m := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{promMetrics}}
+		rawBackend := backends.NewMemoryBackend()
+		rawBackend.Put(context.Background(), "foo", tc.inStoredValue, 0)
+		backend := &backendWithMetadata{delegate: rawBackend, metrics: m, now: func() time.Time { return tc.inGetTime }}
+
+		expiration := &utils.ValueExpiration{}
+		ctx := utils.WithValueExpiration(context.Background(), expiration)
+		if tc.inInternalRead {
+			ctx = utils.WithInternalRead(ctx)
+		}
+		value, err := backend.Get(ctx, "foo")
+
+		assert.NoError(t, err, tc.desc)
+		assert.Equal(t, tc.expectedValue, value, tc.desc)
+		expiresAt, _ := expiration.ExpiresAt()
+		assert.True(t, tc.expectedExpiry.Equal(expiresAt), "%s: expected expiry %v, got %v", tc.desc, tc.expectedExpiry, expiresAt)
+		ages := &dto.Metric{}
+		promMetrics.GetsValues.ValueAge.Write(ages)
+		assert.Equal(t, tc.expectedAgeCount, ages.GetHistogram().GetSampleCount(), tc.desc)
+		assert.Equal(t, tc.expectedAgeSum, ages.GetHistogram().GetSampleSum(), tc.desc)
+	}
+}
+
+func TestStoreMetadataPut(t *testing.T) {
+	rawBackend := backends.NewMemoryBackend()
+	backend := &backendWithMetadata{
+		delegate: rawBackend,
+		metrics:  &metrics.Metrics{},
+		now:      func() time.Time { return time.Date(2022, 5, 4, 10, 11, 12, 345000000, time.UTC) },
+	}
+
+	assert.NoError(t, backend.Put(context.Background(), "foo", "json{}", 60))
+
+	stored, _ := rawBackend.Get(context.Background(), "foo")
+	assert.Equal(t, "pbc1:1651659072345:60:json{}", stored)
+	value, err := backend.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "json{}", value, "The metadata is removed on the way out")
+}
+
+func TestStoreMetadataGetError(t *testing.T) {
+	backend := StoreMetadata(backends.NewMemoryBackend(), &metrics.Metrics{})
+
+	_, err := backend.Get(context.Background(), "missing")
+
+	assert.Error(t, err)
+}
@@ -17,12 +17,27 @@ type backendWithMetrics struct {
 }
 
 func (b *backendWithMetrics) Get(ctx context.Context, key string) (string, error) {
+	if utils.InternalRead(ctx) {
+		// Reads made by Prebid Cache itself would skew the hit ratio and sizes of the client reads
+		start := time.Now()
+		val, err := b.delegate.Get(ctx, key)
+		utils.RecordBackendDuration(ctx, time.Since(start))
+		return val, err
+	}
 
 	b.metrics.RecordGetBackendTotal()
 	start := time.Now()
 	val, err := b.delegate.Get(ctx, key)
+	utils.RecordBackendDuration(ctx, time.Since(start))
 	if err == nil {
 		b.metrics.RecordGetBackendDuration(time.Since(start))
+		b.metrics.RecordGetBackendHit()
+		// Sizes are those of the values sent back to the clients, without the prefix
+		if strings.HasPrefix(val, utils.XML_PREFIX) {
+			b.metrics.RecordGetBackendXmlSize(float64(len(val) - len(utils.XML_PREFIX)))
+		} else if strings.HasPrefix(val, utils.JSON_PREFIX) {
+			b.metrics.RecordGetBackendJsonSize(float64(len(val) - len(utils.JSON_PREFIX)))
+		}
 	} else {
 		if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
 			// If error Type is either KEY_NOT_FOUND or MISSING_KEY, account under the
@@ -53,6 +68,7 @@ func (b *backendWithMetrics) Put(ctx context.Context, key string, value string,
 
 	start := time.Now()
 	err := b.delegate.Put(ctx, key, value, ttlSeconds)
+	utils.RecordBackendDuration(ctx, time.Since(start))
 	if err == nil {
 		b.metrics.RecordPutBackendDuration(time.Since(start))
 	} else {
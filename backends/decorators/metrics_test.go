@@ -6,9 +6,13 @@ import (
 	"testing"
 
 	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/metrics/metricstest"
+	prometheus "github.com/prebid/prebid-cache/metrics/prometheus"
 	"github.com/prebid/prebid-cache/utils"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,13 +33,15 @@ func TestGetBackendMetrics(t *testing.T) {
 	expectedMetrics := []string{
 		"RecordGetBackendTotal",
 		"RecordGetBackendDuration",
+		"RecordGetBackendHit",
+		"RecordGetBackendXmlSize",
 	}
 
 	// Test setup
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 
@@ -50,6 +56,25 @@ func TestGetBackendMetrics(t *testing.T) {
 	metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
 }
 
+func TestGetBackendInternalReadMetrics(t *testing.T) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			mockMetrics,
+		},
+	}
+
+	rawBackend := backends.NewMemoryBackend()
+	rawBackend.Put(context.Background(), "foo", "xml<vast></vast>", 0)
+	backendWithMetrics := LogMetrics(rawBackend, m)
+
+	value, err := backendWithMetrics.Get(utils.WithInternalRead(context.Background()), "foo")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "xml<vast></vast>", value)
+	metricstest.AssertMetrics(t, []string{}, mockMetrics)
+}
+
 func TestGetBackendErrorMetrics(t *testing.T) {
 
 	type testCase struct {
@@ -105,7 +130,7 @@ func TestGetBackendErrorMetrics(t *testing.T) {
 			mockMetrics := metricstest.CreateMockMetrics()
 			m := &metrics.Metrics{
 				MetricEngines: []metrics.CacheMetrics{
-					&mockMetrics,
+					mockMetrics,
 				},
 			}
 			// Create backend with a mock storage that will fail and record metrics
@@ -135,7 +160,7 @@ func TestPutSuccessMetrics(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 	backend := LogMetrics(backends.NewMemoryBackend(), m)
@@ -160,7 +185,7 @@ func TestPutErrorMetrics(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 	backend := LogMetrics(&failedBackend{errors.New("Failure")}, m)
@@ -185,7 +210,7 @@ func TestJsonPayloadMetrics(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 	backend := LogMetrics(backends.NewMemoryBackend(), m)
@@ -210,7 +235,7 @@ func TestInvalidPayloadMetrics(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 	backend := LogMetrics(backends.NewMemoryBackend(), m)
@@ -221,3 +246,46 @@ func TestInvalidPayloadMetrics(t *testing.T) {
 	// Assert
 	metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
 }
+
+func TestGetBackendValueMetrics(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		inValue         string
+		expectedFormat  string
+		expectedSizeSum float64
+	}{
+		{
+			desc:            "JSON value. Its size leaves the prefix out",
+			inValue:         `json{"key":"value"}`,
+			expectedFormat:  "json",
+			expectedSizeSum: 15,
+		},
+		{
+			desc:            "XML value. Its size leaves the prefix out",
+			inValue:         "xml<vast></vast>",
+			expectedFormat:  "xml",
+			expectedSizeSum: 13,
+		},
+	}
+
+	for _, tc := range testCases {
+		promMetrics := prometheus.CreatePrometheusMetrics(config.PrometheusMetrics{})
+		m := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{promMetrics}}
+
+		rawBackend := backends.NewMemoryBackend()
+		rawBackend.Put(context.Background(), "foo", tc.inValue, 0)
+		backend := LogMetrics(rawBackend, m)
+
+		backend.Get(context.Background(), "foo")
+		backend.Get(context.Background(), "missing")
+
+		hits := &dto.Metric{}
+		promMetrics.GetsBackend.RequestStatus.WithLabelValues(prometheus.HitVal).Write(hits)
+		assert.Equal(t, float64(1), hits.GetCounter().GetValue(), tc.desc)
+
+		sizes := &dto.Metric{}
+		promMetrics.GetsValues.ValueSize.WithLabelValues(tc.expectedFormat).(promclient.Histogram).Write(sizes)
+		assert.Equal(t, uint64(1), sizes.GetHistogram().GetSampleCount(), tc.desc)
+		assert.Equal(t, tc.expectedSizeSum, sizes.GetHistogram().GetSampleSum(), tc.desc)
+	}
+}
@@ -0,0 +1,61 @@
+package decorators
+
+import (
+	"context"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/tracing"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+type backendWithTracing struct {
+	delegate backends.Backend
+	system   string
+}
+
+// TraceBackend records a client span for every call to the backend, as a child of the request
+// span found in the context. system names the storage service, such as "redis".
+func TraceBackend(backend backends.Backend, system string) backends.Backend {
+	return &backendWithTracing{
+		delegate: backend,
+		system:   system,
+	}
+}
+
+func (b *backendWithTracing) Get(ctx context.Context, key string) (string, error) {
+	ctx, span := tracing.StartSpan(ctx, "backend.get", tracing.SpanKindClient)
+	defer span.End()
+	span.SetAttribute("db.system", b.system)
+
+	value, err := b.delegate.Get(ctx, key)
+	if err != nil {
+		if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr && pbcErr.Type == utils.KEY_NOT_FOUND {
+			// A missing key is an expected outcome of a read, not a failure of the backend
+			span.SetAttribute("cache.hit", false)
+		} else {
+			span.SetError(err)
+		}
+		return value, err
+	}
+	span.SetAttribute("cache.hit", true)
+	span.SetAttribute("cache.value_bytes", len(value))
+	return value, nil
+}
+
+func (b *backendWithTracing) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	ctx, span := tracing.StartSpan(ctx, "backend.put", tracing.SpanKindClient)
+	defer span.End()
+	span.SetAttribute("db.system", b.system)
+	span.SetAttribute("cache.value_bytes", len(value))
+	span.SetAttribute("cache.ttl_seconds", ttlSeconds)
+
+	err := b.delegate.Put(ctx, key, value, ttlSeconds)
+	if err != nil {
+		if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr && pbcErr.Type == utils.RECORD_EXISTS {
+			span.SetAttribute("cache.record_exists", true)
+		} else {
+			span.SetError(err)
+		}
+	}
+	return err
+}
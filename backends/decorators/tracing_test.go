@@ -0,0 +1,108 @@
+package decorators
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/tracing"
+	"github.com/prebid/prebid-cache/tracing/tracingtest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceBackend(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		inBackend          backends.Backend
+		run                func(ctx context.Context, backend backends.Backend)
+		expectedName       string
+		expectedAttributes map[string]interface{}
+		expectedErr        string
+	}{
+		{
+			desc:      "Successful put",
+			inBackend: backends.NewMemoryBackend(),
+			run: func(ctx context.Context, backend backends.Backend) {
+				backend.Put(ctx, "key", "json{}", 60)
+			},
+			expectedName:       "backend.put",
+			expectedAttributes: map[string]interface{}{"db.system": "memory", "cache.value_bytes": 6, "cache.ttl_seconds": 60},
+		},
+		{
+			desc:      "Put of a key that already exists is not an error",
+			inBackend: &failedBackend{returnError: utils.NewPBCError(utils.RECORD_EXISTS)},
+			run: func(ctx context.Context, backend backends.Backend) {
+				backend.Put(ctx, "key", "json{}", 60)
+			},
+			expectedName:       "backend.put",
+			expectedAttributes: map[string]interface{}{"db.system": "memory", "cache.value_bytes": 6, "cache.ttl_seconds": 60, "cache.record_exists": true},
+		},
+		{
+			desc:      "Failed put",
+			inBackend: &failedBackend{returnError: errors.New("connection refused")},
+			run: func(ctx context.Context, backend backends.Backend) {
+				backend.Put(ctx, "key", "json{}", 60)
+			},
+			expectedName:       "backend.put",
+			expectedAttributes: map[string]interface{}{"db.system": "memory", "cache.value_bytes": 6, "cache.ttl_seconds": 60},
+			expectedErr:        "connection refused",
+		},
+		{
+			desc:      "Get of a missing key is a miss, not an error",
+			inBackend: backends.NewMemoryBackend(),
+			run: func(ctx context.Context, backend backends.Backend) {
+				backend.Get(ctx, "missing")
+			},
+			expectedName:       "backend.get",
+			expectedAttributes: map[string]interface{}{"db.system": "memory", "cache.hit": false},
+		},
+		{
+			desc:      "Failed get",
+			inBackend: &failedBackend{returnError: errors.New("connection refused")},
+			run: func(ctx context.Context, backend backends.Backend) {
+				backend.Get(ctx, "key")
+			},
+			expectedName:       "backend.get",
+			expectedAttributes: map[string]interface{}{"db.system": "memory"},
+			expectedErr:        "connection refused",
+		},
+	}
+
+	for _, tc := range testCases {
+		tracer, exporter := tracingtest.NewTracer()
+		ctx, requestSpan := tracer.StartRequestSpan(context.Background(), "POST /cache", tracing.SpanContext{})
+
+		tc.run(ctx, TraceBackend(tc.inBackend, "memory"))
+		tracer.Shutdown(context.Background())
+
+		spans := exporter.Spans()
+		if !assert.Len(t, spans, 1, tc.desc) {
+			continue
+		}
+		assert.Equal(t, tc.expectedName, spans[0].Name, tc.desc)
+		assert.Equal(t, tracing.SpanKindClient, spans[0].Kind, tc.desc)
+		assert.Equal(t, requestSpan.SpanContext.SpanID, spans[0].ParentSpanID, tc.desc)
+		assert.Equal(t, tc.expectedAttributes, spans[0].Attributes, tc.desc)
+		assert.Equal(t, tc.expectedErr, spans[0].Err, tc.desc)
+	}
+}
+
+func TestTraceBackendGetHit(t *testing.T) {
+	tracer, exporter := tracingtest.NewTracer()
+	backend := TraceBackend(backends.NewMemoryBackend(), "memory")
+	backend.Put(context.Background(), "key", "json{}", 60)
+
+	ctx, requestSpan := tracer.StartRequestSpan(context.Background(), "GET /cache", tracing.SpanContext{})
+	value, err := backend.Get(ctx, "key")
+	requestSpan.End()
+	tracer.Shutdown(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "json{}", value)
+	spans := exporter.Spans()
+	if assert.Len(t, spans, 2, "The put happened outside of a request, so it has no span") {
+		assert.Equal(t, map[string]interface{}{"db.system": "memory", "cache.hit": true, "cache.value_bytes": 6}, spans[0].Attributes)
+	}
+}
@@ -3,7 +3,9 @@ package backends
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -16,7 +18,7 @@ import (
 // Redis database. Its implementation is intended to use the "github.com/go-redis/redis"
 // client
 type RedisDB interface {
-	Get(ctx context.Context, key string) (string, error)
+	Get(ctx context.Context, key string) (string, time.Duration, error)
 	Put(ctx context.Context, key string, value string, ttlSeconds int) (bool, error)
 }
 
@@ -26,9 +28,25 @@ type RedisDBClient struct {
 	client *redis.Client
 }
 
-// Get returns the value associated with the provided `key` parameter
-func (db RedisDBClient) Get(ctx context.Context, key string) (string, error) {
-	return db.client.Get(ctx, key).Result()
+// Get returns the value associated with the provided `key` parameter and the time it has left to
+// live, which are read in a single round trip. A negative time to live means the value doesn't expire.
+func (db RedisDBClient) Get(ctx context.Context, key string) (string, time.Duration, error) {
+	var value *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := db.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		value = pipe.Get(ctx, key)
+		ttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return value.Val(), ttl.Val(), nil
+}
+
+// Close closes the connections of the Redis client
+func (db RedisDBClient) Close() error {
+	return db.client.Close()
 }
 
 // Put will set 'key' to hold string 'value' if 'key' does not exist in the redis storage.
@@ -43,26 +61,27 @@ func (db RedisDBClient) Put(ctx context.Context, key, value string, ttlSeconds i
 type RedisBackend struct {
 	cfg    config.Redis
 	client RedisDB
+	// password holds the password new connections authenticate with, so it can be rotated
+	password atomic.Value
 }
 
 // NewRedisBackend initializes the redis client and pings to make sure connection was successful
 func NewRedisBackend(cfg config.Redis, ctx context.Context) *RedisBackend {
 	constr := cfg.Host + ":" + strconv.Itoa(cfg.Port)
 
+	backend := &RedisBackend{cfg: cfg}
+	backend.password.Store(cfg.Password)
+
+	// The password and database are sent by initConnection instead of the client's options so
+	// connections opened after a reload use the latest password
 	options := &redis.Options{
-		Addr:     constr,
-		Password: cfg.Password,
-		DB:       cfg.Db,
+		Addr:      constr,
+		OnConnect: backend.initConnection,
 	}
 
 	if cfg.TLS.Enabled {
-		options = &redis.Options{
-			Addr:     constr,
-			Password: cfg.Password,
-			DB:       cfg.Db,
-			TLSConfig: &tls.Config{
-				InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
-			},
+		options.TLSConfig = &tls.Config{
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
 		}
 	}
 
@@ -77,20 +96,41 @@ func NewRedisBackend(cfg config.Redis, ctx context.Context) *RedisBackend {
 
 	log.Infof("Connected to Redis at %s:%d", cfg.Host, cfg.Port)
 
-	return &RedisBackend{
-		cfg:    cfg,
-		client: redisClient,
-	}
+	backend.client = redisClient
+	return backend
+}
+
+// UpdateCredentials makes the connections opened from now on authenticate with the Redis password
+// found in cfg. Connections that are already open remain authenticated.
+func (b *RedisBackend) UpdateCredentials(cfg config.Backend) {
+	b.password.Store(cfg.Redis.Password)
+}
+
+// initConnection authenticates a new connection and selects the configured database
+func (b *RedisBackend) initConnection(ctx context.Context, cn *redis.Conn) error {
+	password, _ := b.password.Load().(string)
+	_, err := cn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		if password != "" {
+			pipe.Auth(ctx, password)
+		}
+		if b.cfg.Db > 0 {
+			pipe.Select(ctx, b.cfg.Db)
+		}
+		return nil
+	})
+	return err
 }
 
 // Get calls the Redis client to return the value associated with the provided `key`
 // parameter and interprets its response. A `Nil` error reply of the Redis client means
-// the `key` does not exist.
+// the `key` does not exist. The time the value expires at is recorded in the context.
 func (b *RedisBackend) Get(ctx context.Context, key string) (string, error) {
-	res, err := b.client.Get(ctx, key)
+	res, ttl, err := b.client.Get(ctx, key)
 
 	if err == redis.Nil {
 		err = utils.NewPBCError(utils.KEY_NOT_FOUND)
+	} else if err == nil && ttl > 0 {
+		utils.RecordValueExpiration(ctx, time.Now().Add(ttl))
 	}
 
 	return res, err
@@ -107,3 +147,11 @@ func (b *RedisBackend) Put(ctx context.Context, key string, value string, ttlSec
 	}
 	return err
 }
+
+// Close releases the connections to the Redis server
+func (b *RedisBackend) Close() error {
+	if closer, ok := b.client.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
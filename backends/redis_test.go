@@ -1,11 +1,18 @@
 package backends
 
 import (
+	"bufio"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -75,6 +82,66 @@ func TestRedisClientGet(t *testing.T) {
 	}
 }
 
+func TestRedisClientGetExpiration(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		inTTL          time.Duration
+		expectedExpiry bool
+	}{
+		{
+			desc:           "Value expires",
+			inTTL:          90 * time.Second,
+			expectedExpiry: true,
+		},
+		{
+			desc:  "Value doesn't expire",
+			inTTL: -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		redisBackend := &RedisBackend{client: &goodRedisClient{key: "defaultKey", value: "aValue", ttl: tc.inTTL}}
+		expiration := &utils.ValueExpiration{}
+
+		before := time.Now()
+		_, err := redisBackend.Get(utils.WithValueExpiration(context.Background(), expiration), "defaultKey")
+		after := time.Now()
+
+		assert.NoError(t, err, tc.desc)
+		expiresAt, ok := expiration.ExpiresAt()
+		if assert.Equal(t, tc.expectedExpiry, ok, tc.desc) && ok {
+			assert.False(t, expiresAt.Before(before.Add(tc.inTTL)), tc.desc)
+			assert.False(t, expiresAt.After(after.Add(tc.inTTL)), tc.desc)
+		}
+	}
+}
+
+func TestRedisDBClientGet(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	commands := make(chan string, 20)
+	go serveFakeRedis(listener, commands)
+
+	client := RedisDBClient{client: redis.NewClient(&redis.Options{Addr: listener.Addr().String()})}
+	defer client.Close()
+
+	value, ttl, err := client.Get(context.Background(), "stored")
+	assert.NoError(t, err)
+	assert.Equal(t, "aValue", value)
+	assert.Equal(t, 1500*time.Millisecond, ttl)
+
+	_, _, err = client.Get(context.Background(), "missing")
+	assert.Equal(t, redis.Nil, err)
+
+	for _, expected := range []string{"get stored", "pttl stored", "get missing", "pttl missing"} {
+		assert.Equal(t, expected, <-commands)
+	}
+}
+
 func TestRedisClientPut(t *testing.T) {
 	redisBackend := &RedisBackend{}
 
@@ -175,8 +242,8 @@ type errorProneRedisClient struct {
 	errorToThrow error
 }
 
-func (ec *errorProneRedisClient) Get(ctx context.Context, key string) (string, error) {
-	return "", ec.errorToThrow
+func (ec *errorProneRedisClient) Get(ctx context.Context, key string) (string, time.Duration, error) {
+	return "", 0, ec.errorToThrow
 }
 
 func (ec *errorProneRedisClient) Put(ctx context.Context, key string, value string, ttlSeconds int) (bool, error) {
@@ -187,13 +254,14 @@ func (ec *errorProneRedisClient) Put(ctx context.Context, key string, value stri
 type goodRedisClient struct {
 	key   string
 	value string
+	ttl   time.Duration
 }
 
-func (gc *goodRedisClient) Get(ctx context.Context, key string) (string, error) {
+func (gc *goodRedisClient) Get(ctx context.Context, key string) (string, time.Duration, error) {
 	if key == gc.key {
-		return gc.value, nil
+		return gc.value, gc.ttl, nil
 	}
-	return "", utils.NewPBCError(utils.KEY_NOT_FOUND)
+	return "", 0, utils.NewPBCError(utils.KEY_NOT_FOUND)
 }
 
 func (gc *goodRedisClient) Put(ctx context.Context, key string, value string, ttlSeconds int) (bool, error) {
@@ -204,3 +272,86 @@ func (gc *goodRedisClient) Put(ctx context.Context, key string, value string, tt
 
 	return true, nil
 }
+
+func TestRedisUpdateCredentials(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	commands := make(chan string, 20)
+	go serveFakeRedis(listener, commands)
+
+	backend := &RedisBackend{cfg: config.Redis{Db: 2}}
+	backend.password.Store("first-password")
+
+	// Each client opens its own connection, authenticated with the password current at that time
+	ping := func() {
+		client := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), OnConnect: backend.initConnection})
+		defer client.Close()
+		assert.NoError(t, client.Ping(context.Background()).Err())
+	}
+
+	ping()
+	backend.UpdateCredentials(config.Backend{Redis: config.Redis{Password: "rotated-password"}})
+	ping()
+
+	expectedCommands := []string{
+		"auth first-password", "select 2", "ping",
+		"auth rotated-password", "select 2", "ping",
+	}
+	for _, expected := range expectedCommands {
+		assert.Equal(t, expected, <-commands)
+	}
+}
+
+// serveFakeRedis accepts connections, sends every command it reads to the commands channel and
+// replies with the simple strings Redis uses for AUTH, SELECT and PING. Only the "stored" key holds a
+// value, which expires in 1.5 seconds.
+func serveFakeRedis(listener net.Listener, commands chan<- string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for {
+				var numArgs int
+				if _, err := fmt.Fscanf(reader, "*%d\r\n", &numArgs); err != nil {
+					return
+				}
+				args := make([]string, numArgs)
+				for i := range args {
+					var length int
+					if _, err := fmt.Fscanf(reader, "$%d\r\n", &length); err != nil {
+						return
+					}
+					arg := make([]byte, length+2)
+					if _, err := io.ReadFull(reader, arg); err != nil {
+						return
+					}
+					args[i] = string(arg[:length])
+				}
+				commands <- strings.Join(args, " ")
+
+				switch {
+				case args[0] == "ping":
+					conn.Write([]byte("+PONG\r\n"))
+				case args[0] == "get" && args[1] == "stored":
+					conn.Write([]byte("$6\r\naValue\r\n"))
+				case args[0] == "get":
+					conn.Write([]byte("$-1\r\n"))
+				case args[0] == "pttl" && args[1] == "stored":
+					conn.Write([]byte(":1500\r\n"))
+				case args[0] == "pttl":
+					conn.Write([]byte(":-2\r\n"))
+				default:
+					conn.Write([]byte("+OK\r\n"))
+				}
+			}
+		}(conn)
+	}
+}
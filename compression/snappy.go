@@ -5,6 +5,7 @@ import (
 
 	"github.com/golang/snappy"
 	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/tracing"
 )
 
 // SnappyCompress runs snappy compression on data before saving it in the backend.
@@ -20,7 +21,13 @@ type snappyCompressor struct {
 }
 
 func (s *snappyCompressor) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
-	return s.delegate.Put(ctx, key, string(snappy.Encode(nil, []byte(value))), ttlSeconds)
+	_, span := tracing.StartSpan(ctx, "compression.snappy.encode", tracing.SpanKindInternal)
+	compressed := snappy.Encode(nil, []byte(value))
+	span.SetAttribute("compression.uncompressed_bytes", len(value))
+	span.SetAttribute("compression.compressed_bytes", len(compressed))
+	span.End()
+
+	return s.delegate.Put(ctx, key, string(compressed), ttlSeconds)
 }
 
 func (s *snappyCompressor) Get(ctx context.Context, key string) (string, error) {
@@ -29,10 +36,16 @@ func (s *snappyCompressor) Get(ctx context.Context, key string) (string, error)
 		return "", err
 	}
 
+	_, span := tracing.StartSpan(ctx, "compression.snappy.decode", tracing.SpanKindInternal)
+	defer span.End()
+	span.SetAttribute("compression.compressed_bytes", len(compressed))
+
 	decompressed, err := snappy.Decode(nil, []byte(compressed))
 	if err != nil {
+		span.SetError(err)
 		return "", err
 	}
+	span.SetAttribute("compression.uncompressed_bytes", len(decompressed))
 
 	return string(decompressed), nil
 }
@@ -0,0 +1,38 @@
+package compression
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/tracing"
+	"github.com/prebid/prebid-cache/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnappyCompressSpans(t *testing.T) {
+	tracer, exporter := tracingtest.NewTracer()
+	backend := SnappyCompress(backends.NewMemoryBackend())
+	ctx, requestSpan := tracer.StartRequestSpan(context.Background(), "POST /cache", tracing.SpanContext{})
+
+	value := "json{\"field\":\"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\"}"
+	assert.NoError(t, backend.Put(ctx, "key", value, 60))
+	stored, err := backend.Get(ctx, "key")
+	requestSpan.End()
+	tracer.Shutdown(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, value, stored)
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	assert.Equal(t, "compression.snappy.encode", spans[0].Name)
+	assert.Equal(t, "compression.snappy.decode", spans[1].Name)
+	for _, span := range spans[:2] {
+		assert.Equal(t, tracing.SpanKindInternal, span.Kind)
+		assert.Equal(t, requestSpan.SpanContext.SpanID, span.ParentSpanID)
+		assert.Equal(t, len(value), span.Attributes["compression.uncompressed_bytes"])
+		assert.Less(t, span.Attributes["compression.compressed_bytes"], len(value))
+	}
+}
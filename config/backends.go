@@ -12,11 +12,16 @@ type Backend struct {
 	Cassandra Cassandra   `mapstructure:"cassandra"`
 	Memcache  Memcache    `mapstructure:"memcache"`
 	Redis     Redis       `mapstructure:"redis"`
+	// StoreMetadata stores the time a value was put along with it, so the age of the values can be
+	// measured when they're read. Values stored this way can't be read by versions of Prebid Cache
+	// that predate this setting.
+	StoreMetadata bool `mapstructure:"store_metadata"`
 }
 
-func (cfg *Backend) validateAndLog() error {
+func (cfg *Backend) validateAndLog() []error {
 
 	log.Infof("config.backend.type: %s", cfg.Type)
+	log.Infof("config.backend.store_metadata: %t", cfg.StoreMetadata)
 	switch cfg.Type {
 	case BackendAerospike:
 		return cfg.Aerospike.validateAndLog()
@@ -29,9 +34,8 @@ func (cfg *Backend) validateAndLog() error {
 	case BackendMemory:
 		return nil
 	default:
-		return fmt.Errorf(`invalid config.backend.type: %s. It must be "aerospike", "cassandra", "memcache", "redis", or "memory".`, cfg.Type)
+		return []error{fmt.Errorf(`invalid config.backend.type: %s. It must be "aerospike", "cassandra", "memcache", "redis", or "memory".`, cfg.Type)}
 	}
-	return nil
 }
 
 type BackendType string
@@ -51,7 +55,8 @@ type Aerospike struct {
 	Port            int      `mapstructure:"port"`
 	Namespace       string   `mapstructure:"namespace"`
 	User            string   `mapstructure:"user"`
-	Password        string   `mapstructure:"password"`
+	Password        string   `mapstructure:"password" secret:"true"`
+	PasswordFile    string   `mapstructure:"password_file"`
 	MaxReadRetries  int      `mapstructure:"max_read_retries"`
 	MaxWriteRetries int      `mapstructure:"max_write_retries"`
 	// Please set this to a value lower than the `proto-fd-idle-ms` (converted
@@ -62,13 +67,17 @@ type Aerospike struct {
 	ConnIdleTimeoutSecs int `mapstructure:"connection_idle_timeout_seconds"`
 }
 
-func (cfg *Aerospike) validateAndLog() error {
+func (cfg *Aerospike) validateAndLog() []error {
+	var errs []error
 	if len(cfg.Host) < 1 && len(cfg.Hosts) < 1 {
-		return fmt.Errorf("Cannot connect to empty Aerospike host(s)")
+		errs = append(errs, fmt.Errorf("Cannot connect to empty Aerospike host(s)"))
 	}
 
 	if cfg.Port <= 0 {
-		return fmt.Errorf("Cannot connect to Aerospike host at port %d", cfg.Port)
+		errs = append(errs, fmt.Errorf("Cannot connect to Aerospike host at port %d", cfg.Port))
+	}
+	if len(errs) > 0 {
+		return errs
 	}
 
 	log.Infof("config.backend.aerospike.host: %s", cfg.Host)
@@ -76,6 +85,7 @@ func (cfg *Aerospike) validateAndLog() error {
 	log.Infof("config.backend.aerospike.port: %d", cfg.Port)
 	log.Infof("config.backend.aerospike.namespace: %s", cfg.Namespace)
 	log.Infof("config.backend.aerospike.user: %s", cfg.User)
+	logSecret("config.backend.aerospike.password", cfg.Password, cfg.PasswordFile)
 
 	if cfg.DefaultTTLSecs > 0 {
 		log.Infof("config.backend.aerospike.default_ttl_seconds: %d. Note that this configuration option is being deprecated in favor of config.request_limits.max_ttl_seconds", cfg.DefaultTTLSecs)
@@ -108,7 +118,7 @@ type Cassandra struct {
 	DefaultTTL int    `mapstructure:"default_ttl_seconds"`
 }
 
-func (cfg *Cassandra) validateAndLog() error {
+func (cfg *Cassandra) validateAndLog() []error {
 	log.Infof("config.backend.cassandra.hosts: %s", cfg.Hosts)
 	log.Infof("config.backend.cassandra.keyspace: %s", cfg.Keyspace)
 	if cfg.DefaultTTL < 0 {
@@ -126,7 +136,7 @@ type Memcache struct {
 	Hosts               []string `mapstructure:"hosts"`
 }
 
-func (cfg *Memcache) validateAndLog() error {
+func (cfg *Memcache) validateAndLog() []error {
 	if cfg.ConfigHost != "" {
 		log.Infof("Memcache client will run in auto discovery mode")
 		log.Infof("config.backend.memcache.config_host: %s", cfg.ConfigHost)
@@ -140,7 +150,8 @@ func (cfg *Memcache) validateAndLog() error {
 type Redis struct {
 	Host              string   `mapstructure:"host"`
 	Port              int      `mapstructure:"port"`
-	Password          string   `mapstructure:"password"`
+	Password          string   `mapstructure:"password" secret:"true"`
+	PasswordFile      string   `mapstructure:"password_file"`
 	Db                int      `mapstructure:"db"`
 	ExpirationMinutes int      `mapstructure:"expiration"`
 	TLS               RedisTLS `mapstructure:"tls"`
@@ -151,9 +162,10 @@ type RedisTLS struct {
 	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
 }
 
-func (cfg *Redis) validateAndLog() error {
+func (cfg *Redis) validateAndLog() []error {
 	log.Infof("config.backend.redis.host: %s", cfg.Host)
 	log.Infof("config.backend.redis.port: %d", cfg.Port)
+	logSecret("config.backend.redis.password", cfg.Password, cfg.PasswordFile)
 	log.Infof("config.backend.redis.db: %d", cfg.Db)
 	if cfg.ExpirationMinutes > 0 {
 		log.Infof("config.backend.redis.expiration: %d. Note that this configuration option is being deprecated in favor of config.request_limits.max_ttl_seconds", cfg.ExpirationMinutes)
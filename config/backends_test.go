@@ -17,11 +17,11 @@ func TestAerospikeValidateAndLog(t *testing.T) {
 	}
 
 	type testCase struct {
-		desc          string
-		inCfg         Aerospike
-		hasError      bool
-		expectedError error
-		logEntries    []logComponents
+		desc           string
+		inCfg          Aerospike
+		hasError       bool
+		expectedErrors []error
+		logEntries     []logComponents
 	}
 	testGroups := []struct {
 		desc      string
@@ -214,16 +214,16 @@ func TestAerospikeValidateAndLog(t *testing.T) {
 					inCfg: Aerospike{
 						Port: 8888,
 					},
-					hasError:      true,
-					expectedError: fmt.Errorf("Cannot connect to empty Aerospike host(s)"),
+					hasError:       true,
+					expectedErrors: []error{fmt.Errorf("Cannot connect to empty Aerospike host(s)")},
 				},
 				{
 					desc: "aerospike.port config missing",
 					inCfg: Aerospike{
 						Host: "foo.com",
 					},
-					hasError:      true,
-					expectedError: fmt.Errorf("Cannot connect to Aerospike host at port 0"),
+					hasError:       true,
+					expectedErrors: []error{fmt.Errorf("Cannot connect to Aerospike host at port 0")},
 				},
 				{
 					desc: "aerospike.port config missing",
@@ -231,8 +231,17 @@ func TestAerospikeValidateAndLog(t *testing.T) {
 						Host:  "foo.com",
 						Hosts: []string{"bar.com"},
 					},
-					hasError:      true,
-					expectedError: fmt.Errorf("Cannot connect to Aerospike host at port 0"),
+					hasError:       true,
+					expectedErrors: []error{fmt.Errorf("Cannot connect to Aerospike host at port 0")},
+				},
+				{
+					desc:     "aerospike.host, aerospike.hosts and aerospike.port missing",
+					inCfg:    Aerospike{},
+					hasError: true,
+					expectedErrors: []error{
+						fmt.Errorf("Cannot connect to empty Aerospike host(s)"),
+						fmt.Errorf("Cannot connect to Aerospike host at port 0"),
+					},
 				},
 			},
 		},
@@ -252,7 +261,7 @@ func TestAerospikeValidateAndLog(t *testing.T) {
 
 			//run test
 			if test.hasError {
-				assert.Equal(t, test.inCfg.validateAndLog(), test.expectedError, group.desc+" : "+test.desc)
+				assert.Equal(t, test.expectedErrors, test.inCfg.validateAndLog(), group.desc+" : "+test.desc)
 			} else {
 				assert.Nil(t, test.inCfg.validateAndLog(), group.desc+" : "+test.desc)
 			}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -10,15 +13,37 @@ import (
 	"github.com/prebid/prebid-cache/utils"
 )
 
+// NewConfig loads the configuration, terminating the program if the file can't be read
 func NewConfig(filename string) Configuration {
+	cfg, err := LoadConfig(filename)
+	if err != nil {
+		log.Fatalf("%s", err.Error())
+	}
+	return cfg
+}
+
+// LoadConfig reads the configuration file, if any, and merges it with the defaults and the
+// PBC_ environment variables. Unlike NewConfig, errors are returned to the caller so a
+// running server can try to reload its configuration without exiting.
+func LoadConfig(filename string) (Configuration, error) {
 	v := viper.New()
+	setConfigFilePath(v, filename)
+	return readConfig(v)
+}
+
+// LoadConfigFile works like LoadConfig, but reads the configuration from the file at path
+// instead of looking for it in the default locations. The file must exist.
+func LoadConfigFile(path string) (Configuration, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	return readConfig(v)
+}
 
+func readConfig(v *viper.Viper) (Configuration, error) {
 	setConfigDefaults(v)
 
 	setEnvVarsLookup(v)
 
-	setConfigFilePath(v, filename)
-
 	// Read configuration file
 	err := v.ReadInConfig()
 	if err != nil {
@@ -28,16 +53,20 @@ func NewConfig(filename string) Configuration {
 			log.Info("Configuration file not detected. Initializing with default values and environment variable overrides.")
 		} else {
 			// Config file was found but was defective, Either `UnsupportedConfigError` or `ConfigParseError` was thrown
-			log.Fatalf("Configuration file could not be read: %v", err)
+			return Configuration{}, fmt.Errorf("Configuration file could not be read: %v", err)
 		}
 	}
 
 	cfg := Configuration{}
 	if err := v.Unmarshal(&cfg); err != nil {
-		log.Fatalf("Failed to unmarshal config: %v", err)
+		return Configuration{}, fmt.Errorf("Failed to unmarshal config: %v", err)
 	}
 
-	return cfg
+	if err := resolveSecrets(&cfg); err != nil {
+		return Configuration{}, fmt.Errorf("Failed to resolve secrets: %v", err)
+	}
+
+	return cfg, nil
 }
 
 func setConfigDefaults(v *viper.Viper) {
@@ -45,13 +74,16 @@ func setConfigDefaults(v *viper.Viper) {
 	v.SetDefault("admin_port", 2525)
 	v.SetDefault("index_response", "This application stores short-term data for use in Prebid.")
 	v.SetDefault("log.level", "info")
+	v.SetDefault("log.access_log.enabled", false)
 	v.SetDefault("backend.type", "memory")
+	v.SetDefault("backend.store_metadata", false)
 	v.SetDefault("backend.aerospike.host", "")
 	v.SetDefault("backend.aerospike.hosts", []string{})
 	v.SetDefault("backend.aerospike.port", 0)
 	v.SetDefault("backend.aerospike.namespace", "")
 	v.SetDefault("backend.aerospike.user", "")
 	v.SetDefault("backend.aerospike.password", "")
+	v.SetDefault("backend.aerospike.password_file", "")
 	v.SetDefault("backend.aerospike.default_ttl_seconds", 0)
 	v.SetDefault("backend.aerospike.max_read_retries", 2)
 	v.SetDefault("backend.aerospike.max_write_retries", 0)
@@ -63,6 +95,7 @@ func setConfigDefaults(v *viper.Viper) {
 	v.SetDefault("backend.redis.host", "")
 	v.SetDefault("backend.redis.port", 0)
 	v.SetDefault("backend.redis.password", "")
+	v.SetDefault("backend.redis.password_file", "")
 	v.SetDefault("backend.redis.db", 0)
 	v.SetDefault("backend.redis.expiration", utils.REDIS_DEFAULT_EXPIRATION_MINUTES)
 	v.SetDefault("backend.redis.tls.enabled", false)
@@ -74,19 +107,117 @@ func setConfigDefaults(v *viper.Viper) {
 	v.SetDefault("metrics.influx.measurement", "")
 	v.SetDefault("metrics.influx.username", "")
 	v.SetDefault("metrics.influx.password", "")
+	v.SetDefault("metrics.influx.password_file", "")
 	v.SetDefault("metrics.influx.align_timestamps", false)
+	v.SetDefault("metrics.influx.tags", map[string]string{})
+	v.SetDefault("metrics.influx.flush_interval_ms", utils.INFLUX_FLUSH_INTERVAL_MS)
+	v.SetDefault("metrics.influx.buffer_size", utils.INFLUX_BUFFER_SIZE)
+	v.SetDefault("metrics.influx.max_retries", utils.INFLUX_MAX_RETRIES)
+	v.SetDefault("metrics.influx.retry_backoff_ms", utils.INFLUX_RETRY_BACKOFF_MS)
 	v.SetDefault("metrics.prometheus.port", 0)
 	v.SetDefault("metrics.prometheus.namespace", "")
 	v.SetDefault("metrics.prometheus.subsystem", "")
 	v.SetDefault("metrics.prometheus.timeout_ms", 0)
 	v.SetDefault("metrics.prometheus.enabled", false)
+	v.SetDefault("metrics.prometheus.admin_endpoint", false)
+	v.SetDefault("metrics.prometheus.process_collector", true)
+	v.SetDefault("metrics.prometheus.go_collector", false)
+	v.SetDefault("metrics.prometheus.buckets.duration", utils.PROMETHEUS_DURATION_BUCKETS)
+	v.SetDefault("metrics.prometheus.buckets.size", utils.PROMETHEUS_SIZE_BUCKETS)
+	v.SetDefault("metrics.prometheus.buckets.ttl", utils.PROMETHEUS_TTL_BUCKETS)
+	v.SetDefault("metrics.prometheus.buckets.age", utils.PROMETHEUS_AGE_BUCKETS)
+	v.SetDefault("metrics.statsd.enabled", false)
+	v.SetDefault("metrics.statsd.host", "127.0.0.1")
+	v.SetDefault("metrics.statsd.port", utils.STATSD_PORT)
+	v.SetDefault("metrics.statsd.prefix", "prebid_cache")
+	v.SetDefault("metrics.statsd.sample_rate", 1.0)
+	v.SetDefault("metrics.statsd.dogstatsd", false)
+	v.SetDefault("metrics.statsd.tags", []string{})
+	v.SetDefault("metrics.statsd.flush_interval_ms", utils.STATSD_FLUSH_INTERVAL_MS)
+	v.SetDefault("metrics.statsd.max_packet_bytes", utils.STATSD_MAX_PACKET_BYTES)
 	v.SetDefault("rate_limiter.enabled", true)
 	v.SetDefault("rate_limiter.num_requests", utils.RATE_LIMITER_NUM_REQUESTS)
 	v.SetDefault("request_limits.allow_setting_keys", false)
 	v.SetDefault("request_limits.max_size_bytes", utils.REQUEST_MAX_SIZE_BYTES)
 	v.SetDefault("request_limits.max_num_values", utils.REQUEST_MAX_NUM_VALUES)
+	v.SetDefault("request_limits.max_body_size_bytes", utils.REQUEST_MAX_BODY_SIZE_BYTES)
+	v.SetDefault("request_limits.max_decompressed_body_size_bytes", utils.REQUEST_MAX_DECOMPRESSED_BYTES)
 	v.SetDefault("request_limits.max_ttl_seconds", utils.REQUEST_MAX_TTL_SECONDS)
+	v.SetDefault("keys.dedup", false)
+	v.SetDefault("keys.format", "uuidv4")
+	v.SetDefault("keys.prefix", "")
+	v.SetDefault("keys.collision_retries", 3)
 	v.SetDefault("routes.allow_public_write", true)
+	v.SetDefault("routes.error_format", "text")
+	v.SetDefault("routes.response_encodings", []string{})
+	v.SetDefault("routes.cors.get.allowed_origins", []string{"*"})
+	v.SetDefault("routes.cors.get.allowed_methods", []string{"GET", "HEAD"})
+	v.SetDefault("routes.cors.get.allowed_headers", []string{})
+	v.SetDefault("routes.cors.get.max_age_seconds", 0)
+	v.SetDefault("routes.cors.get.allow_credentials", false)
+	v.SetDefault("routes.cors.post.allowed_origins", []string{"*"})
+	v.SetDefault("routes.cors.post.allowed_methods", []string{"POST"})
+	v.SetDefault("routes.cors.post.allowed_headers", []string{})
+	v.SetDefault("routes.cors.post.max_age_seconds", 0)
+	v.SetDefault("routes.cors.post.allow_credentials", false)
+	v.SetDefault("peers.allowed_hosts", []string{})
+	v.SetDefault("peers.scheme", "https")
+	v.SetDefault("peers.timeout_ms", utils.PEER_TIMEOUT_MS)
+	v.SetDefault("peers.max_response_size_bytes", utils.PEER_MAX_RESPONSE_SIZE_BYTES)
+	v.SetDefault("client_ip.trusted_proxies", []string{})
+	v.SetDefault("client_ip.proxy_protocol.enabled", false)
+	v.SetDefault("client_ip.proxy_protocol.header_timeout_ms", utils.PROXY_PROTOCOL_HEADER_TIMEOUT_MS)
+	v.SetDefault("shutdown.pre_stop_delay_ms", 0)
+	v.SetDefault("shutdown.drain_timeout_ms", utils.SHUTDOWN_DRAIN_TIMEOUT_MS)
+	v.SetDefault("shutdown.close_timeout_ms", utils.SHUTDOWN_CLOSE_TIMEOUT_MS)
+	v.SetDefault("server.main.read_header_timeout_ms", 0)
+	v.SetDefault("server.main.read_timeout_ms", utils.MAIN_SERVER_TIMEOUT_MS)
+	v.SetDefault("server.main.write_timeout_ms", utils.MAIN_SERVER_TIMEOUT_MS)
+	v.SetDefault("server.main.idle_timeout_ms", 0)
+	v.SetDefault("server.main.max_header_bytes", 0)
+	v.SetDefault("server.main.keep_alive_period_seconds", utils.TCP_KEEP_ALIVE_PERIOD_SECONDS)
+	v.SetDefault("server.main.max_connections", 0)
+	v.SetDefault("server.main.h2c", false)
+	v.SetDefault("server.main.unix_socket.path", "")
+	v.SetDefault("server.main.unix_socket.mode", "0660")
+	v.SetDefault("server.main.unix_socket.disable_tcp", false)
+	v.SetDefault("server.main.tls.enabled", false)
+	v.SetDefault("server.main.tls.cert_file", "")
+	v.SetDefault("server.main.tls.key_file", "")
+	v.SetDefault("server.main.tls.min_version", "1.2")
+	v.SetDefault("server.main.tls.cipher_suites", []string{})
+	v.SetDefault("server.main.tls.client_ca_file", "")
+	v.SetDefault("server.main.tls.reload_interval_seconds", utils.TLS_RELOAD_INTERVAL_SECONDS)
+	v.SetDefault("server.admin.read_header_timeout_ms", 0)
+	v.SetDefault("server.admin.read_timeout_ms", 0)
+	v.SetDefault("server.admin.write_timeout_ms", 0)
+	v.SetDefault("server.admin.idle_timeout_ms", 0)
+	v.SetDefault("server.admin.max_header_bytes", 0)
+	v.SetDefault("server.admin.keep_alive_period_seconds", utils.TCP_KEEP_ALIVE_PERIOD_SECONDS)
+	v.SetDefault("server.admin.max_connections", 0)
+	v.SetDefault("server.admin.h2c", false)
+	v.SetDefault("server.admin.unix_socket.path", "")
+	v.SetDefault("server.admin.unix_socket.mode", "0660")
+	v.SetDefault("server.admin.unix_socket.disable_tcp", false)
+	v.SetDefault("server.admin.tls.enabled", false)
+	v.SetDefault("server.admin.tls.cert_file", "")
+	v.SetDefault("server.admin.tls.key_file", "")
+	v.SetDefault("server.admin.tls.min_version", "1.2")
+	v.SetDefault("server.admin.tls.cipher_suites", []string{})
+	v.SetDefault("server.admin.tls.client_ca_file", "")
+	v.SetDefault("server.admin.tls.reload_interval_seconds", utils.TLS_RELOAD_INTERVAL_SECONDS)
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.service_name", "prebid-cache")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("tracing.queue_size", utils.TRACING_QUEUE_SIZE)
+	v.SetDefault("tracing.batch_size", utils.TRACING_BATCH_SIZE)
+	v.SetDefault("tracing.flush_interval_ms", utils.TRACING_FLUSH_INTERVAL_MS)
+	v.SetDefault("tracing.exporter.type", "stdout")
+	v.SetDefault("tracing.exporter.file_path", "")
+	v.SetDefault("tracing.exporter.otlp.endpoint", "http://localhost:4318/v1/traces")
+	v.SetDefault("tracing.exporter.otlp.authorization", "")
+	v.SetDefault("tracing.exporter.otlp.authorization_file", "")
+	v.SetDefault("tracing.exporter.otlp.timeout_ms", utils.TRACING_OTLP_TIMEOUT_MS)
 }
 
 func setConfigFilePath(v *viper.Viper, filename string) {
@@ -109,37 +240,92 @@ type Configuration struct {
 	Log           Log           `mapstructure:"log"`
 	RateLimiting  RateLimiting  `mapstructure:"rate_limiter"`
 	RequestLimits RequestLimits `mapstructure:"request_limits"`
+	Keys          Keys          `mapstructure:"keys"`
 	Backend       Backend       `mapstructure:"backend"`
 	Compression   Compression   `mapstructure:"compression"`
 	Metrics       Metrics       `mapstructure:"metrics"`
 	Routes        Routes        `mapstructure:"routes"`
+	Peers         Peers         `mapstructure:"peers"`
+	ClientIP      ClientIP      `mapstructure:"client_ip"`
+	Shutdown      Shutdown      `mapstructure:"shutdown"`
+	Server        Servers       `mapstructure:"server"`
+	Tracing       Tracing       `mapstructure:"tracing"`
 }
 
-// ValidateAndLog validates the config, terminating the program on any errors.
-// It also logs the config values that it used.
-func (cfg *Configuration) ValidateAndLog() {
+// ValidateAndLog validates the config and logs the config values that it used. Every problem
+// found is returned in a ValidationErrors so callers can decide whether to exit or keep running.
+func (cfg *Configuration) ValidateAndLog() error {
 
 	log.Infof("config.port: %d", cfg.Port)
 	log.Infof("config.admin_port: %d", cfg.AdminPort)
-	cfg.Log.validateAndLog()
-	cfg.RateLimiting.validateAndLog()
-	cfg.RequestLimits.validateAndLog()
 
-	if err := cfg.Backend.validateAndLog(); err != nil {
-		log.Fatalf("%s", err.Error())
+	validators := []func() []error{
+		cfg.Log.validateAndLog,
+		cfg.RateLimiting.validateAndLog,
+		cfg.RequestLimits.validateAndLog,
+		cfg.Keys.validateAndLog,
+		cfg.Backend.validateAndLog,
+		cfg.validateDedup,
+		cfg.Compression.validateAndLog,
+		cfg.Metrics.validateAndLog,
+		cfg.Routes.validateAndLog,
+		cfg.Peers.validateAndLog,
+		cfg.ClientIP.validateAndLog,
+		cfg.Shutdown.validateAndLog,
+		cfg.Server.validateAndLog,
+		cfg.Tracing.validateAndLog,
+	}
+	var errs ValidationErrors
+	for _, validate := range validators {
+		errs = append(errs, validate()...)
+	}
+	if len(errs) > 0 {
+		return errs
 	}
+	return nil
+}
+
+// validateDedup makes sure the backend tells when values expire when keys.dedup is enabled, so
+// values found under their content key can be checked to live as long as a put requested
+func (cfg *Configuration) validateDedup() []error {
+	if !cfg.Keys.Dedup || cfg.Backend.StoreMetadata {
+		return nil
+	}
+	switch cfg.Backend.Type {
+	case BackendCassandra, BackendMemcache:
+		return []error{fmt.Errorf("invalid config.keys.dedup: true. The %s backend doesn't report when values expire, it requires config.backend.store_metadata.", cfg.Backend.Type)}
+	}
+	return nil
+}
+
+// ValidationErrors lists every problem found in a configuration
+type ValidationErrors []error
 
-	cfg.Compression.validateAndLog()
-	cfg.Metrics.validateAndLog()
-	cfg.Routes.validateAndLog()
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
 }
 
 type Log struct {
-	Level LogLevel `mapstructure:"level"`
+	Level     LogLevel  `mapstructure:"level"`
+	AccessLog AccessLog `mapstructure:"access_log"`
 }
 
-func (cfg *Log) validateAndLog() {
+func (cfg *Log) validateAndLog() []error {
+	if _, err := log.ParseLevel(string(cfg.Level)); err != nil {
+		return []error{fmt.Errorf("invalid config.log.level: %s", cfg.Level)}
+	}
 	log.Infof("config.log.level: %s", cfg.Level)
+	log.Infof("config.log.access_log.enabled: %t", cfg.AccessLog.Enabled)
+	return nil
+}
+
+// AccessLog writes a JSON line to the standard output for every request the servers handle
+type AccessLog struct {
+	Enabled bool `mapstructure:"enabled"`
 }
 
 type LogLevel string
@@ -159,53 +345,122 @@ type RateLimiting struct {
 	MaxRequestsPerSecond int64 `mapstructure:"num_requests"`
 }
 
-func (cfg *RateLimiting) validateAndLog() {
+func (cfg *RateLimiting) validateAndLog() []error {
 	log.Infof("config.rate_limiter.enabled: %t", cfg.Enabled)
 	log.Infof("config.rate_limiter.num_requests: %d", cfg.MaxRequestsPerSecond)
+	return nil
 }
 
 type RequestLimits struct {
-	MaxSize          int  `mapstructure:"max_size_bytes"`
-	MaxNumValues     int  `mapstructure:"max_num_values"`
-	MaxTTLSeconds    int  `mapstructure:"max_ttl_seconds"`
-	AllowSettingKeys bool `mapstructure:"allow_setting_keys"`
+	MaxSize                 int  `mapstructure:"max_size_bytes"`
+	MaxNumValues            int  `mapstructure:"max_num_values"`
+	MaxBodySize             int  `mapstructure:"max_body_size_bytes"`
+	MaxDecompressedBodySize int  `mapstructure:"max_decompressed_body_size_bytes"`
+	MaxTTLSeconds           int  `mapstructure:"max_ttl_seconds"`
+	AllowSettingKeys        bool `mapstructure:"allow_setting_keys"`
 }
 
-func (cfg *RequestLimits) validateAndLog() {
+func (cfg *RequestLimits) validateAndLog() []error {
+	var errs []error
 	log.Infof("config.request_limits.allow_setting_keys: %v", cfg.AllowSettingKeys)
 
 	if cfg.MaxTTLSeconds >= 0 {
 		log.Infof("config.request_limits.max_ttl_seconds: %d", cfg.MaxTTLSeconds)
 	} else {
-		log.Fatalf("invalid config.request_limits.max_ttl_seconds: %d. Value cannot be negative.", cfg.MaxTTLSeconds)
+		errs = append(errs, fmt.Errorf("invalid config.request_limits.max_ttl_seconds: %d. Value cannot be negative.", cfg.MaxTTLSeconds))
 	}
 
 	if cfg.MaxSize >= 0 {
 		log.Infof("config.request_limits.max_size_bytes: %d", cfg.MaxSize)
 	} else {
-		log.Fatalf("invalid config.request_limits.max_size_bytes: %d. Value cannot be negative.", cfg.MaxSize)
+		errs = append(errs, fmt.Errorf("invalid config.request_limits.max_size_bytes: %d. Value cannot be negative.", cfg.MaxSize))
 	}
 
 	if cfg.MaxNumValues >= 0 {
 		log.Infof("config.request_limits.max_num_values: %d", cfg.MaxNumValues)
 	} else {
-		log.Fatalf("invalid config.request_limits.max_num_values: %d. Value cannot be negative.", cfg.MaxNumValues)
+		errs = append(errs, fmt.Errorf("invalid config.request_limits.max_num_values: %d. Value cannot be negative.", cfg.MaxNumValues))
+	}
+
+	if cfg.MaxBodySize > 0 {
+		log.Infof("config.request_limits.max_body_size_bytes: %d", cfg.MaxBodySize)
+	} else {
+		errs = append(errs, fmt.Errorf("invalid config.request_limits.max_body_size_bytes: %d. Value must be positive.", cfg.MaxBodySize))
+	}
+
+	if cfg.MaxDecompressedBodySize > 0 {
+		log.Infof("config.request_limits.max_decompressed_body_size_bytes: %d", cfg.MaxDecompressedBodySize)
+	} else {
+		errs = append(errs, fmt.Errorf("invalid config.request_limits.max_decompressed_body_size_bytes: %d. Value must be positive.", cfg.MaxDecompressedBodySize))
+	}
+	return errs
+}
+
+// Keys controls the keys Prebid Cache stores values under when the put requests don't set their own
+type Keys struct {
+	// Dedup stores identical values only once, under a key derived from their content
+	Dedup bool `mapstructure:"dedup"`
+	// Format is the format of the generated keys
+	Format IDFormat `mapstructure:"format"`
+	// Prefix is prepended to the generated keys, to tell which datacenter or shard stored a value.
+	// Get requests for keys without it are rejected, unless clients are allowed to set keys.
+	Prefix string `mapstructure:"prefix"`
+	// CollisionRetries is how many times a value is put again under a new key when the key
+	// generated for it is already taken
+	CollisionRetries int `mapstructure:"collision_retries"`
+}
+
+type IDFormat string
+
+const (
+	IDFormatUUIDv4 IDFormat = "uuidv4"
+	IDFormatUUIDv7 IDFormat = "uuidv7"
+	IDFormatULID   IDFormat = "ulid"
+)
+
+func (cfg *Keys) validateAndLog() []error {
+	log.Infof("config.keys.dedup: %t", cfg.Dedup)
+
+	var errs []error
+	switch cfg.Format {
+	case IDFormatUUIDv4, IDFormatUUIDv7, IDFormatULID:
+		log.Infof("config.keys.format: %s", cfg.Format)
+	default:
+		errs = append(errs, fmt.Errorf("invalid config.keys.format: %s. Value must be \"%s\", \"%s\" or \"%s\".", cfg.Format, IDFormatUUIDv4, IDFormatUUIDv7, IDFormatULID))
+	}
+
+	// Keys travel in query strings, so the prefix is limited to characters that never get escaped
+	validPrefix := strings.IndexFunc(cfg.Prefix, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-._~", r))
+	}) == -1
+	if validPrefix {
+		log.Infof("config.keys.prefix: %s", cfg.Prefix)
+	} else {
+		errs = append(errs, fmt.Errorf("invalid config.keys.prefix: %s. Value must only contain letters, digits, \"-\", \".\", \"_\" and \"~\".", cfg.Prefix))
+	}
+
+	if cfg.CollisionRetries < 0 {
+		errs = append(errs, fmt.Errorf("invalid config.keys.collision_retries: %d. Value cannot be negative.", cfg.CollisionRetries))
+	} else {
+		log.Infof("config.keys.collision_retries: %d", cfg.CollisionRetries)
 	}
+	return errs
 }
 
 type Compression struct {
 	Type CompressionType `mapstructure:"type"`
 }
 
-func (cfg *Compression) validateAndLog() {
+func (cfg *Compression) validateAndLog() []error {
 	switch cfg.Type {
 	case CompressionNone:
 		fallthrough
 	case CompressionSnappy:
 		log.Infof("config.compression.type: %s", cfg.Type)
 	default:
-		log.Fatalf(`invalid config.compression.type: %s. It must be "none" or "snappy"`, cfg.Type)
+		return []error{fmt.Errorf(`invalid config.compression.type: %s. It must be "none" or "snappy"`, cfg.Type)}
 	}
+	return nil
 }
 
 type CompressionType string
@@ -219,37 +474,50 @@ type Metrics struct {
 	Type       MetricsType       `mapstructure:"type"`
 	Influx     InfluxMetrics     `mapstructure:"influx"`
 	Prometheus PrometheusMetrics `mapstructure:"prometheus"`
+	StatsD     StatsDMetrics     `mapstructure:"statsd"`
 }
 
-func (cfg *Metrics) validateAndLog() {
+func (cfg *Metrics) validateAndLog() []error {
+	var errs []error
 
 	if cfg.Type == MetricsInflux || cfg.Influx.Enabled {
-		cfg.Influx.validateAndLog()
-		cfg.Influx.Enabled = true
+		if influxErrs := cfg.Influx.validateAndLog(); len(influxErrs) > 0 {
+			errs = append(errs, influxErrs...)
+		} else {
+			cfg.Influx.Enabled = true
+		}
 	}
 
 	if cfg.Prometheus.Enabled {
-		cfg.Prometheus.validateAndLog()
-		cfg.Prometheus.Enabled = true
+		if prometheusErrs := cfg.Prometheus.validateAndLog(); len(prometheusErrs) > 0 {
+			errs = append(errs, prometheusErrs...)
+		} else {
+			cfg.Prometheus.Enabled = true
+		}
 	}
 
-	metricsEnabled := cfg.Influx.Enabled || cfg.Prometheus.Enabled
+	if cfg.StatsD.Enabled {
+		errs = append(errs, cfg.StatsD.validateAndLog()...)
+	}
+
+	metricsEnabled := cfg.Influx.Enabled || cfg.Prometheus.Enabled || cfg.StatsD.Enabled
 	if cfg.Type == MetricsNone || cfg.Type == "" {
 		if !metricsEnabled {
 			log.Infof("Prebid Cache will run without metrics")
 		}
 	} else if cfg.Type != MetricsInflux {
-		// Was any other metrics system besides "InfluxDB" or "Prometheus" specified in `cfg.Type`?
+		// Was any other metrics system besides "InfluxDB", "Prometheus" or "StatsD" specified in `cfg.Type`?
 		if metricsEnabled {
-			// Prometheus, Influx or both, are enabled. Log a message explaining that `prebid-cache` will
+			// Prometheus, Influx, StatsD or a combination of them are enabled. Log a message explaining that `prebid-cache` will
 			// continue with supported metrics and non-supported metrics will be disabled
 			log.Infof("Prebid Cache will run without unsupported metrics \"%s\".", cfg.Type)
 		} else {
 			// The only metrics engine specified in the configuration file is a non-supported
-			// metrics engine. We should log error and exit program
-			log.Fatalf("Metrics \"%s\" are not supported, exiting program.", cfg.Type)
+			// metrics engine, which is a configuration error
+			errs = append(errs, fmt.Errorf("Metrics \"%s\" are not supported.", cfg.Type))
 		}
 	}
+	return errs
 }
 
 type MetricsType string
@@ -265,27 +533,84 @@ type InfluxMetrics struct {
 	Database        string `mapstructure:"database"`
 	Measurement     string `mapstructure:"measurement"`
 	Username        string `mapstructure:"username"`
-	Password        string `mapstructure:"password"`
+	Password        string `mapstructure:"password" secret:"true"`
+	PasswordFile    string `mapstructure:"password_file"`
 	AlignTimestamps bool   `mapstructure:"align_timestamps"`
+	// Tags are added to every point. Their values can refer to environment variables, as in "${HOSTNAME}".
+	Tags map[string]string `mapstructure:"tags"`
+	// FlushIntervalMillis is how often the metrics are written to Influx
+	FlushIntervalMillis int `mapstructure:"flush_interval_ms"`
+	// BufferSize is the number of points kept in memory while Influx can't be written to. The oldest
+	// points are dropped once it's full.
+	BufferSize int `mapstructure:"buffer_size"`
+	// MaxRetries is the number of times a failed write is retried before the points are kept for the next flush
+	MaxRetries int `mapstructure:"max_retries"`
+	// RetryBackoffMillis is the time waited before retrying a failed write
+	RetryBackoffMillis int `mapstructure:"retry_backoff_ms"`
 }
 
-func (influxMetricsConfig *InfluxMetrics) validateAndLog() {
+func (influxMetricsConfig *InfluxMetrics) validateAndLog() []error {
 	// validate
+	var errs []error
 	if influxMetricsConfig.Host == "" {
-		log.Fatalf(`Despite being enabled, influx metrics came with no host info: config.metrics.influx.host = "".`)
+		errs = append(errs, fmt.Errorf(`Despite being enabled, influx metrics came with no host info: config.metrics.influx.host = "".`))
 	}
 	if influxMetricsConfig.Database == "" {
-		log.Fatalf(`Despite being enabled, influx metrics came with no database info: config.metrics.influx.database = "".`)
+		errs = append(errs, fmt.Errorf(`Despite being enabled, influx metrics came with no database info: config.metrics.influx.database = "".`))
 	}
 	if influxMetricsConfig.Measurement == "" {
-		log.Fatalf(`Despite being enabled, influx metrics came with no measurement info: config.metrics.influx.measurement = "".`)
+		errs = append(errs, fmt.Errorf(`Despite being enabled, influx metrics came with no measurement info: config.metrics.influx.measurement = "".`))
+	}
+	for key := range influxMetricsConfig.Tags {
+		if key == "" {
+			errs = append(errs, fmt.Errorf("invalid config.metrics.influx.tags: tag keys cannot be empty."))
+		}
+	}
+	if influxMetricsConfig.FlushIntervalMillis <= 0 {
+		errs = append(errs, fmt.Errorf("invalid config.metrics.influx.flush_interval_ms: %d. Value must be positive.", influxMetricsConfig.FlushIntervalMillis))
+	}
+	if influxMetricsConfig.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid config.metrics.influx.buffer_size: %d. Value must be positive.", influxMetricsConfig.BufferSize))
+	}
+	if influxMetricsConfig.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("invalid config.metrics.influx.max_retries: %d. Value cannot be negative.", influxMetricsConfig.MaxRetries))
+	}
+	if influxMetricsConfig.RetryBackoffMillis < 0 {
+		errs = append(errs, fmt.Errorf("invalid config.metrics.influx.retry_backoff_ms: %d. Value cannot be negative.", influxMetricsConfig.RetryBackoffMillis))
+	}
+	if len(errs) > 0 {
+		return errs
 	}
 
 	// log
 	log.Infof("config.metrics.influx.host: %s", influxMetricsConfig.Host)
 	log.Infof("config.metrics.influx.database: %s", influxMetricsConfig.Database)
 	log.Infof("config.metrics.influx.measurement: %s", influxMetricsConfig.Measurement)
+	logSecret("config.metrics.influx.password", influxMetricsConfig.Password, influxMetricsConfig.PasswordFile)
 	log.Infof("config.metrics.influx.align_timestamps: %v", influxMetricsConfig.AlignTimestamps)
+	log.Infof("config.metrics.influx.tags: %v", influxMetricsConfig.ExpandedTags())
+	log.Infof("config.metrics.influx.flush_interval_ms: %d", influxMetricsConfig.FlushIntervalMillis)
+	log.Infof("config.metrics.influx.buffer_size: %d", influxMetricsConfig.BufferSize)
+	log.Infof("config.metrics.influx.max_retries: %d", influxMetricsConfig.MaxRetries)
+	log.Infof("config.metrics.influx.retry_backoff_ms: %d", influxMetricsConfig.RetryBackoffMillis)
+	return nil
+}
+
+// ExpandedTags returns the tags with the environment variables in their values replaced by their values
+func (influxMetricsConfig *InfluxMetrics) ExpandedTags() map[string]string {
+	tags := make(map[string]string, len(influxMetricsConfig.Tags))
+	for key, value := range influxMetricsConfig.Tags {
+		tags[key] = os.ExpandEnv(value)
+	}
+	return tags
+}
+
+func (influxMetricsConfig *InfluxMetrics) FlushInterval() time.Duration {
+	return time.Duration(influxMetricsConfig.FlushIntervalMillis) * time.Millisecond
+}
+
+func (influxMetricsConfig *InfluxMetrics) RetryBackoff() time.Duration {
+	return time.Duration(influxMetricsConfig.RetryBackoffMillis) * time.Millisecond
 }
 
 type PrometheusMetrics struct {
@@ -294,29 +619,456 @@ type PrometheusMetrics struct {
 	Subsystem        string `mapstructure:"subsystem"`
 	TimeoutMillisRaw int    `mapstructure:"timeout_ms"`
 	Enabled          bool   `mapstructure:"enabled"`
+	// AdminEndpoint serves the metrics on the /metrics route of the admin server. The dedicated
+	// server is only started if a port is set too.
+	AdminEndpoint bool `mapstructure:"admin_endpoint"`
+	// ProcessCollector adds the CPU, memory and file descriptor metrics of the process
+	ProcessCollector bool `mapstructure:"process_collector"`
+	// GoCollector adds the goroutine, garbage collector and memory statistics of the Go runtime
+	GoCollector bool `mapstructure:"go_collector"`
+	// Buckets are the upper bounds of the histogram buckets
+	Buckets PrometheusBuckets `mapstructure:"buckets"`
 }
 
-// validateAndLog will error out when the value of port is 0
-func (promMetricsConfig *PrometheusMetrics) validateAndLog() {
-	if promMetricsConfig.Port == 0 {
-		log.Fatalf(`Despite being enabled, prometheus metrics came with an empty port number: config.metrics.prometheus.port = 0`)
+// PrometheusBuckets holds the upper bounds of the buckets of each kind of histogram. An empty list
+// keeps the default buckets.
+type PrometheusBuckets struct {
+	// Duration buckets, in seconds, of the request and backend durations
+	Duration []float64 `mapstructure:"duration"`
+	// Size buckets, in bytes, of the values put and got
+	Size []float64 `mapstructure:"size"`
+	// TTL buckets, in seconds, of the values put
+	TTL []float64 `mapstructure:"ttl"`
+	// Age buckets, in seconds, of the values got
+	Age []float64 `mapstructure:"age"`
+}
+
+// validateAndLog will error out when the value of port is 0, unless the metrics are served on the admin port,
+// or when the bucket boundaries don't increase monotonically
+func (promMetricsConfig *PrometheusMetrics) validateAndLog() []error {
+	if promMetricsConfig.Port == 0 && !promMetricsConfig.AdminEndpoint {
+		return []error{fmt.Errorf(`Despite being enabled, prometheus metrics came with an empty port number: config.metrics.prometheus.port = 0`)}
+	}
+
+	var errs []error
+	buckets := []struct {
+		name   string
+		bounds []float64
+	}{
+		{"duration", promMetricsConfig.Buckets.Duration},
+		{"size", promMetricsConfig.Buckets.Size},
+		{"ttl", promMetricsConfig.Buckets.TTL},
+		{"age", promMetricsConfig.Buckets.Age},
+	}
+	for _, b := range buckets {
+		if !increasing(b.bounds) {
+			errs = append(errs, fmt.Errorf("invalid config.metrics.prometheus.buckets.%s: %v. Bucket boundaries must increase monotonically.", b.name, b.bounds))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
 	}
 
 	log.Infof("config.metrics.prometheus.namespace: %s", promMetricsConfig.Namespace)
 	log.Infof("config.metrics.prometheus.subsystem: %s", promMetricsConfig.Subsystem)
 	log.Infof("config.metrics.prometheus.port: %d", promMetricsConfig.Port)
+	log.Infof("config.metrics.prometheus.admin_endpoint: %t", promMetricsConfig.AdminEndpoint)
+	log.Infof("config.metrics.prometheus.process_collector: %t", promMetricsConfig.ProcessCollector)
+	log.Infof("config.metrics.prometheus.go_collector: %t", promMetricsConfig.GoCollector)
+	log.Infof("config.metrics.prometheus.buckets.duration: %v", promMetricsConfig.Buckets.Duration)
+	log.Infof("config.metrics.prometheus.buckets.size: %v", promMetricsConfig.Buckets.Size)
+	log.Infof("config.metrics.prometheus.buckets.ttl: %v", promMetricsConfig.Buckets.TTL)
+	log.Infof("config.metrics.prometheus.buckets.age: %v", promMetricsConfig.Buckets.Age)
+	return nil
+}
+
+// increasing tells whether every bound is greater than the one before it
+func increasing(bounds []float64) bool {
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			return false
+		}
+	}
+	return true
 }
 
 func (m *PrometheusMetrics) Timeout() time.Duration {
 	return time.Duration(m.TimeoutMillisRaw) * time.Millisecond
 }
 
+// StatsDMetrics sends the metrics over UDP in the StatsD format, to a StatsD server or a
+// DogStatsD agent
+type StatsDMetrics struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Host    string `mapstructure:"host"`
+	Port    int    `mapstructure:"port"`
+	// Prefix is prepended to every metric name, followed by a dot
+	Prefix string `mapstructure:"prefix"`
+	// SampleRate is the fraction of the events sent. The server scales the counts back up.
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// DogStatsD sends the statuses, formats and error types as tags instead of appending them to
+	// the metric names, and sends the sizes and TTLs as histograms
+	DogStatsD bool `mapstructure:"dogstatsd"`
+	// Tags are added to every metric, as "key:value" strings. They require DogStatsD.
+	Tags []string `mapstructure:"tags"`
+	// FlushIntervalMillis is how long metrics are buffered before they are sent. 0 sends every
+	// metric in its own packet.
+	FlushIntervalMillis int `mapstructure:"flush_interval_ms"`
+	// MaxPacketBytes caps the size of the packets, which hold as many buffered metrics as fit
+	MaxPacketBytes int `mapstructure:"max_packet_bytes"`
+}
+
+func (cfg *StatsDMetrics) validateAndLog() []error {
+	var errs []error
+	if cfg.Host == "" {
+		errs = append(errs, fmt.Errorf(`Despite being enabled, statsd metrics came with no host info: config.metrics.statsd.host = "".`))
+	}
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid config.metrics.statsd.port: %d", cfg.Port))
+	}
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("invalid config.metrics.statsd.sample_rate: %v. It must be greater than 0 and no greater than 1.", cfg.SampleRate))
+	}
+	if len(cfg.Tags) > 0 && !cfg.DogStatsD {
+		errs = append(errs, fmt.Errorf("config.metrics.statsd.tags require config.metrics.statsd.dogstatsd to be enabled"))
+	}
+	for _, tag := range cfg.Tags {
+		if tag == "" || strings.ContainsAny(tag, "|,#\n") {
+			errs = append(errs, fmt.Errorf("invalid config.metrics.statsd.tags: %q", tag))
+		}
+	}
+	if cfg.FlushIntervalMillis < 0 {
+		errs = append(errs, fmt.Errorf("invalid config.metrics.statsd.flush_interval_ms: %d. Value cannot be negative.", cfg.FlushIntervalMillis))
+	}
+	if cfg.MaxPacketBytes <= 0 {
+		errs = append(errs, fmt.Errorf("invalid config.metrics.statsd.max_packet_bytes: %d. Value must be positive.", cfg.MaxPacketBytes))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	log.Infof("config.metrics.statsd.host: %s", cfg.Host)
+	log.Infof("config.metrics.statsd.port: %d", cfg.Port)
+	log.Infof("config.metrics.statsd.prefix: %s", cfg.Prefix)
+	log.Infof("config.metrics.statsd.sample_rate: %v", cfg.SampleRate)
+	log.Infof("config.metrics.statsd.dogstatsd: %t", cfg.DogStatsD)
+	log.Infof("config.metrics.statsd.tags: %v", cfg.Tags)
+	log.Infof("config.metrics.statsd.flush_interval_ms: %d", cfg.FlushIntervalMillis)
+	log.Infof("config.metrics.statsd.max_packet_bytes: %d", cfg.MaxPacketBytes)
+	return nil
+}
+
+// FlushInterval is how long metrics are buffered before they are sent
+func (cfg *StatsDMetrics) FlushInterval() time.Duration {
+	return time.Duration(cfg.FlushIntervalMillis) * time.Millisecond
+}
+
 type Routes struct {
 	AllowPublicWrite bool `mapstructure:"allow_public_write"`
+	CORS             CORS `mapstructure:"cors"`
+	// ErrorFormat is the format of the error responses. Clients can ask for JSON errors with an
+	// "Accept: application/json" header whatever it is.
+	ErrorFormat ErrorFormat `mapstructure:"error_format"`
+	// ResponseEncodings lists the content codings GET /cache may compress its responses with, in
+	// order of preference, when clients accept them
+	ResponseEncodings []string `mapstructure:"response_encodings"`
 }
 
-func (cfg *Routes) validateAndLog() {
+// Peers lists the other Prebid Cache clusters that get requests may name in their "ch" parameter.
+// When the key isn't found locally, the request is forwarded to the named peer if it's allowed.
+type Peers struct {
+	// AllowedHosts lists the host, and optionally the port, of every peer requests can be forwarded to
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+	// Scheme is "http" or "https"
+	Scheme               string `mapstructure:"scheme"`
+	TimeoutMillis        int    `mapstructure:"timeout_ms"`
+	MaxResponseSizeBytes int    `mapstructure:"max_response_size_bytes"`
+}
+
+func (cfg *Peers) validateAndLog() []error {
+	var errs []error
+	validHosts := true
+	for _, host := range cfg.AllowedHosts {
+		if parsed, err := url.Parse("//" + host); err != nil || parsed.Host != host || parsed.User != nil || host == "" {
+			errs = append(errs, fmt.Errorf("invalid config.peers.allowed_hosts: %q. Values must be a host with an optional port, without a scheme or path.", host))
+			validHosts = false
+		}
+	}
+	if validHosts {
+		log.Infof("config.peers.allowed_hosts: %v", cfg.AllowedHosts)
+	}
+
+	if cfg.Scheme == "http" || cfg.Scheme == "https" {
+		log.Infof("config.peers.scheme: %s", cfg.Scheme)
+	} else {
+		errs = append(errs, fmt.Errorf("invalid config.peers.scheme: %s. Value must be \"http\" or \"https\".", cfg.Scheme))
+	}
+
+	if cfg.TimeoutMillis > 0 {
+		log.Infof("config.peers.timeout_ms: %d", cfg.TimeoutMillis)
+	} else {
+		errs = append(errs, fmt.Errorf("invalid config.peers.timeout_ms: %d. Value must be positive.", cfg.TimeoutMillis))
+	}
+
+	if cfg.MaxResponseSizeBytes > 0 {
+		log.Infof("config.peers.max_response_size_bytes: %d", cfg.MaxResponseSizeBytes)
+	} else {
+		errs = append(errs, fmt.Errorf("invalid config.peers.max_response_size_bytes: %d. Value must be positive.", cfg.MaxResponseSizeBytes))
+	}
+	return errs
+}
+
+// Timeout is how long peers have to answer the get requests forwarded to them
+func (cfg *Peers) Timeout() time.Duration {
+	return time.Duration(cfg.TimeoutMillis) * time.Millisecond
+}
+
+type ErrorFormat string
+
+const (
+	ErrorFormatText ErrorFormat = "text"
+	ErrorFormatJSON ErrorFormat = "json"
+)
+
+// The content codings GET /cache responses can be compressed with
+const (
+	EncodingGzip = "gzip"
+)
+
+func (cfg *Routes) validateAndLog() []error {
 	if !cfg.AllowPublicWrite {
 		log.Infof("Main server will only accept GET requests")
 	}
+
+	var errs []error
+	switch cfg.ErrorFormat {
+	case ErrorFormatText, ErrorFormatJSON:
+		log.Infof("config.routes.error_format: %s", cfg.ErrorFormat)
+	default:
+		errs = append(errs, fmt.Errorf("invalid config.routes.error_format: %s. Value must be \"text\" or \"json\".", cfg.ErrorFormat))
+	}
+
+	validEncodings := true
+	seen := make(map[string]bool, len(cfg.ResponseEncodings))
+	for _, encoding := range cfg.ResponseEncodings {
+		if encoding != EncodingGzip {
+			errs = append(errs, fmt.Errorf("invalid config.routes.response_encodings: %s. Values must be \"%s\".", encoding, EncodingGzip))
+			validEncodings = false
+		} else if seen[encoding] {
+			errs = append(errs, fmt.Errorf("invalid config.routes.response_encodings: %s is listed more than once.", encoding))
+			validEncodings = false
+		}
+		seen[encoding] = true
+	}
+	if validEncodings {
+		log.Infof("config.routes.response_encodings: %v", cfg.ResponseEncodings)
+	}
+
+	errs = append(errs, cfg.CORS.Get.validateAndLog("config.routes.cors.get")...)
+	if !cfg.AllowPublicWrite {
+		// The main server doesn't expose POST /cache, so there's nothing to apply the policy to
+		return errs
+	}
+	return append(errs, cfg.CORS.Post.validateAndLog("config.routes.cors.post")...)
+}
+
+// CORS holds the Cross-Origin Resource Sharing policies of the main server. Reads and writes
+// are configured separately so a permissive policy for video players fetching cached VAST
+// doesn't have to apply to the endpoint that stores it.
+type CORS struct {
+	Get  CORSPolicy `mapstructure:"get"`
+	Post CORSPolicy `mapstructure:"post"`
+}
+
+type CORSPolicy struct {
+	// AllowedOrigins lists the origins allowed to make cross-domain requests. An entry can be "*" to
+	// allow any origin, or a scheme://host[:port] origin whose host may start with a "*." wildcard to
+	// allow every subdomain, as in "https://*.example.com".
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`
+	AllowedMethods   []string `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	MaxAgeSeconds    int      `mapstructure:"max_age_seconds"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+}
+
+func (cfg *CORSPolicy) validateAndLog(prefix string) []error {
+	var errs []error
+	for _, origin := range cfg.AllowedOrigins {
+		if err := validateCORSOrigin(origin); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s.allowed_origins: %v", prefix, err))
+		}
+		if origin == "*" && cfg.AllowCredentials {
+			errs = append(errs, fmt.Errorf(`invalid %s.allowed_origins: "*" cannot be used when %s.allow_credentials is true. List the allowed origins explicitly`, prefix, prefix))
+		}
+	}
+	for _, method := range cfg.AllowedMethods {
+		if method == "*" || !isHTTPToken(method) {
+			errs = append(errs, fmt.Errorf("invalid %s.allowed_methods: %q is not a valid HTTP method", prefix, method))
+		}
+	}
+	for _, header := range cfg.AllowedHeaders {
+		if header != "*" && !isHTTPToken(header) {
+			errs = append(errs, fmt.Errorf("invalid %s.allowed_headers: %q is not a valid HTTP header name", prefix, header))
+		}
+	}
+	if cfg.MaxAgeSeconds < 0 {
+		errs = append(errs, fmt.Errorf("invalid %s.max_age_seconds: %d. Value cannot be negative.", prefix, cfg.MaxAgeSeconds))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	log.Infof("%s.allowed_origins: %v", prefix, cfg.AllowedOrigins)
+	log.Infof("%s.allowed_methods: %v", prefix, cfg.AllowedMethods)
+	log.Infof("%s.allowed_headers: %v", prefix, cfg.AllowedHeaders)
+	log.Infof("%s.max_age_seconds: %d", prefix, cfg.MaxAgeSeconds)
+	log.Infof("%s.allow_credentials: %t", prefix, cfg.AllowCredentials)
+	return nil
+}
+
+// validateCORSOrigin makes sure an origin pattern is either "*" or a scheme://host[:port] origin
+// with, at most, a wildcard covering the left-most labels of the host. Wildcards anywhere else
+// would let "https://*example.com" match "https://evilexample.com".
+func validateCORSOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+
+	schemeEnd := strings.Index(origin, "://")
+	if schemeEnd < 1 {
+		return fmt.Errorf("%q must be \"*\" or look like scheme://host[:port]", origin)
+	}
+	scheme, host := origin[:schemeEnd], origin[schemeEnd+3:]
+	if !isHTTPToken(scheme) || strings.Contains(scheme, "*") {
+		return fmt.Errorf("%q has an invalid scheme", origin)
+	}
+
+	if strings.HasPrefix(host, "*.") {
+		host = host[2:]
+	}
+	if strings.Contains(host, "*") {
+		return fmt.Errorf(`%q can only use a wildcard as the first label of the host, as in "https://*.example.com"`, origin)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@ ") {
+		return fmt.Errorf("%q must be a scheme://host[:port] origin without path, query or user info", origin)
+	}
+	if colon := strings.LastIndex(host, ":"); colon >= 0 && !strings.HasSuffix(host, "]") {
+		port := host[colon+1:]
+		if port == "" || strings.Trim(port, "0123456789") != "" {
+			return fmt.Errorf("%q has an invalid port", origin)
+		}
+	}
+	return nil
+}
+
+// isHTTPToken returns true if s is a non-empty RFC 7230 token, the syntax of methods and header names
+func isHTTPToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClientIP controls how Prebid Cache determines the address of the client that originated a request
+// when it runs behind load balancers or reverse proxies.
+type ClientIP struct {
+	// TrustedProxies lists the IP addresses or CIDR blocks of the proxies whose X-Forwarded-For and
+	// X-Real-IP headers and PROXY protocol headers can be believed. Forwarding headers coming from
+	// any other peer are ignored.
+	TrustedProxies []string      `mapstructure:"trusted_proxies"`
+	ProxyProtocol  ProxyProtocol `mapstructure:"proxy_protocol"`
+}
+
+// ProxyProtocol configures support for the HAProxy PROXY protocol, versions 1 and 2, on the main server listener.
+type ProxyProtocol struct {
+	Enabled             bool `mapstructure:"enabled"`
+	HeaderTimeoutMillis int  `mapstructure:"header_timeout_ms"`
+}
+
+func (cfg *ClientIP) validateAndLog() []error {
+	var errs []error
+	for _, entry := range cfg.TrustedProxies {
+		if _, err := utils.NewTrustedProxies([]string{entry}); err != nil {
+			errs = append(errs, fmt.Errorf("invalid config.client_ip.trusted_proxies: %v", err))
+		}
+	}
+	if len(errs) == 0 {
+		log.Infof("config.client_ip.trusted_proxies: %v", cfg.TrustedProxies)
+	}
+
+	if cfg.ProxyProtocol.Enabled {
+		proxyProtocolValid := true
+		if len(cfg.TrustedProxies) == 0 {
+			errs = append(errs, fmt.Errorf("config.client_ip.proxy_protocol.enabled requires at least one entry in config.client_ip.trusted_proxies"))
+			proxyProtocolValid = false
+		}
+		if cfg.ProxyProtocol.HeaderTimeoutMillis <= 0 {
+			errs = append(errs, fmt.Errorf("invalid config.client_ip.proxy_protocol.header_timeout_ms: %d. Value must be positive.", cfg.ProxyProtocol.HeaderTimeoutMillis))
+			proxyProtocolValid = false
+		}
+		if proxyProtocolValid {
+			log.Infof("config.client_ip.proxy_protocol.enabled: %t", cfg.ProxyProtocol.Enabled)
+			log.Infof("config.client_ip.proxy_protocol.header_timeout_ms: %d", cfg.ProxyProtocol.HeaderTimeoutMillis)
+		}
+	}
+	return errs
+}
+
+// HeaderTimeout is the maximum amount of time a trusted proxy has to send the PROXY protocol header
+func (cfg *ProxyProtocol) HeaderTimeout() time.Duration {
+	return time.Duration(cfg.HeaderTimeoutMillis) * time.Millisecond
+}
+
+// Shutdown controls the steps Prebid Cache takes after receiving SIGTERM or SIGINT. First, /status
+// starts reporting that the server is not ready and requests keep being served for the pre-stop
+// delay, so load balancers stop sending traffic. Then the servers stop accepting connections and
+// wait for in-flight requests to finish, and finally the backend clients are closed.
+type Shutdown struct {
+	PreStopDelayMillis int `mapstructure:"pre_stop_delay_ms"`
+	DrainTimeoutMillis int `mapstructure:"drain_timeout_ms"`
+	CloseTimeoutMillis int `mapstructure:"close_timeout_ms"`
+}
+
+func (cfg *Shutdown) validateAndLog() []error {
+	var errs []error
+	if cfg.PreStopDelayMillis < 0 {
+		errs = append(errs, fmt.Errorf("invalid config.shutdown.pre_stop_delay_ms: %d. Value cannot be negative.", cfg.PreStopDelayMillis))
+	}
+	if cfg.DrainTimeoutMillis <= 0 {
+		errs = append(errs, fmt.Errorf("invalid config.shutdown.drain_timeout_ms: %d. Value must be positive.", cfg.DrainTimeoutMillis))
+	}
+	if cfg.CloseTimeoutMillis <= 0 {
+		errs = append(errs, fmt.Errorf("invalid config.shutdown.close_timeout_ms: %d. Value must be positive.", cfg.CloseTimeoutMillis))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	log.Infof("config.shutdown.pre_stop_delay_ms: %d", cfg.PreStopDelayMillis)
+	log.Infof("config.shutdown.drain_timeout_ms: %d", cfg.DrainTimeoutMillis)
+	log.Infof("config.shutdown.close_timeout_ms: %d", cfg.CloseTimeoutMillis)
+	return nil
+}
+
+// PreStopDelay is how long requests keep being served after /status starts reporting that the server is not ready
+func (cfg *Shutdown) PreStopDelay() time.Duration {
+	return time.Duration(cfg.PreStopDelayMillis) * time.Millisecond
+}
+
+// DrainTimeout is how long in-flight requests, along with their backend writes, have to finish
+func (cfg *Shutdown) DrainTimeout() time.Duration {
+	return time.Duration(cfg.DrainTimeoutMillis) * time.Millisecond
+}
+
+// CloseTimeout is how long the backend clients have to close their connections
+func (cfg *Shutdown) CloseTimeout() time.Duration {
+	return time.Duration(cfg.CloseTimeoutMillis) * time.Millisecond
 }
@@ -46,26 +46,30 @@ func TestLogValidateAndLog(t *testing.T) {
 
 	// Define object to run `validateAndLog()` on
 	configLogObject := Log{
-		Level: Debug,
+		Level:     Debug,
+		AccessLog: AccessLog{Enabled: true},
 	}
 
 	// run test
-	configLogObject.validateAndLog()
+	assert.Empty(t, configLogObject.validateAndLog())
 
 	// Assert logrus entries
-	if !assert.Equal(t, 1, len(hook.Entries), "No entries were logged to logrus.") {
-		return
-	}
-	if !assert.Equal(t, logrus.InfoLevel, hook.LastEntry().Level) {
-		return
-	}
-	if !assert.Equal(t, "config.log.level: debug", hook.LastEntry().Message) {
+	if !assert.Equal(t, 2, len(hook.Entries), "No entries were logged to logrus.") {
 		return
 	}
+	assert.Equal(t, logrus.InfoLevel, hook.Entries[0].Level)
+	assert.Equal(t, "config.log.level: debug", hook.Entries[0].Message)
+	assert.Equal(t, logrus.InfoLevel, hook.Entries[1].Level)
+	assert.Equal(t, "config.log.access_log.enabled: true", hook.Entries[1].Message)
 
 	// Reset logrus
 	hook.Reset()
 	assert.Nil(t, hook.LastEntry())
+
+	// An unknown level is rejected without being logged
+	configLogObject.Level = LogLevel("verbose")
+	assert.Equal(t, []error{fmt.Errorf("invalid config.log.level: verbose")}, configLogObject.validateAndLog())
+	assert.Nil(t, hook.LastEntry())
 }
 
 func TestCheckMetricsEnabled(t *testing.T) {
@@ -90,6 +94,34 @@ func TestCheckMetricsEnabled(t *testing.T) {
 			msg: "config.metrics.prometheus.port: 8080",
 			lvl: logrus.InfoLevel,
 		},
+		{
+			msg: "config.metrics.prometheus.admin_endpoint: false",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.prometheus.process_collector: false",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.prometheus.go_collector: false",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.prometheus.buckets.duration: []",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.prometheus.buckets.size: []",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.prometheus.buckets.ttl: []",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.prometheus.buckets.age: []",
+			lvl: logrus.InfoLevel,
+		},
 	}
 
 	// Influx success
@@ -110,6 +142,26 @@ func TestCheckMetricsEnabled(t *testing.T) {
 			msg: "config.metrics.influx.align_timestamps: false",
 			lvl: logrus.InfoLevel,
 		},
+		{
+			msg: "config.metrics.influx.tags: map[]",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.influx.flush_interval_ms: 10000",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.influx.buffer_size: 10000",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.influx.max_retries: 0",
+			lvl: logrus.InfoLevel,
+		},
+		{
+			msg: "config.metrics.influx.retry_backoff_ms: 0",
+			lvl: logrus.InfoLevel,
+		},
 	}
 
 	// test cases
@@ -199,12 +251,7 @@ func TestCheckMetricsEnabled(t *testing.T) {
 			prometheusEnabled: false,
 			metricType:        "unknown",
 			expectedError:     true,
-			expectedLogInfo: []logComponents{
-				{
-					msg: "Metrics \"unknown\" are not supported, exiting program.",
-					lvl: logrus.FatalLevel,
-				},
-			},
+			expectedLogInfo:   []logComponents{},
 		},
 		{
 			description:       "[10] metricType = \"unknown\"; prometheus flags on.",
@@ -245,6 +292,13 @@ func TestCheckMetricsEnabled(t *testing.T) {
 				prometheusSuccess[0],
 				prometheusSuccess[1],
 				prometheusSuccess[2],
+				prometheusSuccess[3],
+				prometheusSuccess[4],
+				prometheusSuccess[5],
+				prometheusSuccess[6],
+				prometheusSuccess[7],
+				prometheusSuccess[8],
+				prometheusSuccess[9],
 				logComponents{
 					msg: "Prebid Cache will run without unsupported metrics \"unknown\".",
 					lvl: logrus.InfoLevel,
@@ -293,9 +347,11 @@ func TestCheckMetricsEnabled(t *testing.T) {
 	//Standard elements of the config.Metrics object are set so test cases only modify what's relevant to them
 	cfg := &Metrics{
 		Influx: InfluxMetrics{
-			Host:        "http://fakeurl.com",
-			Database:    "database-value",
-			Measurement: "measurement-value",
+			Host:                "http://fakeurl.com",
+			Database:            "database-value",
+			Measurement:         "measurement-value",
+			FlushIntervalMillis: 10000,
+			BufferSize:          10000,
 		},
 		Prometheus: PrometheusMetrics{
 			Port:      8080,
@@ -307,22 +363,14 @@ func TestCheckMetricsEnabled(t *testing.T) {
 	// logrus entries will be recorded to this `hook` object so we can compare and assert them
 	hook := testLogrus.NewGlobal()
 
-	//substitute logger exit function so execution doesn't get interrupted when log.Fatalf() call comes
-	defer func() { logrus.StandardLogger().ExitFunc = nil }()
-	var fatal bool
-	logrus.StandardLogger().ExitFunc = func(int) { fatal = true }
-
 	for i, tc := range testCases {
-		// Reset the fatal flag to false every test
-		fatal = false
-
 		// Set test flags in metrics object
 		cfg.Type = tc.metricType
 		cfg.Influx.Enabled = tc.influxEnabled
 		cfg.Prometheus.Enabled = tc.prometheusEnabled
 
 		//run test
-		cfg.validateAndLog()
+		err := cfg.validateAndLog()
 
 		// Assert logrus expected entries
 		if assert.Equal(t, len(tc.expectedLogInfo), len(hook.Entries), "Incorrect number of entries were logged to logrus in test %d: len(tc.expectedLogInfo) = %d len(hook.Entries) = %d", i+1, len(tc.expectedLogInfo), len(hook.Entries)) {
@@ -334,8 +382,8 @@ func TestCheckMetricsEnabled(t *testing.T) {
 			return
 		}
 
-		// Assert log.Fatalf() was called or not
-		assert.Equal(t, tc.expectedError, fatal, "Test case %d failed.", i+1)
+		// Assert an error was returned or not
+		assert.Equal(t, tc.expectedError, len(err) > 0, "Test case %d failed.", i+1)
 
 		//Reset log after every test and assert successful reset
 		hook.Reset()
@@ -447,18 +495,17 @@ func TestEnabledFlagGetsModified(t *testing.T) {
 	// logrus entries will be recorded to this `hook` object so we can compare and assert them
 	hook := testLogrus.NewGlobal()
 
-	//substitute logger exit function so execution doesn't get interrupted when log.Fatalf() call comes
-	defer func() { logrus.StandardLogger().ExitFunc = nil }()
-	logrus.StandardLogger().ExitFunc = func(int) {}
-
 	for i, tc := range testCases {
 		// Reset Metrics object
 		metricsCfg := Metrics{
 			Type: tc.in.metricType,
 			Influx: InfluxMetrics{
-				Host:     "http://fakeurl.com",
-				Database: "database-value",
-				Enabled:  tc.in.influxEnabled,
+				Host:                "http://fakeurl.com",
+				Database:            "database-value",
+				Measurement:         "measurement-value",
+				FlushIntervalMillis: 10000,
+				BufferSize:          10000,
+				Enabled:             tc.in.influxEnabled,
 			},
 			Prometheus: PrometheusMetrics{
 				Port:      8080,
@@ -494,121 +541,171 @@ func TestInfluxValidateAndLog(t *testing.T) {
 		// In
 		influxConfig *InfluxMetrics
 		//out
-		expectError     bool
+		expectedErrors  []error
 		expectedLogInfo []logComponents
 	}
 	testCases := []aTest{
 		{
 			description: "All Required Fields Missing",
 			influxConfig: &InfluxMetrics{
-				Host:        "",
-				Database:    "",
-				Measurement: "",
+				Host:                "",
+				Database:            "",
+				Measurement:         "",
+				FlushIntervalMillis: 10000,
+				BufferSize:          10000,
+				MaxRetries:          3,
+				RetryBackoffMillis:  1000,
 			},
-			expectError: true,
-			expectedLogInfo: []logComponents{
-				{lvl: logrus.FatalLevel, msg: `Despite being enabled, influx metrics came with no host info: config.metrics.influx.host = "".`},
-				{lvl: logrus.FatalLevel, msg: `Despite being enabled, influx metrics came with no database info: config.metrics.influx.database = "".`},
-				{lvl: logrus.FatalLevel, msg: `Despite being enabled, influx metrics came with no measurement info: config.metrics.influx.measurement = "".`},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.host: "},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.database: "},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.measurement: "},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.align_timestamps: false"},
+			expectedErrors: []error{
+				fmt.Errorf(`Despite being enabled, influx metrics came with no host info: config.metrics.influx.host = "".`),
+				fmt.Errorf(`Despite being enabled, influx metrics came with no database info: config.metrics.influx.database = "".`),
+				fmt.Errorf(`Despite being enabled, influx metrics came with no measurement info: config.metrics.influx.measurement = "".`),
 			},
+			expectedLogInfo: []logComponents{},
 		},
 		{
 			description: "Host Missing",
 			influxConfig: &InfluxMetrics{
-				Host:        "",
-				Database:    "database-value",
-				Measurement: "measurement-value",
-			},
-			expectError: true,
-			expectedLogInfo: []logComponents{
-				{lvl: logrus.FatalLevel, msg: `Despite being enabled, influx metrics came with no host info: config.metrics.influx.host = "".`},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.host: "},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.database: database-value"},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.measurement: measurement-value"},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.align_timestamps: false"},
+				Host:                "",
+				Database:            "database-value",
+				Measurement:         "measurement-value",
+				FlushIntervalMillis: 10000,
+				BufferSize:          10000,
+				MaxRetries:          3,
+				RetryBackoffMillis:  1000,
 			},
+			expectedErrors:  []error{fmt.Errorf(`Despite being enabled, influx metrics came with no host info: config.metrics.influx.host = "".`)},
+			expectedLogInfo: []logComponents{},
 		},
 		{
 			description: "Database Missing",
 			influxConfig: &InfluxMetrics{
-				Host:        "http://fakeurl.com",
-				Database:    "",
-				Measurement: "measurement-value",
-			},
-			expectError: true,
-			expectedLogInfo: []logComponents{
-				{lvl: logrus.FatalLevel, msg: `Despite being enabled, influx metrics came with no database info: config.metrics.influx.database = "".`},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.host: http://fakeurl.com"},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.database: "},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.measurement: measurement-value"},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.align_timestamps: false"},
+				Host:                "http://fakeurl.com",
+				Database:            "",
+				Measurement:         "measurement-value",
+				FlushIntervalMillis: 10000,
+				BufferSize:          10000,
+				MaxRetries:          3,
+				RetryBackoffMillis:  1000,
 			},
+			expectedErrors:  []error{fmt.Errorf(`Despite being enabled, influx metrics came with no database info: config.metrics.influx.database = "".`)},
+			expectedLogInfo: []logComponents{},
 		},
 		{
 			description: "Measurement Missing",
 			influxConfig: &InfluxMetrics{
-				Host:        "http://fakeurl.com",
-				Database:    "database-value",
-				Measurement: "",
+				Host:                "http://fakeurl.com",
+				Database:            "database-value",
+				Measurement:         "",
+				FlushIntervalMillis: 10000,
+				BufferSize:          10000,
+				MaxRetries:          3,
+				RetryBackoffMillis:  1000,
+			},
+			expectedErrors:  []error{fmt.Errorf(`Despite being enabled, influx metrics came with no measurement info: config.metrics.influx.measurement = "".`)},
+			expectedLogInfo: []logComponents{},
+		},
+		{
+			description: "All Required Fields Provided",
+			influxConfig: &InfluxMetrics{
+				Host:                "http://fakeurl.com",
+				Database:            "database-value",
+				Measurement:         "measurement-value",
+				FlushIntervalMillis: 10000,
+				BufferSize:          10000,
+				MaxRetries:          3,
+				RetryBackoffMillis:  1000,
+				AlignTimestamps:     true,
 			},
-			expectError: true,
 			expectedLogInfo: []logComponents{
-				{lvl: logrus.FatalLevel, msg: `Despite being enabled, influx metrics came with no measurement info: config.metrics.influx.measurement = "".`},
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.host: http://fakeurl.com"},
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.database: database-value"},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.measurement: "},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.align_timestamps: false"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.measurement: measurement-value"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.align_timestamps: true"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.tags: map[]"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.flush_interval_ms: 10000"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.buffer_size: 10000"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.max_retries: 3"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.retry_backoff_ms: 1000"},
 			},
 		},
 		{
-			description: "All Required Fields Provided",
+			description: "Align Timestamps",
 			influxConfig: &InfluxMetrics{
-				Host:            "http://fakeurl.com",
-				Database:        "database-value",
-				Measurement:     "measurement-value",
-				AlignTimestamps: true,
+				Host:                "http://fakeurl.com",
+				Database:            "database-value",
+				Measurement:         "measurement-value",
+				FlushIntervalMillis: 10000,
+				BufferSize:          10000,
+				MaxRetries:          3,
+				RetryBackoffMillis:  1000,
+				AlignTimestamps:     true,
 			},
-			expectError: false,
 			expectedLogInfo: []logComponents{
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.host: http://fakeurl.com"},
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.database: database-value"},
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.measurement: measurement-value"},
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.align_timestamps: true"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.tags: map[]"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.flush_interval_ms: 10000"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.buffer_size: 10000"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.max_retries: 3"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.retry_backoff_ms: 1000"},
 			},
 		},
 		{
-			description: "Align Timestamps",
+			description: "Tags expanded from the environment",
 			influxConfig: &InfluxMetrics{
-				Host:            "http://fakeurl.com",
-				Database:        "database-value",
-				Measurement:     "measurement-value",
-				AlignTimestamps: true,
+				Host:                "http://fakeurl.com",
+				Database:            "database-value",
+				Measurement:         "measurement-value",
+				Tags:                map[string]string{"host": "${PBC_TEST_INFLUX_HOST}", "region": "us-east"},
+				FlushIntervalMillis: 10000,
+				BufferSize:          10000,
+				MaxRetries:          3,
+				RetryBackoffMillis:  1000,
 			},
-			expectError: false,
 			expectedLogInfo: []logComponents{
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.host: http://fakeurl.com"},
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.database: database-value"},
 				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.measurement: measurement-value"},
-				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.align_timestamps: true"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.align_timestamps: false"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.tags: map[host:cache-1 region:us-east]"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.flush_interval_ms: 10000"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.buffer_size: 10000"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.max_retries: 3"},
+				{lvl: logrus.InfoLevel, msg: "config.metrics.influx.retry_backoff_ms: 1000"},
+			},
+		},
+		{
+			description: "Invalid delivery settings",
+			influxConfig: &InfluxMetrics{
+				Host:                "http://fakeurl.com",
+				Database:            "database-value",
+				Measurement:         "measurement-value",
+				Tags:                map[string]string{"": "value"},
+				FlushIntervalMillis: 0,
+				BufferSize:          -1,
+				MaxRetries:          -1,
+				RetryBackoffMillis:  -1,
 			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.metrics.influx.tags: tag keys cannot be empty."),
+				fmt.Errorf("invalid config.metrics.influx.flush_interval_ms: 0. Value must be positive."),
+				fmt.Errorf("invalid config.metrics.influx.buffer_size: -1. Value must be positive."),
+				fmt.Errorf("invalid config.metrics.influx.max_retries: -1. Value cannot be negative."),
+				fmt.Errorf("invalid config.metrics.influx.retry_backoff_ms: -1. Value cannot be negative."),
+			},
+			expectedLogInfo: []logComponents{},
 		},
 	}
 
-	//substitute logger exit function so execution doesn't get interrupted
-	defer func() { logrus.StandardLogger().ExitFunc = nil }()
-	var fatal bool
-	logrus.StandardLogger().ExitFunc = func(int) { fatal = true }
+	os.Setenv("PBC_TEST_INFLUX_HOST", "cache-1")
+	defer os.Unsetenv("PBC_TEST_INFLUX_HOST")
 
 	for j, tc := range testCases {
-		// Reset the fatal flag to false every test
-		fatal = false
-
 		//run test
-		tc.influxConfig.validateAndLog()
+		err := tc.influxConfig.validateAndLog()
 
 		// Assert logrus expected entries
 		if assert.Equal(t, len(tc.expectedLogInfo), len(hook.Entries), "Incorrect number of entries were logged to logrus in test %d: len(tc.expectedLogInfo) = %d len(hook.Entries) = %d", j, len(tc.expectedLogInfo), len(hook.Entries)) {
@@ -620,8 +717,8 @@ func TestInfluxValidateAndLog(t *testing.T) {
 			return
 		}
 
-		// Assert log.Fatalf() was called or not
-		assert.Equal(t, tc.expectError, fatal)
+		// Assert the expected error was returned
+		assert.Equal(t, tc.expectedErrors, err, "Test case %d failed", j)
 
 		//Reset log after every test and assert successful reset
 		hook.Reset()
@@ -640,7 +737,7 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 		// In
 		prometheusConfig *PrometheusMetrics
 		//out
-		expectError     bool
+		expectedErrors  []error
 		expectedLogInfo []logComponents
 	}
 	testCases := []aTest{
@@ -651,12 +748,20 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 				Namespace: "prebid",
 				Subsystem: "cache",
 			},
-			expectError: true,
+			expectedErrors:  []error{fmt.Errorf(`Despite being enabled, prometheus metrics came with an empty port number: config.metrics.prometheus.port = 0`)},
+			expectedLogInfo: []logComponents{},
+		},
+		{
+			description: "Port empty, metrics served on the admin port. Don't expect error",
+			prometheusConfig: &PrometheusMetrics{
+				Port:             0,
+				Namespace:        "prebid",
+				Subsystem:        "cache",
+				AdminEndpoint:    true,
+				ProcessCollector: true,
+				GoCollector:      true,
+			},
 			expectedLogInfo: []logComponents{
-				{
-					msg: `Despite being enabled, prometheus metrics came with an empty port number: config.metrics.prometheus.port = 0`,
-					lvl: logrus.FatalLevel,
-				},
 				{
 					msg: "config.metrics.prometheus.namespace: prebid",
 					lvl: logrus.InfoLevel,
@@ -669,6 +774,34 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 					msg: "config.metrics.prometheus.port: 0",
 					lvl: logrus.InfoLevel,
 				},
+				{
+					msg: "config.metrics.prometheus.admin_endpoint: true",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.process_collector: true",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.go_collector: true",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.duration: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.size: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.ttl: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.age: []",
+					lvl: logrus.InfoLevel,
+				},
 			},
 		},
 		{
@@ -678,7 +811,6 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 				Namespace: "",
 				Subsystem: "cache",
 			},
-			expectError: false,
 			expectedLogInfo: []logComponents{
 				{
 					msg: "config.metrics.prometheus.namespace: ",
@@ -692,6 +824,34 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 					msg: "config.metrics.prometheus.port: 8080",
 					lvl: logrus.InfoLevel,
 				},
+				{
+					msg: "config.metrics.prometheus.admin_endpoint: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.process_collector: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.go_collector: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.duration: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.size: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.ttl: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.age: []",
+					lvl: logrus.InfoLevel,
+				},
 			},
 		},
 		{
@@ -701,7 +861,6 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 				Namespace: "prebid",
 				Subsystem: "",
 			},
-			expectError: false,
 			expectedLogInfo: []logComponents{
 				{
 					msg: "config.metrics.prometheus.namespace: prebid",
@@ -715,6 +874,34 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 					msg: "config.metrics.prometheus.port: 8080",
 					lvl: logrus.InfoLevel,
 				},
+				{
+					msg: "config.metrics.prometheus.admin_endpoint: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.process_collector: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.go_collector: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.duration: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.size: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.ttl: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.age: []",
+					lvl: logrus.InfoLevel,
+				},
 			},
 		},
 		{
@@ -724,7 +911,6 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 				Namespace: "prebid",
 				Subsystem: "cache",
 			},
-			expectError: false,
 			expectedLogInfo: []logComponents{
 				{
 					msg: "config.metrics.prometheus.namespace: prebid",
@@ -738,6 +924,34 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 					msg: "config.metrics.prometheus.port: 8080",
 					lvl: logrus.InfoLevel,
 				},
+				{
+					msg: "config.metrics.prometheus.admin_endpoint: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.process_collector: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.go_collector: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.duration: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.size: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.ttl: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.age: []",
+					lvl: logrus.InfoLevel,
+				},
 			},
 		},
 		{
@@ -747,7 +961,6 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 				Namespace: "",
 				Subsystem: "",
 			},
-			expectError: false,
 			expectedLogInfo: []logComponents{
 				{
 					msg: "config.metrics.prometheus.namespace: ",
@@ -761,24 +974,84 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 					msg: "config.metrics.prometheus.port: 8080",
 					lvl: logrus.InfoLevel,
 				},
+				{
+					msg: "config.metrics.prometheus.admin_endpoint: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.process_collector: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.go_collector: false",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.duration: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.size: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.ttl: []",
+					lvl: logrus.InfoLevel,
+				},
+				{
+					msg: "config.metrics.prometheus.buckets.age: []",
+					lvl: logrus.InfoLevel,
+				},
+			},
+		},
+		{
+			description: "Custom buckets that increase monotonically. Expect them in log",
+			prometheusConfig: &PrometheusMetrics{
+				Port: 8080,
+				Buckets: PrometheusBuckets{
+					Duration: []float64{0.0005, 0.001, 0.005},
+					Size:     []float64{1024},
+					TTL:      []float64{60, 3600},
+					Age:      []float64{1, 60},
+				},
+			},
+			expectedLogInfo: []logComponents{
+				{msg: "config.metrics.prometheus.namespace: ", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.subsystem: ", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.port: 8080", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.admin_endpoint: false", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.process_collector: false", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.go_collector: false", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.buckets.duration: [0.0005 0.001 0.005]", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.buckets.size: [1024]", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.buckets.ttl: [60 3600]", lvl: logrus.InfoLevel},
+				{msg: "config.metrics.prometheus.buckets.age: [1 60]", lvl: logrus.InfoLevel},
+			},
+		},
+		{
+			description: "Buckets that don't increase monotonically. Expect an error for each of them",
+			prometheusConfig: &PrometheusMetrics{
+				Port: 8080,
+				Buckets: PrometheusBuckets{
+					Duration: []float64{0.005, 0.001},
+					Size:     []float64{1024, 1024},
+					TTL:      []float64{60, 3600},
+				},
 			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.metrics.prometheus.buckets.duration: [0.005 0.001]. Bucket boundaries must increase monotonically."),
+				fmt.Errorf("invalid config.metrics.prometheus.buckets.size: [1024 1024]. Bucket boundaries must increase monotonically."),
+			},
+			expectedLogInfo: []logComponents{},
 		},
 	}
 
 	// logrus entries will be recorded to this `hook` object so we can compare and assert them
 	hook := testLogrus.NewGlobal()
 
-	//substitute logger exit function so execution doesn't get interrupted
-	defer func() { logrus.StandardLogger().ExitFunc = nil }()
-	var fatal bool
-	logrus.StandardLogger().ExitFunc = func(int) { fatal = true }
-
 	for _, tc := range testCases {
-		// Reset the fatal flag to false every test
-		fatal = false
-
 		//run test
-		tc.prometheusConfig.validateAndLog()
+		err := tc.prometheusConfig.validateAndLog()
 
 		// Assert logrus expected entries
 		if assert.Equal(t, len(tc.expectedLogInfo), len(hook.Entries), "Incorrect number of entries were logged to logrus in test %s.", tc.description) {
@@ -790,8 +1063,8 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 			return
 		}
 
-		// Assert log.Fatalf() was called or not
-		assert.Equal(t, tc.expectError, fatal)
+		// Assert the expected error was returned
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
 
 		//Reset log after every test and assert successful reset
 		hook.Reset()
@@ -799,103 +1072,232 @@ func TestPrometheusValidateAndLog(t *testing.T) {
 	}
 }
 
-func TestRequestLimitsValidateAndLog(t *testing.T) {
-
-	// logrus entries will be recorded to this `hook` object so we can compare and assert them
-	hook := testLogrus.NewGlobal()
-
-	type logComponents struct {
-		msg string
-		lvl logrus.Level
+func TestStatsDValidateAndLog(t *testing.T) {
+	validConfig := func() StatsDMetrics {
+		return StatsDMetrics{
+			Enabled:             true,
+			Host:                "127.0.0.1",
+			Port:                8125,
+			Prefix:              "prebid_cache",
+			SampleRate:          0.5,
+			DogStatsD:           true,
+			Tags:                []string{"env:prod"},
+			FlushIntervalMillis: 100,
+			MaxPacketBytes:      1432,
+		}
 	}
 
 	testCases := []struct {
-		description        string
-		inRequestLimitsCfg *RequestLimits
-		expectedLogInfo    []logComponents
-		expectFatal        bool
+		description     string
+		modify          func(cfg *StatsDMetrics)
+		expectedErrors  []error
+		expectedLogInfo []string
 	}{
 		{
-			description:        "Blank RequestLimits",
-			inRequestLimitsCfg: &RequestLimits{},
-			expectedLogInfo: []logComponents{
-				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
-				{msg: `config.request_limits.max_ttl_seconds: 0`, lvl: logrus.InfoLevel},
-				{msg: `config.request_limits.max_size_bytes: 0`, lvl: logrus.InfoLevel},
-				{msg: `config.request_limits.max_num_values: 0`, lvl: logrus.InfoLevel},
+			description: "Valid config. Expect every value to be logged",
+			modify:      func(cfg *StatsDMetrics) {},
+			expectedLogInfo: []string{
+				"config.metrics.statsd.host: 127.0.0.1",
+				"config.metrics.statsd.port: 8125",
+				"config.metrics.statsd.prefix: prebid_cache",
+				"config.metrics.statsd.sample_rate: 0.5",
+				"config.metrics.statsd.dogstatsd: true",
+				"config.metrics.statsd.tags: [env:prod]",
+				"config.metrics.statsd.flush_interval_ms: 100",
+				"config.metrics.statsd.max_packet_bytes: 1432",
 			},
-			expectFatal: false,
 		},
 		{
-			description:        "allow_setting_keys flag set to true",
-			inRequestLimitsCfg: &RequestLimits{AllowSettingKeys: true},
-			expectedLogInfo: []logComponents{
-				{msg: `config.request_limits.allow_setting_keys: true`, lvl: logrus.InfoLevel},
-				{msg: `config.request_limits.max_ttl_seconds: 0`, lvl: logrus.InfoLevel},
-				{msg: `config.request_limits.max_size_bytes: 0`, lvl: logrus.InfoLevel},
-				{msg: `config.request_limits.max_num_values: 0`, lvl: logrus.InfoLevel},
+			description: "Missing host and out of range port",
+			modify: func(cfg *StatsDMetrics) {
+				cfg.Host = ""
+				cfg.Port = 70000
+			},
+			expectedErrors: []error{
+				fmt.Errorf(`Despite being enabled, statsd metrics came with no host info: config.metrics.statsd.host = "".`),
+				fmt.Errorf("invalid config.metrics.statsd.port: 70000"),
 			},
-			expectFatal: false,
 		},
 		{
-			description:        "Negative max_ttl_seconds, expect fatal level log and early exit",
-			inRequestLimitsCfg: &RequestLimits{MaxTTLSeconds: -1},
-			expectedLogInfo: []logComponents{
-				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
-				{msg: `invalid config.request_limits.max_ttl_seconds: -1. Value cannot be negative.`, lvl: logrus.FatalLevel},
+			description: "Sample rate of zero",
+			modify:      func(cfg *StatsDMetrics) { cfg.SampleRate = 0 },
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.metrics.statsd.sample_rate: 0. It must be greater than 0 and no greater than 1."),
 			},
-			expectFatal: true,
 		},
 		{
-			description:        "Negative max_size_bytes, expect fatal level log and early exit",
-			inRequestLimitsCfg: &RequestLimits{MaxSize: -1},
-			expectedLogInfo: []logComponents{
-				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
-				{msg: `config.request_limits.max_ttl_seconds: 0`, lvl: logrus.InfoLevel},
-				{msg: `invalid config.request_limits.max_size_bytes: -1. Value cannot be negative.`, lvl: logrus.FatalLevel},
+			description: "Sample rate greater than one",
+			modify:      func(cfg *StatsDMetrics) { cfg.SampleRate = 1.5 },
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.metrics.statsd.sample_rate: 1.5. It must be greater than 0 and no greater than 1."),
 			},
-			expectFatal: true,
 		},
 		{
-			description:        "Negative max_num_values, expect fatal level log and early exit",
-			inRequestLimitsCfg: &RequestLimits{MaxNumValues: -1},
+			description: "Tags without DogStatsD",
+			modify:      func(cfg *StatsDMetrics) { cfg.DogStatsD = false },
+			expectedErrors: []error{
+				fmt.Errorf("config.metrics.statsd.tags require config.metrics.statsd.dogstatsd to be enabled"),
+			},
+		},
+		{
+			description: "Tag that would break the line format",
+			modify:      func(cfg *StatsDMetrics) { cfg.Tags = []string{"env:prod|c"} },
+			expectedErrors: []error{
+				fmt.Errorf(`invalid config.metrics.statsd.tags: "env:prod|c"`),
+			},
+		},
+		{
+			description: "Negative flush interval and empty packets",
+			modify: func(cfg *StatsDMetrics) {
+				cfg.FlushIntervalMillis = -1
+				cfg.MaxPacketBytes = 0
+			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.metrics.statsd.flush_interval_ms: -1. Value cannot be negative."),
+				fmt.Errorf("invalid config.metrics.statsd.max_packet_bytes: 0. Value must be positive."),
+			},
+		},
+	}
+
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	for _, tc := range testCases {
+		cfg := validConfig()
+		tc.modify(&cfg)
+
+		errs := cfg.validateAndLog()
+
+		assert.Equal(t, tc.expectedErrors, errs, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i, msg := range tc.expectedLogInfo {
+				assert.Equal(t, msg, hook.Entries[i].Message, tc.description)
+				assert.Equal(t, logrus.InfoLevel, hook.Entries[i].Level, tc.description)
+			}
+		}
+
+		hook.Reset()
+	}
+}
+
+func TestRequestLimitsValidateAndLog(t *testing.T) {
+
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	type logComponents struct {
+		msg string
+		lvl logrus.Level
+	}
+
+	testCases := []struct {
+		description        string
+		inRequestLimitsCfg *RequestLimits
+		expectedLogInfo    []logComponents
+		expectedErrors     []error
+	}{
+		{
+			description:        "Blank RequestLimits",
+			inRequestLimitsCfg: &RequestLimits{MaxBodySize: 1024, MaxDecompressedBodySize: 2048},
+			expectedLogInfo: []logComponents{
+				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_ttl_seconds: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_size_bytes: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_num_values: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_body_size_bytes: 1024`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_decompressed_body_size_bytes: 2048`, lvl: logrus.InfoLevel},
+			},
+		},
+		{
+			description:        "allow_setting_keys flag set to true",
+			inRequestLimitsCfg: &RequestLimits{AllowSettingKeys: true, MaxBodySize: 1024, MaxDecompressedBodySize: 2048},
+			expectedLogInfo: []logComponents{
+				{msg: `config.request_limits.allow_setting_keys: true`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_ttl_seconds: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_size_bytes: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_num_values: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_body_size_bytes: 1024`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_decompressed_body_size_bytes: 2048`, lvl: logrus.InfoLevel},
+			},
+		},
+		{
+			description:        "Negative max_ttl_seconds, expect error and keep validating",
+			inRequestLimitsCfg: &RequestLimits{MaxTTLSeconds: -1, MaxBodySize: 1024, MaxDecompressedBodySize: 2048},
+			expectedLogInfo: []logComponents{
+				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_size_bytes: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_num_values: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_body_size_bytes: 1024`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_decompressed_body_size_bytes: 2048`, lvl: logrus.InfoLevel},
+			},
+			expectedErrors: []error{fmt.Errorf("invalid config.request_limits.max_ttl_seconds: -1. Value cannot be negative.")},
+		},
+		{
+			description:        "Negative max_size_bytes, expect error and keep validating",
+			inRequestLimitsCfg: &RequestLimits{MaxSize: -1, MaxBodySize: 1024, MaxDecompressedBodySize: 2048},
+			expectedLogInfo: []logComponents{
+				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_ttl_seconds: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_num_values: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_body_size_bytes: 1024`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_decompressed_body_size_bytes: 2048`, lvl: logrus.InfoLevel},
+			},
+			expectedErrors: []error{fmt.Errorf("invalid config.request_limits.max_size_bytes: -1. Value cannot be negative.")},
+		},
+		{
+			description:        "Negative max_num_values, expect error",
+			inRequestLimitsCfg: &RequestLimits{MaxNumValues: -1, MaxBodySize: 1024, MaxDecompressedBodySize: 2048},
+			expectedLogInfo: []logComponents{
+				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_ttl_seconds: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_size_bytes: 0`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_body_size_bytes: 1024`, lvl: logrus.InfoLevel},
+				{msg: `config.request_limits.max_decompressed_body_size_bytes: 2048`, lvl: logrus.InfoLevel},
+			},
+			expectedErrors: []error{fmt.Errorf("invalid config.request_limits.max_num_values: -1. Value cannot be negative.")},
+		},
+		{
+			description:        "Every negative limit is reported",
+			inRequestLimitsCfg: &RequestLimits{MaxTTLSeconds: -1, MaxSize: -2, MaxNumValues: -3, MaxBodySize: -4, MaxDecompressedBodySize: -5},
+			expectedLogInfo: []logComponents{
+				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
+			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.request_limits.max_ttl_seconds: -1. Value cannot be negative."),
+				fmt.Errorf("invalid config.request_limits.max_size_bytes: -2. Value cannot be negative."),
+				fmt.Errorf("invalid config.request_limits.max_num_values: -3. Value cannot be negative."),
+				fmt.Errorf("invalid config.request_limits.max_body_size_bytes: -4. Value must be positive."),
+				fmt.Errorf("invalid config.request_limits.max_decompressed_body_size_bytes: -5. Value must be positive."),
+			},
+		},
+		{
+			description:        "Zero max_body_size_bytes and max_decompressed_body_size_bytes, expect errors",
+			inRequestLimitsCfg: &RequestLimits{},
 			expectedLogInfo: []logComponents{
 				{msg: `config.request_limits.allow_setting_keys: false`, lvl: logrus.InfoLevel},
 				{msg: `config.request_limits.max_ttl_seconds: 0`, lvl: logrus.InfoLevel},
 				{msg: `config.request_limits.max_size_bytes: 0`, lvl: logrus.InfoLevel},
-				{msg: `invalid config.request_limits.max_num_values: -1. Value cannot be negative.`, lvl: logrus.FatalLevel},
+				{msg: `config.request_limits.max_num_values: 0`, lvl: logrus.InfoLevel},
+			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.request_limits.max_body_size_bytes: 0. Value must be positive."),
+				fmt.Errorf("invalid config.request_limits.max_decompressed_body_size_bytes: 0. Value must be positive."),
 			},
-			expectFatal: true,
 		},
 	}
 
-	//substitute logger exit function so execution doesn't get interrupted
-	defer func() { logrus.StandardLogger().ExitFunc = nil }()
-	var fatal bool
-	logrus.StandardLogger().ExitFunc = func(int) { fatal = true }
-
 	for _, tc := range testCases {
-		// Reset the fatal flag to false every test
-		fatal = false
-
 		// Run test
-		tc.inRequestLimitsCfg.validateAndLog()
+		err := tc.inRequestLimitsCfg.validateAndLog()
 
 		// Assert logrus expected entries
-		logEntryCount := 0
-		for i := 0; i < len(tc.expectedLogInfo); i++ {
-			assert.Equal(t, tc.expectedLogInfo[i].msg, hook.Entries[i].Message, tc.description+":message")
-			assert.Equal(t, tc.expectedLogInfo[i].lvl, hook.Entries[i].Level, tc.description+":log level")
-
-			logEntryCount++
-			if tc.expectedLogInfo[i].lvl == logrus.FatalLevel {
-				break
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i].msg, hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, tc.expectedLogInfo[i].lvl, hook.Entries[i].Level, tc.description+":log level")
 			}
 		}
-		if tc.expectedLogInfo[logEntryCount-1].lvl == logrus.FatalLevel && !fatal {
-			t.Errorf("Log level fatal was expected. %s", tc.description)
-		}
-		assert.Len(t, tc.expectedLogInfo, logEntryCount, tc.description)
 
 		//Reset log after every test and assert successful reset
 		hook.Reset()
@@ -917,15 +1319,15 @@ func TestCompressionValidateAndLog(t *testing.T) {
 		description      string
 		inCompressionCfg *Compression
 		inBackendType    BackendType
+		expectedErrors   []error
 		expectedLogInfo  []logComponents
 	}{
 		{
-			description:      "Blank compression type, expect fatal level log entry",
+			description:      "Blank compression type, expect error",
 			inCompressionCfg: &Compression{Type: CompressionType("")},
 			inBackendType:    BackendMemory,
-			expectedLogInfo: []logComponents{
-				{msg: `invalid config.compression.type: . It must be "none" or "snappy"`, lvl: logrus.FatalLevel},
-			},
+			expectedErrors:   []error{fmt.Errorf(`invalid config.compression.type: . It must be "none" or "snappy"`)},
+			expectedLogInfo:  []logComponents{},
 		},
 		{
 			description:      "Valid compression type 'none', expect info level log entry",
@@ -944,24 +1346,20 @@ func TestCompressionValidateAndLog(t *testing.T) {
 			},
 		},
 		{
-			description:      "Unsupported compression, expect fatal level log entry",
+			description:      "Unsupported compression, expect error",
 			inCompressionCfg: &Compression{Type: CompressionType("UnknownCompressionType")},
 			inBackendType:    BackendMemory,
-			expectedLogInfo: []logComponents{
-				{msg: `invalid config.compression.type: UnknownCompressionType. It must be "none" or "snappy"`, lvl: logrus.FatalLevel},
-			},
+			expectedErrors:   []error{fmt.Errorf(`invalid config.compression.type: UnknownCompressionType. It must be "none" or "snappy"`)},
+			expectedLogInfo:  []logComponents{},
 		},
 	}
 
-	//substitute logger exit function so execution doesn't get interrupted
-	defer func() { logrus.StandardLogger().ExitFunc = nil }()
-	logrus.StandardLogger().ExitFunc = func(int) {}
-
 	for _, tc := range testCases {
 		// Run test
-		tc.inCompressionCfg.validateAndLog()
+		err := tc.inCompressionCfg.validateAndLog()
 
 		// Assert logrus expected entries
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
 		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
 			for i := 0; i < len(tc.expectedLogInfo); i++ {
 				assert.Equal(t, tc.expectedLogInfo[i].msg, hook.Entries[i].Message, tc.description+":message")
@@ -1020,7 +1418,7 @@ func TestNewConfigFromFile(t *testing.T) {
 					lvl: logrus.FatalLevel,
 				},
 			},
-			expectedConfig: getExpectedDefaultConfig(),
+			expectedConfig: Configuration{},
 		},
 		{
 			description:      "Valid yaml configuration populates all configuration fields properly",
@@ -1053,6 +1451,49 @@ func TestNewConfigFromFile(t *testing.T) {
 	}
 }
 
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("configtest", "sample_full_config"))
+	assert.NoError(t, err, "Valid file")
+	assert.Equal(t, getExpectedFullConfigForTestFile(), cfg, "Valid file")
+
+	_, err = LoadConfig(filepath.Join("configtest", "config_invalid"))
+	if assert.Error(t, err, "Invalid file") {
+		assert.True(t, strings.HasPrefix(err.Error(), "Configuration file could not be read:"), "Invalid file")
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	cfg, err := LoadConfigFile(filepath.Join("configtest", "sample_full_config.yaml"))
+	assert.NoError(t, err, "Valid file")
+	assert.Equal(t, getExpectedFullConfigForTestFile(), cfg, "Valid file")
+
+	_, err = LoadConfigFile(filepath.Join("configtest", "missing_config.yaml"))
+	if assert.Error(t, err, "Missing file") {
+		assert.True(t, strings.HasPrefix(err.Error(), "Configuration file could not be read:"), "Missing file")
+	}
+}
+
+func TestConfigurationValidateAndLogReturnsEveryError(t *testing.T) {
+	cfg := getExpectedDefaultConfig()
+	cfg.Log.Level = LogLevel("verbose")
+	cfg.RequestLimits.MaxTTLSeconds = -1
+	cfg.Backend = Backend{Type: BackendAerospike}
+	cfg.Compression.Type = CompressionType("zip")
+
+	err := cfg.ValidateAndLog()
+
+	expectedErrors := ValidationErrors{
+		fmt.Errorf("invalid config.log.level: verbose"),
+		fmt.Errorf("invalid config.request_limits.max_ttl_seconds: -1. Value cannot be negative."),
+		fmt.Errorf("Cannot connect to empty Aerospike host(s)"),
+		fmt.Errorf("Cannot connect to Aerospike host at port 0"),
+		fmt.Errorf(`invalid config.compression.type: zip. It must be "none" or "snappy"`),
+	}
+	assert.Equal(t, expectedErrors, err)
+	assert.EqualError(t, err, `invalid config.log.level: verbose; invalid config.request_limits.max_ttl_seconds: -1. Value cannot be negative.; `+
+		`Cannot connect to empty Aerospike host(s); Cannot connect to Aerospike host at port 0; invalid config.compression.type: zip. It must be "none" or "snappy"`)
+}
+
 func TestConfigurationValidateAndLog(t *testing.T) {
 	// logrus entries will be recorded to this `hook` object so we can compare and assert them
 	hook := testLogrus.NewGlobal()
@@ -1072,19 +1513,66 @@ func TestConfigurationValidateAndLog(t *testing.T) {
 		{msg: fmt.Sprintf("config.port: %d", expectedConfig.Port), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.admin_port: %d", expectedConfig.AdminPort), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.log.level: %s", expectedConfig.Log.Level), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.log.access_log.enabled: %t", expectedConfig.Log.AccessLog.Enabled), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.rate_limiter.enabled: %t", expectedConfig.RateLimiting.Enabled), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.rate_limiter.num_requests: %d", expectedConfig.RateLimiting.MaxRequestsPerSecond), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.request_limits.allow_setting_keys: %v", expectedConfig.RequestLimits.AllowSettingKeys), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.request_limits.max_ttl_seconds: %d", expectedConfig.RequestLimits.MaxTTLSeconds), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.request_limits.max_size_bytes: %d", expectedConfig.RequestLimits.MaxSize), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.request_limits.max_num_values: %d", expectedConfig.RequestLimits.MaxNumValues), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.request_limits.max_body_size_bytes: %d", expectedConfig.RequestLimits.MaxBodySize), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.request_limits.max_decompressed_body_size_bytes: %d", expectedConfig.RequestLimits.MaxDecompressedBodySize), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.keys.dedup: %t", expectedConfig.Keys.Dedup), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.keys.format: %s", expectedConfig.Keys.Format), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.keys.prefix: %s", expectedConfig.Keys.Prefix), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.keys.collision_retries: %d", expectedConfig.Keys.CollisionRetries), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.backend.type: %s", expectedConfig.Backend.Type), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.backend.store_metadata: %t", expectedConfig.Backend.StoreMetadata), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("config.compression.type: %s", expectedConfig.Compression.Type), lvl: logrus.InfoLevel},
 		{msg: fmt.Sprintf("Prebid Cache will run without metrics"), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.error_format: %s", expectedConfig.Routes.ErrorFormat), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.response_encodings: %v", expectedConfig.Routes.ResponseEncodings), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.get.allowed_origins: %v", expectedConfig.Routes.CORS.Get.AllowedOrigins), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.get.allowed_methods: %v", expectedConfig.Routes.CORS.Get.AllowedMethods), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.get.allowed_headers: %v", expectedConfig.Routes.CORS.Get.AllowedHeaders), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.get.max_age_seconds: %d", expectedConfig.Routes.CORS.Get.MaxAgeSeconds), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.get.allow_credentials: %t", expectedConfig.Routes.CORS.Get.AllowCredentials), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.post.allowed_origins: %v", expectedConfig.Routes.CORS.Post.AllowedOrigins), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.post.allowed_methods: %v", expectedConfig.Routes.CORS.Post.AllowedMethods), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.post.allowed_headers: %v", expectedConfig.Routes.CORS.Post.AllowedHeaders), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.post.max_age_seconds: %d", expectedConfig.Routes.CORS.Post.MaxAgeSeconds), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.routes.cors.post.allow_credentials: %t", expectedConfig.Routes.CORS.Post.AllowCredentials), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.peers.allowed_hosts: %v", expectedConfig.Peers.AllowedHosts), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.peers.scheme: %s", expectedConfig.Peers.Scheme), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.peers.timeout_ms: %d", expectedConfig.Peers.TimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.peers.max_response_size_bytes: %d", expectedConfig.Peers.MaxResponseSizeBytes), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.client_ip.trusted_proxies: %v", expectedConfig.ClientIP.TrustedProxies), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.shutdown.pre_stop_delay_ms: %d", expectedConfig.Shutdown.PreStopDelayMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.shutdown.drain_timeout_ms: %d", expectedConfig.Shutdown.DrainTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.shutdown.close_timeout_ms: %d", expectedConfig.Shutdown.CloseTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.read_header_timeout_ms: %d", expectedConfig.Server.Main.ReadHeaderTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.read_timeout_ms: %d", expectedConfig.Server.Main.ReadTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.write_timeout_ms: %d", expectedConfig.Server.Main.WriteTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.idle_timeout_ms: %d", expectedConfig.Server.Main.IdleTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.max_header_bytes: %d", expectedConfig.Server.Main.MaxHeaderBytes), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.keep_alive_period_seconds: %d", expectedConfig.Server.Main.KeepAlivePeriodSeconds), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.max_connections: %d", expectedConfig.Server.Main.MaxConnections), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.h2c: %t", expectedConfig.Server.Main.H2C), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.main.tls.enabled: %t", expectedConfig.Server.Main.TLS.Enabled), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.read_header_timeout_ms: %d", expectedConfig.Server.Admin.ReadHeaderTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.read_timeout_ms: %d", expectedConfig.Server.Admin.ReadTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.write_timeout_ms: %d", expectedConfig.Server.Admin.WriteTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.idle_timeout_ms: %d", expectedConfig.Server.Admin.IdleTimeoutMillis), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.max_header_bytes: %d", expectedConfig.Server.Admin.MaxHeaderBytes), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.keep_alive_period_seconds: %d", expectedConfig.Server.Admin.KeepAlivePeriodSeconds), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.max_connections: %d", expectedConfig.Server.Admin.MaxConnections), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.h2c: %t", expectedConfig.Server.Admin.H2C), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.server.admin.tls.enabled: %t", expectedConfig.Server.Admin.TLS.Enabled), lvl: logrus.InfoLevel},
+		{msg: fmt.Sprintf("config.tracing.enabled: %t", expectedConfig.Tracing.Enabled), lvl: logrus.InfoLevel},
 	}
 
 	// Run test
-	expectedConfig.ValidateAndLog()
+	assert.NoError(t, expectedConfig.ValidateAndLog())
 
 	// Assertions
 	if assert.Len(t, hook.Entries, len(expectedLogInfo)) {
@@ -1109,7 +1597,7 @@ func TestPrometheusTimeoutDuration(t *testing.T) {
 	assert.Equal(t, expectedTimeout, actualTimeout)
 }
 
-func TestRoutesValidateAndLog(t *testing.T) {
+func TestKeysValidateAndLog(t *testing.T) {
 	// logrus entries will be recorded to this `hook` object so we can compare and assert them
 	hook := testLogrus.NewGlobal()
 
@@ -1118,22 +1606,254 @@ func TestRoutesValidateAndLog(t *testing.T) {
 		lvl logrus.Level
 	}
 
+	dedupLog := logComponents{msg: "config.keys.dedup: false", lvl: logrus.InfoLevel}
+	formatLog := logComponents{msg: "config.keys.format: uuidv4", lvl: logrus.InfoLevel}
+	prefixLog := logComponents{msg: "config.keys.prefix: ", lvl: logrus.InfoLevel}
+	retriesLog := logComponents{msg: "config.keys.collision_retries: 0", lvl: logrus.InfoLevel}
+
 	testCases := []struct {
 		description     string
-		inRoutesConfig  *Routes
+		inKeysConfig    *Keys
+		expectedErrors  []error
 		expectedLogInfo []logComponents
 	}{
 		{
-			description:    "Public write is not allowed, log info level message",
-			inRoutesConfig: &Routes{AllowPublicWrite: false},
+			description:     "Default keys",
+			inKeysConfig:    &Keys{Format: IDFormatUUIDv4},
+			expectedLogInfo: []logComponents{dedupLog, formatLog, prefixLog, retriesLog},
+		},
+		{
+			description:  "Deduplicated ULIDs with a prefix",
+			inKeysConfig: &Keys{Dedup: true, Format: IDFormatULID, Prefix: "use1.shard_2~-", CollisionRetries: 3},
 			expectedLogInfo: []logComponents{
-				{msg: "Main server will only accept GET requests", lvl: logrus.InfoLevel},
+				{msg: "config.keys.dedup: true", lvl: logrus.InfoLevel},
+				{msg: "config.keys.format: ulid", lvl: logrus.InfoLevel},
+				{msg: "config.keys.prefix: use1.shard_2~-", lvl: logrus.InfoLevel},
+				{msg: "config.keys.collision_retries: 3", lvl: logrus.InfoLevel},
 			},
 		},
 		{
-			description:     "Public write allowed. Default GET and POST methods are allowed, no need to log anything",
-			inRoutesConfig:  &Routes{AllowPublicWrite: true},
-			expectedLogInfo: []logComponents{},
+			description:     "Unknown format",
+			inKeysConfig:    &Keys{Format: IDFormat("uuidv1")},
+			expectedErrors:  []error{fmt.Errorf(`invalid config.keys.format: uuidv1. Value must be "uuidv4", "uuidv7" or "ulid".`)},
+			expectedLogInfo: []logComponents{dedupLog, prefixLog, retriesLog},
+		},
+		{
+			description:     "Prefix with characters that get escaped in query strings",
+			inKeysConfig:    &Keys{Format: IDFormatUUIDv4, Prefix: "us east/1"},
+			expectedErrors:  []error{fmt.Errorf(`invalid config.keys.prefix: us east/1. Value must only contain letters, digits, "-", ".", "_" and "~".`)},
+			expectedLogInfo: []logComponents{dedupLog, formatLog, retriesLog},
+		},
+		{
+			description:     "Negative collision retries",
+			inKeysConfig:    &Keys{Format: IDFormatUUIDv4, CollisionRetries: -1},
+			expectedErrors:  []error{fmt.Errorf(`invalid config.keys.collision_retries: -1. Value cannot be negative.`)},
+			expectedLogInfo: []logComponents{dedupLog, formatLog, prefixLog},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		err := tc.inKeysConfig.validateAndLog()
+
+		// Assert logrus expected entries
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i].msg, hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, tc.expectedLogInfo[i].lvl, hook.Entries[i].Level, tc.description+":log level")
+			}
+		}
+
+		//Reset log after every test and assert successful reset
+		hook.Reset()
+		assert.Nil(t, hook.LastEntry())
+	}
+}
+
+func TestDedupValidate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		inKeys         Keys
+		inBackend      Backend
+		expectedErrors []error
+	}{
+		{
+			description: "Dedup disabled",
+			inBackend:   Backend{Type: BackendMemcache},
+		},
+		{
+			description: "Backend reporting when values expire",
+			inKeys:      Keys{Dedup: true},
+			inBackend:   Backend{Type: BackendRedis},
+		},
+		{
+			description: "Backend that never expires values",
+			inKeys:      Keys{Dedup: true},
+			inBackend:   Backend{Type: BackendMemory},
+		},
+		{
+			description: "Backend not reporting when values expire, with metadata",
+			inKeys:      Keys{Dedup: true},
+			inBackend:   Backend{Type: BackendCassandra, StoreMetadata: true},
+		},
+		{
+			description:    "Backend not reporting when values expire",
+			inKeys:         Keys{Dedup: true},
+			inBackend:      Backend{Type: BackendMemcache},
+			expectedErrors: []error{fmt.Errorf("invalid config.keys.dedup: true. The memcache backend doesn't report when values expire, it requires config.backend.store_metadata.")},
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := Configuration{Keys: tc.inKeys, Backend: tc.inBackend}
+		assert.Equal(t, tc.expectedErrors, cfg.validateDedup(), tc.description)
+	}
+}
+
+func TestPeersValidateAndLog(t *testing.T) {
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	type logComponents struct {
+		msg string
+		lvl logrus.Level
+	}
+
+	schemeLog := logComponents{msg: "config.peers.scheme: https", lvl: logrus.InfoLevel}
+	timeoutLog := logComponents{msg: "config.peers.timeout_ms: 200", lvl: logrus.InfoLevel}
+	sizeLog := logComponents{msg: "config.peers.max_response_size_bytes: 1024", lvl: logrus.InfoLevel}
+
+	testCases := []struct {
+		description     string
+		inPeersConfig   *Peers
+		expectedErrors  []error
+		expectedLogInfo []logComponents
+	}{
+		{
+			description:   "Hosts with and without ports",
+			inPeersConfig: &Peers{AllowedHosts: []string{"cache.prebid.org", "10.0.0.1:8000", "[::1]:8000"}, Scheme: "https", TimeoutMillis: 200, MaxResponseSizeBytes: 1024},
+			expectedLogInfo: []logComponents{
+				{msg: "config.peers.allowed_hosts: [cache.prebid.org 10.0.0.1:8000 [::1]:8000]", lvl: logrus.InfoLevel},
+				schemeLog, timeoutLog, sizeLog,
+			},
+		},
+		{
+			description:   "Hosts with a scheme, a path or credentials",
+			inPeersConfig: &Peers{AllowedHosts: []string{"https://cache.prebid.org", "cache.prebid.org/cache", "user@cache.prebid.org", ""}, Scheme: "https", TimeoutMillis: 200, MaxResponseSizeBytes: 1024},
+			expectedErrors: []error{
+				fmt.Errorf(`invalid config.peers.allowed_hosts: "https://cache.prebid.org". Values must be a host with an optional port, without a scheme or path.`),
+				fmt.Errorf(`invalid config.peers.allowed_hosts: "cache.prebid.org/cache". Values must be a host with an optional port, without a scheme or path.`),
+				fmt.Errorf(`invalid config.peers.allowed_hosts: "user@cache.prebid.org". Values must be a host with an optional port, without a scheme or path.`),
+				fmt.Errorf(`invalid config.peers.allowed_hosts: "". Values must be a host with an optional port, without a scheme or path.`),
+			},
+			expectedLogInfo: []logComponents{schemeLog, timeoutLog, sizeLog},
+		},
+		{
+			description:   "Unknown scheme and non-positive limits",
+			inPeersConfig: &Peers{AllowedHosts: []string{}, Scheme: "ftp", TimeoutMillis: 0, MaxResponseSizeBytes: -1},
+			expectedErrors: []error{
+				fmt.Errorf(`invalid config.peers.scheme: ftp. Value must be "http" or "https".`),
+				fmt.Errorf(`invalid config.peers.timeout_ms: 0. Value must be positive.`),
+				fmt.Errorf(`invalid config.peers.max_response_size_bytes: -1. Value must be positive.`),
+			},
+			expectedLogInfo: []logComponents{{msg: "config.peers.allowed_hosts: []", lvl: logrus.InfoLevel}},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		err := tc.inPeersConfig.validateAndLog()
+
+		// Assert logrus expected entries
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i].msg, hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, tc.expectedLogInfo[i].lvl, hook.Entries[i].Level, tc.description+":log level")
+			}
+		}
+
+		//Reset log after every test and assert successful reset
+		hook.Reset()
+		assert.Nil(t, hook.LastEntry())
+	}
+}
+
+func TestRoutesValidateAndLog(t *testing.T) {
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	type logComponents struct {
+		msg string
+		lvl logrus.Level
+	}
+
+	corsPolicy := CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}
+	getPolicyLogs := []logComponents{
+		{msg: "config.routes.cors.get.allowed_origins: [*]", lvl: logrus.InfoLevel},
+		{msg: "config.routes.cors.get.allowed_methods: [GET]", lvl: logrus.InfoLevel},
+		{msg: "config.routes.cors.get.allowed_headers: []", lvl: logrus.InfoLevel},
+		{msg: "config.routes.cors.get.max_age_seconds: 0", lvl: logrus.InfoLevel},
+		{msg: "config.routes.cors.get.allow_credentials: false", lvl: logrus.InfoLevel},
+	}
+	postPolicyLogs := []logComponents{
+		{msg: "config.routes.cors.post.allowed_origins: [*]", lvl: logrus.InfoLevel},
+		{msg: "config.routes.cors.post.allowed_methods: [GET]", lvl: logrus.InfoLevel},
+		{msg: "config.routes.cors.post.allowed_headers: []", lvl: logrus.InfoLevel},
+		{msg: "config.routes.cors.post.max_age_seconds: 0", lvl: logrus.InfoLevel},
+		{msg: "config.routes.cors.post.allow_credentials: false", lvl: logrus.InfoLevel},
+	}
+
+	errorFormatLog := logComponents{msg: "config.routes.error_format: text", lvl: logrus.InfoLevel}
+	encodingsLog := logComponents{msg: "config.routes.response_encodings: [gzip]", lvl: logrus.InfoLevel}
+
+	testCases := []struct {
+		description     string
+		inRoutesConfig  *Routes
+		expectedErrors  []error
+		expectedLogInfo []logComponents
+	}{
+		{
+			description:     "Public write is not allowed, log info level message and skip the POST CORS policy",
+			inRoutesConfig:  &Routes{AllowPublicWrite: false, ErrorFormat: ErrorFormatText, ResponseEncodings: []string{"gzip"}, CORS: CORS{Get: corsPolicy, Post: corsPolicy}},
+			expectedLogInfo: append([]logComponents{{msg: "Main server will only accept GET requests", lvl: logrus.InfoLevel}, errorFormatLog, encodingsLog}, getPolicyLogs...),
+		},
+		{
+			description:     "Public write allowed. Default GET and POST methods are allowed, only log CORS policies",
+			inRoutesConfig:  &Routes{AllowPublicWrite: true, ErrorFormat: ErrorFormatText, ResponseEncodings: []string{"gzip"}, CORS: CORS{Get: corsPolicy, Post: corsPolicy}},
+			expectedLogInfo: append(append([]logComponents{errorFormatLog, encodingsLog}, getPolicyLogs...), postPolicyLogs...),
+		},
+		{
+			description:     "Invalid POST CORS policy",
+			inRoutesConfig:  &Routes{AllowPublicWrite: true, ErrorFormat: ErrorFormatText, ResponseEncodings: []string{"gzip"}, CORS: CORS{Get: corsPolicy, Post: CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}}},
+			expectedErrors:  []error{fmt.Errorf(`invalid config.routes.cors.post.allowed_origins: "*" cannot be used when config.routes.cors.post.allow_credentials is true. List the allowed origins explicitly`)},
+			expectedLogInfo: append([]logComponents{errorFormatLog, encodingsLog}, getPolicyLogs...),
+		},
+		{
+			description:     "JSON error responses",
+			inRoutesConfig:  &Routes{AllowPublicWrite: false, ErrorFormat: ErrorFormatJSON, ResponseEncodings: []string{"gzip"}, CORS: CORS{Get: corsPolicy, Post: corsPolicy}},
+			expectedLogInfo: append([]logComponents{{msg: "Main server will only accept GET requests", lvl: logrus.InfoLevel}, {msg: "config.routes.error_format: json", lvl: logrus.InfoLevel}, encodingsLog}, getPolicyLogs...),
+		},
+		{
+			description:     "Unknown error format",
+			inRoutesConfig:  &Routes{AllowPublicWrite: false, ErrorFormat: ErrorFormat("xml"), ResponseEncodings: []string{"gzip"}, CORS: CORS{Get: corsPolicy, Post: corsPolicy}},
+			expectedErrors:  []error{fmt.Errorf(`invalid config.routes.error_format: xml. Value must be "text" or "json".`)},
+			expectedLogInfo: append([]logComponents{{msg: "Main server will only accept GET requests", lvl: logrus.InfoLevel}, encodingsLog}, getPolicyLogs...),
+		},
+		{
+			description:     "No response encodings",
+			inRoutesConfig:  &Routes{AllowPublicWrite: false, ErrorFormat: ErrorFormatText, ResponseEncodings: []string{}, CORS: CORS{Get: corsPolicy, Post: corsPolicy}},
+			expectedLogInfo: append([]logComponents{{msg: "Main server will only accept GET requests", lvl: logrus.InfoLevel}, errorFormatLog, {msg: "config.routes.response_encodings: []", lvl: logrus.InfoLevel}}, getPolicyLogs...),
+		},
+		{
+			description:    "Unknown and repeated response encodings",
+			inRoutesConfig: &Routes{AllowPublicWrite: false, ErrorFormat: ErrorFormatText, ResponseEncodings: []string{"gzip", "br", "gzip"}, CORS: CORS{Get: corsPolicy, Post: corsPolicy}},
+			expectedErrors: []error{
+				fmt.Errorf(`invalid config.routes.response_encodings: br. Values must be "gzip".`),
+				fmt.Errorf(`invalid config.routes.response_encodings: gzip is listed more than once.`),
+			},
+			expectedLogInfo: append([]logComponents{{msg: "Main server will only accept GET requests", lvl: logrus.InfoLevel}, errorFormatLog}, getPolicyLogs...),
 		},
 	}
 
@@ -1143,9 +1863,179 @@ func TestRoutesValidateAndLog(t *testing.T) {
 
 	for _, tc := range testCases {
 		// Run test
-		tc.inRoutesConfig.validateAndLog()
+		err := tc.inRoutesConfig.validateAndLog()
 
 		// Assert logrus expected entries
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i].msg, hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, tc.expectedLogInfo[i].lvl, hook.Entries[i].Level, tc.description+":log level")
+			}
+		}
+
+		//Reset log after every test and assert successful reset
+		hook.Reset()
+		assert.Nil(t, hook.LastEntry())
+	}
+}
+
+func TestCORSPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		inPolicy       CORSPolicy
+		expectedErrors []string
+	}{
+		{
+			description: "Any origin without credentials",
+			inPolicy:    CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "HEAD"}, AllowedHeaders: []string{"*"}},
+		},
+		{
+			description: "Explicit origins, subdomain wildcards and ports with credentials",
+			inPolicy: CORSPolicy{
+				AllowedOrigins:   []string{"https://prebid.org", "https://*.prebid.org", "http://localhost:8080", "http://[::1]:8080"},
+				AllowedHeaders:   []string{"Content-Type", "X-Request-ID"},
+				MaxAgeSeconds:    600,
+				AllowCredentials: true,
+			},
+		},
+		{
+			description: "No origins allowed",
+			inPolicy:    CORSPolicy{AllowedOrigins: []string{}},
+		},
+		{
+			description:    "Any origin with credentials",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"https://prebid.org", "*"}, AllowCredentials: true},
+			expectedErrors: []string{`invalid config.routes.cors.get.allowed_origins: "*" cannot be used when config.routes.cors.get.allow_credentials is true. List the allowed origins explicitly`},
+		},
+		{
+			description:    "Origin without scheme",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"prebid.org"}},
+			expectedErrors: []string{`invalid config.routes.cors.get.allowed_origins: "prebid.org" must be "*" or look like scheme://host[:port]`},
+		},
+		{
+			description:    "Wildcard in the middle of the host",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"https://*prebid.org"}},
+			expectedErrors: []string{`invalid config.routes.cors.get.allowed_origins: "https://*prebid.org" can only use a wildcard as the first label of the host, as in "https://*.example.com"`},
+		},
+		{
+			description:    "Wildcard in the scheme",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"*://prebid.org"}},
+			expectedErrors: []string{`invalid config.routes.cors.get.allowed_origins: "*://prebid.org" has an invalid scheme`},
+		},
+		{
+			description:    "Origin with a path",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"https://prebid.org/cache"}},
+			expectedErrors: []string{`invalid config.routes.cors.get.allowed_origins: "https://prebid.org/cache" must be a scheme://host[:port] origin without path, query or user info`},
+		},
+		{
+			description:    "Origin with a malformed port",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"https://prebid.org:80a"}},
+			expectedErrors: []string{`invalid config.routes.cors.get.allowed_origins: "https://prebid.org:80a" has an invalid port`},
+		},
+		{
+			description:    "Malformed method",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET POST"}},
+			expectedErrors: []string{`invalid config.routes.cors.get.allowed_methods: "GET POST" is not a valid HTTP method`},
+		},
+		{
+			description:    "Malformed header",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"Content-Type:"}},
+			expectedErrors: []string{`invalid config.routes.cors.get.allowed_headers: "Content-Type:" is not a valid HTTP header name`},
+		},
+		{
+			description:    "Negative max age",
+			inPolicy:       CORSPolicy{AllowedOrigins: []string{"*"}, MaxAgeSeconds: -1},
+			expectedErrors: []string{"invalid config.routes.cors.get.max_age_seconds: -1. Value cannot be negative."},
+		},
+		{
+			description: "Every problem is reported",
+			inPolicy:    CORSPolicy{AllowedOrigins: []string{"prebid.org"}, AllowedMethods: []string{"GET POST"}, MaxAgeSeconds: -1},
+			expectedErrors: []string{
+				`invalid config.routes.cors.get.allowed_origins: "prebid.org" must be "*" or look like scheme://host[:port]`,
+				`invalid config.routes.cors.get.allowed_methods: "GET POST" is not a valid HTTP method`,
+				"invalid config.routes.cors.get.max_age_seconds: -1. Value cannot be negative.",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		errs := tc.inPolicy.validateAndLog("config.routes.cors.get")
+		if assert.Len(t, errs, len(tc.expectedErrors), tc.description) {
+			for i, err := range errs {
+				assert.EqualError(t, err, tc.expectedErrors[i], tc.description)
+			}
+		}
+	}
+}
+
+func TestClientIPValidateAndLog(t *testing.T) {
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	type logComponents struct {
+		msg string
+		lvl logrus.Level
+	}
+
+	testCases := []struct {
+		description     string
+		inClientIPCfg   *ClientIP
+		expectedErrors  []error
+		expectedLogInfo []logComponents
+	}{
+		{
+			description:   "No trusted proxies, proxy protocol disabled",
+			inClientIPCfg: &ClientIP{TrustedProxies: []string{}},
+			expectedLogInfo: []logComponents{
+				{msg: "config.client_ip.trusted_proxies: []", lvl: logrus.InfoLevel},
+			},
+		},
+		{
+			description:   "Valid trusted proxies, proxy protocol enabled",
+			inClientIPCfg: &ClientIP{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}, ProxyProtocol: ProxyProtocol{Enabled: true, HeaderTimeoutMillis: 100}},
+			expectedLogInfo: []logComponents{
+				{msg: "config.client_ip.trusted_proxies: [10.0.0.0/8 192.168.1.1]", lvl: logrus.InfoLevel},
+				{msg: "config.client_ip.proxy_protocol.enabled: true", lvl: logrus.InfoLevel},
+				{msg: "config.client_ip.proxy_protocol.header_timeout_ms: 100", lvl: logrus.InfoLevel},
+			},
+		},
+		{
+			description:     "Malformed trusted proxy",
+			inClientIPCfg:   &ClientIP{TrustedProxies: []string{"10.0.0.0/33"}},
+			expectedErrors:  []error{fmt.Errorf(`invalid config.client_ip.trusted_proxies: "10.0.0.0/33" is not a valid CIDR block`)},
+			expectedLogInfo: []logComponents{},
+		},
+		{
+			description:     "Proxy protocol enabled without trusted proxies",
+			inClientIPCfg:   &ClientIP{TrustedProxies: []string{}, ProxyProtocol: ProxyProtocol{Enabled: true, HeaderTimeoutMillis: 100}},
+			expectedErrors:  []error{fmt.Errorf("config.client_ip.proxy_protocol.enabled requires at least one entry in config.client_ip.trusted_proxies")},
+			expectedLogInfo: []logComponents{{msg: "config.client_ip.trusted_proxies: []", lvl: logrus.InfoLevel}},
+		},
+		{
+			description:     "Proxy protocol enabled with a non-positive header timeout",
+			inClientIPCfg:   &ClientIP{TrustedProxies: []string{"10.0.0.0/8"}, ProxyProtocol: ProxyProtocol{Enabled: true}},
+			expectedErrors:  []error{fmt.Errorf("invalid config.client_ip.proxy_protocol.header_timeout_ms: 0. Value must be positive.")},
+			expectedLogInfo: []logComponents{{msg: "config.client_ip.trusted_proxies: [10.0.0.0/8]", lvl: logrus.InfoLevel}},
+		},
+		{
+			description:   "Every malformed trusted proxy and proxy protocol problem is reported",
+			inClientIPCfg: &ClientIP{TrustedProxies: []string{"10.0.0.0/33", "10.0.0.2", "not-an-ip"}, ProxyProtocol: ProxyProtocol{Enabled: true, HeaderTimeoutMillis: -1}},
+			expectedErrors: []error{
+				fmt.Errorf(`invalid config.client_ip.trusted_proxies: "10.0.0.0/33" is not a valid CIDR block`),
+				fmt.Errorf(`invalid config.client_ip.trusted_proxies: "not-an-ip" is not a valid IP address`),
+				fmt.Errorf("invalid config.client_ip.proxy_protocol.header_timeout_ms: -1. Value must be positive."),
+			},
+			expectedLogInfo: []logComponents{},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		err := tc.inClientIPCfg.validateAndLog()
+
+		// Assertions
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
 		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
 			for i := 0; i < len(tc.expectedLogInfo); i++ {
 				assert.Equal(t, tc.expectedLogInfo[i].msg, hook.Entries[i].Message, tc.description+":message")
@@ -1159,6 +2049,56 @@ func TestRoutesValidateAndLog(t *testing.T) {
 	}
 }
 
+func TestShutdownValidateAndLog(t *testing.T) {
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	testCases := []struct {
+		description     string
+		inShutdownCfg   *Shutdown
+		expectedErrors  []error
+		expectedLogInfo []string
+	}{
+		{
+			description:   "Valid shutdown settings",
+			inShutdownCfg: &Shutdown{PreStopDelayMillis: 15000, DrainTimeoutMillis: 20000, CloseTimeoutMillis: 3000},
+			expectedLogInfo: []string{
+				"config.shutdown.pre_stop_delay_ms: 15000",
+				"config.shutdown.drain_timeout_ms: 20000",
+				"config.shutdown.close_timeout_ms: 3000",
+			},
+		},
+		{
+			description:   "Every invalid shutdown setting is reported",
+			inShutdownCfg: &Shutdown{PreStopDelayMillis: -1, DrainTimeoutMillis: 0, CloseTimeoutMillis: -5},
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.shutdown.pre_stop_delay_ms: -1. Value cannot be negative."),
+				fmt.Errorf("invalid config.shutdown.drain_timeout_ms: 0. Value must be positive."),
+				fmt.Errorf("invalid config.shutdown.close_timeout_ms: -5. Value must be positive."),
+			},
+			expectedLogInfo: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		err := tc.inShutdownCfg.validateAndLog()
+
+		// Assertions
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i], hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, logrus.InfoLevel, hook.Entries[i].Level, tc.description+":log level")
+			}
+		}
+
+		//Reset log after every test and assert successful reset
+		hook.Reset()
+		assert.Nil(t, hook.LastEntry())
+	}
+}
+
 // setEnvVar sets an environment variable to a certain value, and returns a function which resets it to its original value.
 func setEnvVar(t *testing.T, key string, val string) func() {
 	orig, set := os.LookupEnv(key)
@@ -1208,17 +2148,116 @@ func getExpectedDefaultConfig() Configuration {
 		Compression: Compression{
 			Type: CompressionType("snappy"),
 		},
+		Metrics: Metrics{
+			Influx: InfluxMetrics{
+				Tags:                map[string]string{},
+				FlushIntervalMillis: utils.INFLUX_FLUSH_INTERVAL_MS,
+				BufferSize:          utils.INFLUX_BUFFER_SIZE,
+				MaxRetries:          utils.INFLUX_MAX_RETRIES,
+				RetryBackoffMillis:  utils.INFLUX_RETRY_BACKOFF_MS,
+			},
+			Prometheus: PrometheusMetrics{
+				ProcessCollector: true,
+				Buckets: PrometheusBuckets{
+					Duration: utils.PROMETHEUS_DURATION_BUCKETS,
+					Size:     utils.PROMETHEUS_SIZE_BUCKETS,
+					TTL:      utils.PROMETHEUS_TTL_BUCKETS,
+					Age:      utils.PROMETHEUS_AGE_BUCKETS,
+				},
+			},
+			StatsD: StatsDMetrics{
+				Host:                "127.0.0.1",
+				Port:                utils.STATSD_PORT,
+				Prefix:              "prebid_cache",
+				SampleRate:          1,
+				Tags:                []string{},
+				FlushIntervalMillis: utils.STATSD_FLUSH_INTERVAL_MS,
+				MaxPacketBytes:      utils.STATSD_MAX_PACKET_BYTES,
+			},
+		},
 		RateLimiting: RateLimiting{
 			Enabled:              true,
 			MaxRequestsPerSecond: 100,
 		},
 		RequestLimits: RequestLimits{
-			MaxSize:       10240,
-			MaxNumValues:  10,
-			MaxTTLSeconds: 3600,
+			MaxSize:                 10240,
+			MaxNumValues:            10,
+			MaxBodySize:             1048576,
+			MaxDecompressedBodySize: 1048576,
+			MaxTTLSeconds:           3600,
+		},
+		Keys: Keys{
+			Format:           IDFormatUUIDv4,
+			CollisionRetries: 3,
 		},
 		Routes: Routes{
-			AllowPublicWrite: true,
+			AllowPublicWrite:  true,
+			ErrorFormat:       ErrorFormatText,
+			ResponseEncodings: []string{},
+			CORS: CORS{
+				Get: CORSPolicy{
+					AllowedOrigins: []string{"*"},
+					AllowedMethods: []string{"GET", "HEAD"},
+					AllowedHeaders: []string{},
+				},
+				Post: CORSPolicy{
+					AllowedOrigins: []string{"*"},
+					AllowedMethods: []string{"POST"},
+					AllowedHeaders: []string{},
+				},
+			},
+		},
+		Peers: Peers{
+			AllowedHosts:         []string{},
+			Scheme:               "https",
+			TimeoutMillis:        utils.PEER_TIMEOUT_MS,
+			MaxResponseSizeBytes: utils.PEER_MAX_RESPONSE_SIZE_BYTES,
+		},
+		ClientIP: ClientIP{
+			TrustedProxies: []string{},
+			ProxyProtocol: ProxyProtocol{
+				HeaderTimeoutMillis: utils.PROXY_PROTOCOL_HEADER_TIMEOUT_MS,
+			},
+		},
+		Shutdown: Shutdown{
+			DrainTimeoutMillis: utils.SHUTDOWN_DRAIN_TIMEOUT_MS,
+			CloseTimeoutMillis: utils.SHUTDOWN_CLOSE_TIMEOUT_MS,
+		},
+		Server: Servers{
+			Main: Server{
+				ReadTimeoutMillis:      utils.MAIN_SERVER_TIMEOUT_MS,
+				WriteTimeoutMillis:     utils.MAIN_SERVER_TIMEOUT_MS,
+				KeepAlivePeriodSeconds: utils.TCP_KEEP_ALIVE_PERIOD_SECONDS,
+				UnixSocket:             UnixSocket{Mode: "0660"},
+				TLS: TLS{
+					MinVersion:            "1.2",
+					CipherSuites:          []string{},
+					ReloadIntervalSeconds: utils.TLS_RELOAD_INTERVAL_SECONDS,
+				},
+			},
+			Admin: Server{
+				KeepAlivePeriodSeconds: utils.TCP_KEEP_ALIVE_PERIOD_SECONDS,
+				UnixSocket:             UnixSocket{Mode: "0660"},
+				TLS: TLS{
+					MinVersion:            "1.2",
+					CipherSuites:          []string{},
+					ReloadIntervalSeconds: utils.TLS_RELOAD_INTERVAL_SECONDS,
+				},
+			},
+		},
+		Tracing: Tracing{
+			ServiceName:         "prebid-cache",
+			SampleRatio:         1,
+			QueueSize:           utils.TRACING_QUEUE_SIZE,
+			BatchSize:           utils.TRACING_BATCH_SIZE,
+			FlushIntervalMillis: utils.TRACING_FLUSH_INTERVAL_MS,
+			Exporter: TracingExporter{
+				Type: TracingExporterStdout,
+				OTLP: OTLPTraceExporter{
+					Endpoint:      "http://localhost:4318/v1/traces",
+					TimeoutMillis: utils.TRACING_OTLP_TIMEOUT_MS,
+				},
+			},
 		},
 	}
 }
@@ -1230,20 +2269,30 @@ func getExpectedFullConfigForTestFile() Configuration {
 		AdminPort:     2525,
 		IndexResponse: "Any index response",
 		Log: Log{
-			Level: Info,
+			Level:     Info,
+			AccessLog: AccessLog{Enabled: true},
 		},
 		RateLimiting: RateLimiting{
 			Enabled:              false,
 			MaxRequestsPerSecond: 150,
 		},
 		RequestLimits: RequestLimits{
-			MaxSize:          10240,
-			MaxNumValues:     10,
-			MaxTTLSeconds:    5000,
-			AllowSettingKeys: true,
+			MaxSize:                 10240,
+			MaxNumValues:            10,
+			MaxBodySize:             204800,
+			MaxDecompressedBodySize: 2097152,
+			MaxTTLSeconds:           5000,
+			AllowSettingKeys:        true,
+		},
+		Keys: Keys{
+			Dedup:            true,
+			Format:           IDFormatULID,
+			Prefix:           "use1-",
+			CollisionRetries: 5,
 		},
 		Backend: Backend{
-			Type: BackendMemory,
+			Type:          BackendMemory,
+			StoreMetadata: true,
 			Aerospike: Aerospike{
 				DefaultTTLSecs:      3600,
 				Host:                "aerospike.prebid.com",
@@ -1281,11 +2330,16 @@ func getExpectedFullConfigForTestFile() Configuration {
 		Metrics: Metrics{
 			Type: MetricsType("none"),
 			Influx: InfluxMetrics{
-				Host:     "metrics-host",
-				Database: "metrics-database",
-				Username: "metrics-username",
-				Password: "metrics-password",
-				Enabled:  true,
+				Host:                "metrics-host",
+				Database:            "metrics-database",
+				Username:            "metrics-username",
+				Password:            "metrics-password",
+				Enabled:             true,
+				Tags:                map[string]string{"host": "${HOSTNAME}", "region": "us-east"},
+				FlushIntervalMillis: 5000,
+				BufferSize:          20000,
+				MaxRetries:          5,
+				RetryBackoffMillis:  250,
 			},
 			Prometheus: PrometheusMetrics{
 				Port:             8080,
@@ -1293,10 +2347,120 @@ func getExpectedFullConfigForTestFile() Configuration {
 				Subsystem:        "cache",
 				TimeoutMillisRaw: 100,
 				Enabled:          true,
+				AdminEndpoint:    true,
+				ProcessCollector: true,
+				GoCollector:      true,
+				Buckets: PrometheusBuckets{
+					Duration: []float64{0.0005, 0.001, 0.002, 0.005, 0.05},
+					Size:     []float64{1024, 16384, 131072},
+					TTL:      []float64{60, 300, 3600},
+					Age:      []float64{1, 60, 3600},
+				},
+			},
+			StatsD: StatsDMetrics{
+				Enabled:             true,
+				Host:                "dogstatsd.example.com",
+				Port:                8126,
+				Prefix:              "pbc",
+				SampleRate:          0.5,
+				DogStatsD:           true,
+				Tags:                []string{"env:prod", "region:us-east"},
+				FlushIntervalMillis: 250,
+				MaxPacketBytes:      8192,
 			},
 		},
 		Routes: Routes{
-			AllowPublicWrite: true,
+			AllowPublicWrite:  true,
+			ErrorFormat:       ErrorFormatJSON,
+			ResponseEncodings: []string{"gzip"},
+			CORS: CORS{
+				Get: CORSPolicy{
+					AllowedOrigins: []string{"*"},
+					AllowedMethods: []string{"GET"},
+					AllowedHeaders: []string{},
+					MaxAgeSeconds:  600,
+				},
+				Post: CORSPolicy{
+					AllowedOrigins:   []string{"https://*.prebid.org", "https://prebid.example.com:8443"},
+					AllowedMethods:   []string{"POST"},
+					AllowedHeaders:   []string{"Content-Type", "X-Request-ID"},
+					MaxAgeSeconds:    60,
+					AllowCredentials: true,
+				},
+			},
+		},
+		Peers: Peers{
+			AllowedHosts:         []string{"cache-euw1.prebid.example.com", "10.1.2.3:8000"},
+			Scheme:               "http",
+			TimeoutMillis:        150,
+			MaxResponseSizeBytes: 65536,
+		},
+		ClientIP: ClientIP{
+			TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"},
+			ProxyProtocol: ProxyProtocol{
+				Enabled:             true,
+				HeaderTimeoutMillis: 2000,
+			},
+		},
+		Shutdown: Shutdown{
+			PreStopDelayMillis: 15000,
+			DrainTimeoutMillis: 20000,
+			CloseTimeoutMillis: 3000,
+		},
+		Server: Servers{
+			Main: Server{
+				ReadHeaderTimeoutMillis: 2000,
+				ReadTimeoutMillis:       10000,
+				WriteTimeoutMillis:      12000,
+				IdleTimeoutMillis:       60000,
+				MaxHeaderBytes:          16384,
+				KeepAlivePeriodSeconds:  30,
+				MaxConnections:          5000,
+				UnixSocket: UnixSocket{
+					Path: "/var/run/prebid-cache/main.sock",
+					Mode: "0666",
+				},
+				TLS: TLS{
+					Enabled:               true,
+					CertFile:              "/etc/prebid-cache/tls/server.crt",
+					KeyFile:               "/etc/prebid-cache/tls/server.key",
+					MinVersion:            "1.3",
+					CipherSuites:          []string{},
+					ReloadIntervalSeconds: 30,
+				},
+			},
+			Admin: Server{
+				KeepAlivePeriodSeconds: utils.TCP_KEEP_ALIVE_PERIOD_SECONDS,
+				UnixSocket: UnixSocket{
+					Path:       "/var/run/prebid-cache/admin.sock",
+					Mode:       "0660",
+					DisableTCP: true,
+				},
+				TLS: TLS{
+					Enabled:               true,
+					CertFile:              "/etc/prebid-cache/tls/admin.crt",
+					KeyFile:               "/etc/prebid-cache/tls/admin.key",
+					MinVersion:            "1.2",
+					CipherSuites:          []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+					ClientCAFile:          "/etc/prebid-cache/tls/prebid-server-ca.crt",
+					ReloadIntervalSeconds: utils.TLS_RELOAD_INTERVAL_SECONDS,
+				},
+			},
+		},
+		Tracing: Tracing{
+			Enabled:             true,
+			ServiceName:         "prebid-cache-east",
+			SampleRatio:         0.25,
+			QueueSize:           4096,
+			BatchSize:           256,
+			FlushIntervalMillis: 2000,
+			Exporter: TracingExporter{
+				Type: TracingExporterOTLPHTTP,
+				OTLP: OTLPTraceExporter{
+					Endpoint:      "https://otel-collector.example.com:4318/v1/traces",
+					TimeoutMillis: 3000,
+				},
+			},
 		},
 	}
 }
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"reflect"
+)
+
+// RedactedValue replaces the value of secret settings when the configuration is displayed
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns the configuration as nested maps keyed by the same names used in the
+// configuration file, so it can be printed as YAML or JSON. Fields tagged with `secret:"true"`
+// are replaced by RedactedValue unless they are empty.
+func (cfg Configuration) Redacted() map[string]interface{} {
+	return redactStruct(reflect.ValueOf(cfg))
+}
+
+func redactStruct(v reflect.Value) map[string]interface{} {
+	out := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		value := v.Field(i)
+		switch {
+		case field.Tag.Get("secret") == "true":
+			if value.IsZero() {
+				out[key] = value.Interface()
+			} else {
+				out[key] = RedactedValue
+			}
+		case value.Kind() == reflect.Struct:
+			out[key] = redactStruct(value)
+		default:
+			out[key] = value.Interface()
+		}
+	}
+	return out
+}
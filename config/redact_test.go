@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedacted(t *testing.T) {
+	cfg := getExpectedFullConfigForTestFile()
+	cfg.Backend.Redis.Password = ""
+
+	redacted := cfg.Redacted()
+
+	assert.Equal(t, 9000, redacted["port"], "Plain settings keep their value")
+
+	backend, ok := redacted["backend"].(map[string]interface{})
+	if !assert.True(t, ok, "Sections are nested maps") {
+		return
+	}
+	assert.Equal(t, BackendMemory, backend["type"])
+
+	aerospike := backend["aerospike"].(map[string]interface{})
+	assert.Equal(t, RedactedValue, aerospike["password"], "Secrets are redacted")
+	assert.Equal(t, "foo", aerospike["user"])
+
+	redis := backend["redis"].(map[string]interface{})
+	assert.Equal(t, "", redis["password"], "Empty secrets show they were not set")
+
+	influx := redacted["metrics"].(map[string]interface{})["influx"].(map[string]interface{})
+	assert.Equal(t, RedactedValue, influx["password"], "Secrets are redacted")
+
+	assert.NotEqual(t, RedactedValue, cfg.Backend.Aerospike.Password, "The configuration itself is not modified")
+}
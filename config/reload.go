@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CheckReloadable returns an error listing the settings that differ between the running
+// configuration and the updated one but can only take effect after a restart. Both
+// configurations are expected to have gone through ValidateAndLog already.
+//
+// Settings that shape the request handlers and backend decorators, such as request_limits,
+// rate_limiter, routes, index_response and log.level, are safe to change at runtime. Ports,
+// listeners, backend clients, compression, metrics engines and the tracer are created once at startup.
+func CheckReloadable(current, updated Configuration) error {
+	type setting struct {
+		name             string
+		current, updated interface{}
+	}
+	settings := []setting{
+		{"config.port", current.Port, updated.Port},
+		{"config.admin_port", current.AdminPort, updated.AdminPort},
+		// Secrets are compared redacted, so rotating the contents of a secret file doesn't require a restart.
+		// Only Redis can switch to a rotated password, the other clients keep the secret they started with.
+		{"config.backend", redactStruct(reflect.ValueOf(current.Backend)), redactStruct(reflect.ValueOf(updated.Backend))},
+		{"config.backend.aerospike.password", current.Backend.Aerospike.Password, updated.Backend.Aerospike.Password},
+		{"config.compression", current.Compression, updated.Compression},
+		{"config.metrics", redactStruct(reflect.ValueOf(current.Metrics)), redactStruct(reflect.ValueOf(updated.Metrics))},
+		{"config.metrics.influx.password", current.Metrics.Influx.Password, updated.Metrics.Influx.Password},
+		{"config.client_ip.proxy_protocol", current.ClientIP.ProxyProtocol, updated.ClientIP.ProxyProtocol},
+		// Certificates are reloaded from their files by the servers themselves
+		{"config.server", current.Server, updated.Server},
+		{"config.tracing", redactStruct(reflect.ValueOf(current.Tracing)), redactStruct(reflect.ValueOf(updated.Tracing))},
+		{"config.tracing.exporter.otlp.authorization", current.Tracing.Exporter.OTLP.Authorization, updated.Tracing.Exporter.OTLP.Authorization},
+	}
+	if current.ClientIP.ProxyProtocol.Enabled {
+		// The PROXY protocol listener was created with the trusted proxies found at startup
+		settings = append(settings, setting{"config.client_ip.trusted_proxies", current.ClientIP.TrustedProxies, updated.ClientIP.TrustedProxies})
+	}
+
+	var changed []string
+	for _, s := range settings {
+		if !reflect.DeepEqual(s.current, s.updated) {
+			changed = append(changed, s.name)
+		}
+	}
+	if len(changed) > 0 {
+		return fmt.Errorf("%s cannot be changed without restarting Prebid Cache", strings.Join(changed, ", "))
+	}
+	return nil
+}
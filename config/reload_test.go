@@ -0,0 +1,122 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReloadable(t *testing.T) {
+	testCases := []struct {
+		description   string
+		update        func(cfg *Configuration)
+		expectedError string
+	}{
+		{
+			description: "Nothing changed",
+			update:      func(cfg *Configuration) {},
+		},
+		{
+			description: "Only runtime-safe settings changed",
+			update: func(cfg *Configuration) {
+				cfg.Log.Level = Debug
+				cfg.RateLimiting.MaxRequestsPerSecond = 500
+				cfg.RequestLimits.MaxSize = 20480
+				cfg.RequestLimits.MaxNumValues = 50
+				cfg.RequestLimits.AllowSettingKeys = true
+				cfg.Routes.AllowPublicWrite = false
+				cfg.IndexResponse = "Updated index response"
+				cfg.ClientIP.TrustedProxies = []string{"10.0.0.0/8"}
+			},
+		},
+		{
+			description: "Ports changed",
+			update: func(cfg *Configuration) {
+				cfg.Port = 8000
+				cfg.AdminPort = 8001
+			},
+			expectedError: "config.port, config.admin_port cannot be changed without restarting Prebid Cache",
+		},
+		{
+			description: "Backend, compression and metrics changed",
+			update: func(cfg *Configuration) {
+				cfg.Backend.Type = BackendRedis
+				cfg.Compression.Type = CompressionNone
+				cfg.Metrics.Prometheus.Enabled = true
+			},
+			expectedError: "config.backend, config.compression, config.metrics cannot be changed without restarting Prebid Cache",
+		},
+		{
+			description: "Rotated secrets",
+			update: func(cfg *Configuration) {
+				cfg.Backend.Redis.Password = "rotated-redis-password"
+			},
+		},
+		{
+			description: "Rotated secrets the clients can't switch to",
+			update: func(cfg *Configuration) {
+				cfg.Backend.Aerospike.Password = "rotated-aerospike-password"
+				cfg.Metrics.Influx.Password = "rotated-influx-password"
+				cfg.Tracing.Exporter.OTLP.Authorization = "Bearer rotated-token"
+			},
+			expectedError: "config.backend.aerospike.password, config.metrics.influx.password, config.tracing.exporter.otlp.authorization cannot be changed without restarting Prebid Cache",
+		},
+		{
+			description: "Secret file moved",
+			update: func(cfg *Configuration) {
+				cfg.Backend.Redis.PasswordFile = "/run/secrets/other-redis-password"
+			},
+			expectedError: "config.backend cannot be changed without restarting Prebid Cache",
+		},
+		{
+			description: "PROXY protocol enabled",
+			update: func(cfg *Configuration) {
+				cfg.ClientIP.ProxyProtocol.Enabled = true
+			},
+			expectedError: "config.client_ip.proxy_protocol cannot be changed without restarting Prebid Cache",
+		},
+		{
+			description: "TLS enabled",
+			update: func(cfg *Configuration) {
+				cfg.Server.Admin.TLS.Enabled = true
+			},
+			expectedError: "config.server cannot be changed without restarting Prebid Cache",
+		},
+		{
+			description: "Tracing enabled",
+			update: func(cfg *Configuration) {
+				cfg.Tracing.Enabled = true
+			},
+			expectedError: "config.tracing cannot be changed without restarting Prebid Cache",
+		},
+	}
+
+	for _, tc := range testCases {
+		current := getExpectedDefaultConfig()
+		current.Backend.Redis.Password = "redis-password"
+		current.Backend.Aerospike.Password = "aerospike-password"
+		current.Metrics.Influx.Password = "influx-password"
+		current.Tracing.Exporter.OTLP.Authorization = "Bearer token"
+		updated := current
+		tc.update(&updated)
+
+		err := CheckReloadable(current, updated)
+		if len(tc.expectedError) > 0 {
+			assert.EqualError(t, err, tc.expectedError, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+}
+
+func TestCheckReloadableTrustedProxiesWithProxyProtocol(t *testing.T) {
+	current := getExpectedDefaultConfig()
+	current.ClientIP = ClientIP{
+		TrustedProxies: []string{"10.0.0.0/8"},
+		ProxyProtocol:  ProxyProtocol{Enabled: true, HeaderTimeoutMillis: 5000},
+	}
+	updated := current
+	updated.ClientIP.TrustedProxies = []string{"192.168.0.0/16"}
+
+	assert.EqualError(t, CheckReloadable(current, updated), "config.client_ip.trusted_proxies cannot be changed without restarting Prebid Cache")
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// envReference matches ${NAME} references to environment variables. A reference preceded by an
+// extra dollar sign, as in $${NAME}, is an escaped literal.
+var envReference = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveSecrets sets the value of every field tagged with `secret:"true"`. If the sibling field
+// whose mapstructure key ends in "_file" points to a file, the secret is read from it. Otherwise,
+// the ${NAME} references in the secret are replaced by the value of those environment variables.
+// It's called every time the configuration is loaded, so reloading picks up rotated secret files.
+func resolveSecrets(cfg *Configuration) error {
+	errs := resolveStructSecrets(reflect.ValueOf(cfg).Elem(), "config")
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func resolveStructSecrets(v reflect.Value, prefix string) ValidationErrors {
+	var errs ValidationErrors
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := prefix + "." + field.Tag.Get("mapstructure")
+
+		if field.Tag.Get("secret") != "true" {
+			if field.Type.Kind() == reflect.Struct {
+				errs = append(errs, resolveStructSecrets(v.Field(i), key)...)
+			}
+			continue
+		}
+
+		secret, err := resolveSecret(v.Field(i).String(), secretFile(v, field), key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		v.Field(i).SetString(secret)
+	}
+	return errs
+}
+
+// secretFile returns the path set in the "_file" variant of the secret field, if any
+func secretFile(v reflect.Value, secretField reflect.StructField) string {
+	fileKey := secretField.Tag.Get("mapstructure") + "_file"
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("mapstructure") == fileKey {
+			return v.Field(i).String()
+		}
+	}
+	return ""
+}
+
+func resolveSecret(value, file, key string) (string, error) {
+	if file != "" {
+		if value != "" {
+			return "", fmt.Errorf("%s and %s_file cannot be set at the same time", key, key)
+		}
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("%s_file could not be read: %v", key, err)
+		}
+		// Files written by editors and secret managers usually end in a line break
+		return strings.TrimRight(string(contents), "\r\n"), nil
+	}
+
+	var missing []string
+	secret := envReference.ReplaceAllStringFunc(value, func(reference string) string {
+		match := envReference.FindStringSubmatch(reference)
+		if match[1] != "" {
+			return reference[1:]
+		}
+		envValue, found := os.LookupEnv(match[2])
+		if !found {
+			missing = append(missing, match[2])
+		}
+		return envValue
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s references environment variables that are not set: %s", key, strings.Join(missing, ", "))
+	}
+	return secret, nil
+}
+
+// logSecret logs whether a secret was configured, and where it was read from, without revealing it
+func logSecret(key, value, file string) {
+	if file != "" {
+		log.Infof("%s: %s (read from %s)", key, RedactedValue, file)
+	} else if value != "" {
+		log.Infof("%s: %s", key, RedactedValue)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	testLogrus "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	secretFile := filepath.Join(dir, "redis-password")
+	if !assert.NoError(t, ioutil.WriteFile(secretFile, []byte("password-from-file\n"), 0600)) {
+		return
+	}
+	missingFile := filepath.Join(dir, "missing")
+
+	defer setEnvVar(t, "PBC_TEST_SECRET", "password-from-env")()
+	os.Unsetenv("PBC_TEST_UNSET_SECRET")
+
+	testCases := []struct {
+		description      string
+		inRedis          Redis
+		inInflux         InfluxMetrics
+		expectedRedis    string
+		expectedInflux   string
+		expectedErrorMsg string
+	}{
+		{
+			description:    "Plain secrets are kept",
+			inRedis:        Redis{Password: "plain"},
+			expectedRedis:  "plain",
+			expectedInflux: "",
+		},
+		{
+			description:    "Secret read from a file, without its trailing line break",
+			inRedis:        Redis{PasswordFile: secretFile},
+			expectedRedis:  "password-from-file",
+			expectedInflux: "",
+		},
+		{
+			description:    "Environment variable references are expanded",
+			inRedis:        Redis{Password: "${PBC_TEST_SECRET}"},
+			inInflux:       InfluxMetrics{Password: "prefix-${PBC_TEST_SECRET}-suffix"},
+			expectedRedis:  "password-from-env",
+			expectedInflux: "prefix-password-from-env-suffix",
+		},
+		{
+			description:   "Escaped references and dollar signs are kept",
+			inRedis:       Redis{Password: "$${PBC_TEST_SECRET}$1$abc"},
+			expectedRedis: "${PBC_TEST_SECRET}$1$abc",
+		},
+		{
+			description:      "Secret and secret file set at the same time",
+			inRedis:          Redis{Password: "plain", PasswordFile: secretFile},
+			expectedErrorMsg: "config.backend.redis.password and config.backend.redis.password_file cannot be set at the same time",
+		},
+		{
+			description:      "Every problem is reported",
+			inRedis:          Redis{PasswordFile: missingFile},
+			inInflux:         InfluxMetrics{Password: "${PBC_TEST_UNSET_SECRET}"},
+			expectedErrorMsg: fmt.Sprintf("config.backend.redis.password_file could not be read: open %s: no such file or directory; config.metrics.influx.password references environment variables that are not set: PBC_TEST_UNSET_SECRET", missingFile),
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := Configuration{
+			Backend: Backend{Redis: tc.inRedis},
+			Metrics: Metrics{Influx: tc.inInflux},
+		}
+
+		err := resolveSecrets(&cfg)
+
+		if len(tc.expectedErrorMsg) > 0 {
+			assert.EqualError(t, err, tc.expectedErrorMsg, tc.description)
+			continue
+		}
+		if assert.NoError(t, err, tc.description) {
+			assert.Equal(t, tc.expectedRedis, cfg.Backend.Redis.Password, tc.description)
+			assert.Equal(t, tc.expectedInflux, cfg.Metrics.Influx.Password, tc.description)
+		}
+	}
+}
+
+func TestLoadConfigReadsSecretFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	secretFile := filepath.Join(dir, "aerospike-password")
+	ioutil.WriteFile(secretFile, []byte("first"), 0600)
+	defer setEnvVar(t, "PBC_BACKEND_AEROSPIKE_PASSWORD_FILE", secretFile)()
+
+	cfg, err := LoadConfig("config_that_does_not_exist")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "first", cfg.Backend.Aerospike.Password)
+	}
+
+	// Loading the configuration again, as a reload does, picks up the rotated secret
+	ioutil.WriteFile(secretFile, []byte("rotated"), 0600)
+	cfg, err = LoadConfig("config_that_does_not_exist")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "rotated", cfg.Backend.Aerospike.Password)
+	}
+}
+
+func TestLogSecret(t *testing.T) {
+	hook := testLogrus.NewGlobal()
+
+	logSecret("config.backend.redis.password", "", "")
+	assert.Empty(t, hook.Entries, "Unset secrets are not logged")
+
+	logSecret("config.backend.redis.password", "s3cret", "")
+	if assert.Len(t, hook.Entries, 1) {
+		assert.Equal(t, logrus.InfoLevel, hook.LastEntry().Level)
+		assert.Equal(t, "config.backend.redis.password: [REDACTED]", hook.LastEntry().Message)
+	}
+
+	logSecret("config.backend.redis.password", "s3cret", "/run/secrets/redis")
+	if assert.Len(t, hook.Entries, 2) {
+		assert.Equal(t, "config.backend.redis.password: [REDACTED] (read from /run/secrets/redis)", hook.LastEntry().Message)
+	}
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Servers holds the settings of the main server, which listens on config.port, and of the admin
+// server, which listens on config.admin_port.
+type Servers struct {
+	Main  Server `mapstructure:"main"`
+	Admin Server `mapstructure:"admin"`
+}
+
+func (cfg *Servers) validateAndLog() []error {
+	errs := cfg.Main.validateAndLog("config.server.main")
+	return append(errs, cfg.Admin.validateAndLog("config.server.admin")...)
+}
+
+// Server holds the settings of a single HTTP server. Timeouts set to 0 don't expire, except for
+// the read header and idle timeouts, which fall back to the read timeout.
+type Server struct {
+	ReadHeaderTimeoutMillis int `mapstructure:"read_header_timeout_ms"`
+	ReadTimeoutMillis       int `mapstructure:"read_timeout_ms"`
+	WriteTimeoutMillis      int `mapstructure:"write_timeout_ms"`
+	IdleTimeoutMillis       int `mapstructure:"idle_timeout_ms"`
+	// MaxHeaderBytes limits the size of the request headers. 0 uses Go's default of 1 MB.
+	MaxHeaderBytes int `mapstructure:"max_header_bytes"`
+	// KeepAlivePeriodSeconds is the interval of the TCP keep-alive probes. 0 disables them.
+	KeepAlivePeriodSeconds int `mapstructure:"keep_alive_period_seconds"`
+	// MaxConnections caps the number of connections served at the same time, over TCP and the Unix
+	// socket together. Once it's reached, new connections wait until another one closes. 0 means
+	// no limit.
+	MaxConnections int `mapstructure:"max_connections"`
+	// H2C accepts HTTP/2 requests over plain TCP connections, either with prior knowledge or
+	// upgraded from HTTP/1.1, so callers can multiplex their requests
+	H2C        bool       `mapstructure:"h2c"`
+	UnixSocket UnixSocket `mapstructure:"unix_socket"`
+	TLS        TLS        `mapstructure:"tls"`
+}
+
+func (cfg *Server) validateAndLog(prefix string) []error {
+	var errs []error
+	settings := []struct {
+		name  string
+		value int
+	}{
+		{"read_header_timeout_ms", cfg.ReadHeaderTimeoutMillis},
+		{"read_timeout_ms", cfg.ReadTimeoutMillis},
+		{"write_timeout_ms", cfg.WriteTimeoutMillis},
+		{"idle_timeout_ms", cfg.IdleTimeoutMillis},
+		{"max_header_bytes", cfg.MaxHeaderBytes},
+		{"keep_alive_period_seconds", cfg.KeepAlivePeriodSeconds},
+		{"max_connections", cfg.MaxConnections},
+	}
+	for _, setting := range settings {
+		if setting.value < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s.%s: %d. Value cannot be negative.", prefix, setting.name, setting.value))
+		}
+	}
+	if cfg.H2C && cfg.TLS.Enabled {
+		errs = append(errs, fmt.Errorf("%s.h2c can't be combined with %s.tls.enabled. HTTP/2 is negotiated during the TLS handshake instead.", prefix, prefix))
+	}
+	if len(errs) == 0 {
+		for _, setting := range settings {
+			log.Infof("%s.%s: %d", prefix, setting.name, setting.value)
+		}
+		log.Infof("%s.h2c: %t", prefix, cfg.H2C)
+	}
+
+	errs = append(errs, cfg.UnixSocket.validateAndLog(prefix+".unix_socket")...)
+	return append(errs, cfg.TLS.validateAndLog(prefix+".tls")...)
+}
+
+// ReadHeaderTimeout is how long clients have to send the request headers
+func (cfg *Server) ReadHeaderTimeout() time.Duration {
+	return time.Duration(cfg.ReadHeaderTimeoutMillis) * time.Millisecond
+}
+
+// ReadTimeout is how long clients have to send a whole request, body included
+func (cfg *Server) ReadTimeout() time.Duration {
+	return time.Duration(cfg.ReadTimeoutMillis) * time.Millisecond
+}
+
+// WriteTimeout is how long a request can take, from the end of its headers to the end of the response
+func (cfg *Server) WriteTimeout() time.Duration {
+	return time.Duration(cfg.WriteTimeoutMillis) * time.Millisecond
+}
+
+// IdleTimeout is how long a keep-alive connection can wait for the next request
+func (cfg *Server) IdleTimeout() time.Duration {
+	return time.Duration(cfg.IdleTimeoutMillis) * time.Millisecond
+}
+
+// KeepAlivePeriod is the interval of the TCP keep-alive probes
+func (cfg *Server) KeepAlivePeriod() time.Duration {
+	return time.Duration(cfg.KeepAlivePeriodSeconds) * time.Second
+}
+
+// UnixSocket makes a server accept connections on a Unix domain socket, which spares co-located
+// callers the cost of TCP loopback connections
+type UnixSocket struct {
+	// Path of the socket file. An empty path disables the socket.
+	Path string `mapstructure:"path"`
+	// Mode holds the octal permissions of the socket file, such as "0660". Clients need write
+	// permission to connect.
+	Mode string `mapstructure:"mode"`
+	// DisableTCP stops the server from listening on its TCP port, so it can only be reached
+	// through the socket
+	DisableTCP bool `mapstructure:"disable_tcp"`
+}
+
+func (cfg *UnixSocket) validateAndLog(prefix string) []error {
+	if cfg.Path == "" {
+		if cfg.DisableTCP {
+			return []error{fmt.Errorf("%s.disable_tcp requires a %s.path", prefix, prefix)}
+		}
+		return nil
+	}
+
+	if _, err := cfg.FileMode(); err != nil {
+		return []error{fmt.Errorf("invalid %s.mode: %s. It must be octal file permissions, such as 0660", prefix, cfg.Mode)}
+	}
+
+	log.Infof("%s.path: %s", prefix, cfg.Path)
+	log.Infof("%s.mode: %s", prefix, cfg.Mode)
+	log.Infof("%s.disable_tcp: %t", prefix, cfg.DisableTCP)
+	return nil
+}
+
+// FileMode parses Mode
+func (cfg *UnixSocket) FileMode() (os.FileMode, error) {
+	mode, err := strconv.ParseUint(cfg.Mode, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if mode > 0777 {
+		return 0, fmt.Errorf("%s has bits other than permissions set", cfg.Mode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// TLS makes a server accept HTTPS connections only. The certificate, key and client CA files are
+// read again whenever their modification time changes, so renewed certificates don't require a
+// restart.
+type TLS struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// MinVersion is the oldest TLS version accepted: "1.0", "1.1", "1.2" or "1.3"
+	MinVersion string `mapstructure:"min_version"`
+	// CipherSuites restricts the cipher suites used by TLS 1.0 to 1.2 connections. The names are
+	// the ones of the Go crypto/tls constants, such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. When
+	// empty, Go's secure defaults are used. TLS 1.3 suites can't be configured.
+	CipherSuites []string `mapstructure:"cipher_suites"`
+	// ClientCAFile, when set, makes the server require client certificates signed by one of
+	// the certificate authorities found in this PEM file (mutual TLS)
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// ReloadIntervalSeconds is how often the files are checked for changes. 0 disables reloading.
+	ReloadIntervalSeconds int `mapstructure:"reload_interval_seconds"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func (cfg *TLS) validateAndLog(prefix string) []error {
+	if !cfg.Enabled {
+		log.Infof("%s.enabled: false", prefix)
+		return nil
+	}
+
+	var errs []error
+	if cfg.CertFile == "" {
+		errs = append(errs, fmt.Errorf("%s.cert_file is required when TLS is enabled", prefix))
+	}
+	if cfg.KeyFile == "" {
+		errs = append(errs, fmt.Errorf("%s.key_file is required when TLS is enabled", prefix))
+	}
+	if _, ok := tlsVersions[cfg.MinVersion]; !ok {
+		errs = append(errs, fmt.Errorf(`invalid %s.min_version: %s. It must be "1.0", "1.1", "1.2" or "1.3"`, prefix, cfg.MinVersion))
+	} else if cfg.MinVersion == "1.3" && len(cfg.CipherSuites) > 0 {
+		errs = append(errs, fmt.Errorf("%s.cipher_suites can't be set when %s.min_version is 1.3", prefix, prefix))
+	}
+	for _, name := range cfg.CipherSuites {
+		if _, ok := cipherSuiteID(name); !ok {
+			errs = append(errs, fmt.Errorf("invalid %s.cipher_suites: %s is not a supported secure cipher suite", prefix, name))
+		}
+	}
+	if cfg.ReloadIntervalSeconds < 0 {
+		errs = append(errs, fmt.Errorf("invalid %s.reload_interval_seconds: %d. Value cannot be negative.", prefix, cfg.ReloadIntervalSeconds))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	log.Infof("%s.enabled: true", prefix)
+	log.Infof("%s.cert_file: %s", prefix, cfg.CertFile)
+	log.Infof("%s.key_file: %s", prefix, cfg.KeyFile)
+	log.Infof("%s.min_version: %s", prefix, cfg.MinVersion)
+	log.Infof("%s.cipher_suites: %v", prefix, cfg.CipherSuites)
+	log.Infof("%s.client_ca_file: %s", prefix, cfg.ClientCAFile)
+	log.Infof("%s.reload_interval_seconds: %d", prefix, cfg.ReloadIntervalSeconds)
+	return nil
+}
+
+// Version returns the crypto/tls constant of MinVersion. It must be called on a valid configuration.
+func (cfg *TLS) Version() uint16 {
+	return tlsVersions[cfg.MinVersion]
+}
+
+// CipherSuiteIDs returns the crypto/tls identifiers of CipherSuites, or nil to use Go's defaults.
+// It must be called on a valid configuration.
+func (cfg *TLS) CipherSuiteIDs() []uint16 {
+	if len(cfg.CipherSuites) == 0 {
+		return nil
+	}
+	ids := make([]uint16, 0, len(cfg.CipherSuites))
+	for _, name := range cfg.CipherSuites {
+		id, _ := cipherSuiteID(name)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ReloadInterval is how often the certificate files are checked for changes
+func (cfg *TLS) ReloadInterval() time.Duration {
+	return time.Duration(cfg.ReloadIntervalSeconds) * time.Second
+}
+
+// cipherSuiteID looks name up among the cipher suites crypto/tls doesn't consider insecure
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
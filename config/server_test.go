@@ -0,0 +1,267 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	testLogrus "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerValidateAndLog(t *testing.T) {
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	testCases := []struct {
+		description     string
+		inServerCfg     *Server
+		expectedErrors  []error
+		expectedLogInfo []string
+	}{
+		{
+			description: "Valid server settings",
+			inServerCfg: &Server{
+				ReadHeaderTimeoutMillis: 1000,
+				ReadTimeoutMillis:       2000,
+				WriteTimeoutMillis:      3000,
+				IdleTimeoutMillis:       4000,
+				MaxHeaderBytes:          8192,
+				KeepAlivePeriodSeconds:  30,
+				MaxConnections:          100,
+				H2C:                     true,
+			},
+			expectedLogInfo: []string{
+				"config.server.main.read_header_timeout_ms: 1000",
+				"config.server.main.read_timeout_ms: 2000",
+				"config.server.main.write_timeout_ms: 3000",
+				"config.server.main.idle_timeout_ms: 4000",
+				"config.server.main.max_header_bytes: 8192",
+				"config.server.main.keep_alive_period_seconds: 30",
+				"config.server.main.max_connections: 100",
+				"config.server.main.h2c: true",
+				"config.server.main.tls.enabled: false",
+			},
+		},
+		{
+			description: "Every invalid server setting is reported",
+			inServerCfg: &Server{
+				ReadHeaderTimeoutMillis: -1,
+				ReadTimeoutMillis:       -2,
+				WriteTimeoutMillis:      -3,
+				IdleTimeoutMillis:       -4,
+				MaxHeaderBytes:          -5,
+				KeepAlivePeriodSeconds:  -6,
+				MaxConnections:          -7,
+			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.server.main.read_header_timeout_ms: -1. Value cannot be negative."),
+				fmt.Errorf("invalid config.server.main.read_timeout_ms: -2. Value cannot be negative."),
+				fmt.Errorf("invalid config.server.main.write_timeout_ms: -3. Value cannot be negative."),
+				fmt.Errorf("invalid config.server.main.idle_timeout_ms: -4. Value cannot be negative."),
+				fmt.Errorf("invalid config.server.main.max_header_bytes: -5. Value cannot be negative."),
+				fmt.Errorf("invalid config.server.main.keep_alive_period_seconds: -6. Value cannot be negative."),
+				fmt.Errorf("invalid config.server.main.max_connections: -7. Value cannot be negative."),
+			},
+			expectedLogInfo: []string{"config.server.main.tls.enabled: false"},
+		},
+		{
+			description: "h2c with TLS",
+			inServerCfg: &Server{
+				H2C: true,
+				TLS: TLS{Enabled: true, CertFile: "server.crt", KeyFile: "server.key", MinVersion: "1.2"},
+			},
+			expectedErrors: []error{
+				fmt.Errorf("config.server.main.h2c can't be combined with config.server.main.tls.enabled. HTTP/2 is negotiated during the TLS handshake instead."),
+			},
+			expectedLogInfo: []string{
+				"config.server.main.tls.enabled: true",
+				"config.server.main.tls.cert_file: server.crt",
+				"config.server.main.tls.key_file: server.key",
+				"config.server.main.tls.min_version: 1.2",
+				"config.server.main.tls.cipher_suites: []",
+				"config.server.main.tls.client_ca_file: ",
+				"config.server.main.tls.reload_interval_seconds: 0",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		err := tc.inServerCfg.validateAndLog("config.server.main")
+
+		// Assertions
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i], hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, logrus.InfoLevel, hook.Entries[i].Level, tc.description+":log level")
+			}
+		}
+
+		//Reset log after every test and assert successful reset
+		hook.Reset()
+		assert.Nil(t, hook.LastEntry())
+	}
+}
+
+func TestUnixSocketValidateAndLog(t *testing.T) {
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	testCases := []struct {
+		description     string
+		inSocketCfg     *UnixSocket
+		expectedErrors  []error
+		expectedLogInfo []string
+	}{
+		{
+			description:     "Socket disabled",
+			inSocketCfg:     &UnixSocket{Mode: "0660"},
+			expectedLogInfo: []string{},
+		},
+		{
+			description: "Socket instead of the TCP port",
+			inSocketCfg: &UnixSocket{Path: "/var/run/prebid-cache.sock", Mode: "0660", DisableTCP: true},
+			expectedLogInfo: []string{
+				"config.server.main.unix_socket.path: /var/run/prebid-cache.sock",
+				"config.server.main.unix_socket.mode: 0660",
+				"config.server.main.unix_socket.disable_tcp: true",
+			},
+		},
+		{
+			description:     "TCP disabled without a socket",
+			inSocketCfg:     &UnixSocket{Mode: "0660", DisableTCP: true},
+			expectedErrors:  []error{fmt.Errorf("config.server.main.unix_socket.disable_tcp requires a config.server.main.unix_socket.path")},
+			expectedLogInfo: []string{},
+		},
+		{
+			description:     "Mode that isn't octal",
+			inSocketCfg:     &UnixSocket{Path: "/var/run/prebid-cache.sock", Mode: "rw-rw----"},
+			expectedErrors:  []error{fmt.Errorf("invalid config.server.main.unix_socket.mode: rw-rw----. It must be octal file permissions, such as 0660")},
+			expectedLogInfo: []string{},
+		},
+		{
+			description:     "Mode with bits other than permissions",
+			inSocketCfg:     &UnixSocket{Path: "/var/run/prebid-cache.sock", Mode: "4755"},
+			expectedErrors:  []error{fmt.Errorf("invalid config.server.main.unix_socket.mode: 4755. It must be octal file permissions, such as 0660")},
+			expectedLogInfo: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		err := tc.inSocketCfg.validateAndLog("config.server.main.unix_socket")
+
+		// Assertions
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i], hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, logrus.InfoLevel, hook.Entries[i].Level, tc.description+":log level")
+			}
+		}
+
+		//Reset log after every test and assert successful reset
+		hook.Reset()
+		assert.Nil(t, hook.LastEntry())
+	}
+}
+
+func TestTLSValidateAndLog(t *testing.T) {
+	// logrus entries will be recorded to this `hook` object so we can compare and assert them
+	hook := testLogrus.NewGlobal()
+
+	testCases := []struct {
+		description     string
+		inTLSCfg        *TLS
+		expectedErrors  []error
+		expectedLogInfo []string
+	}{
+		{
+			description:     "TLS disabled",
+			inTLSCfg:        &TLS{MinVersion: "not validated"},
+			expectedLogInfo: []string{"config.server.admin.tls.enabled: false"},
+		},
+		{
+			description: "Valid TLS settings",
+			inTLSCfg: &TLS{
+				Enabled:               true,
+				CertFile:              "server.crt",
+				KeyFile:               "server.key",
+				MinVersion:            "1.2",
+				CipherSuites:          []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+				ClientCAFile:          "ca.crt",
+				ReloadIntervalSeconds: 60,
+			},
+			expectedLogInfo: []string{
+				"config.server.admin.tls.enabled: true",
+				"config.server.admin.tls.cert_file: server.crt",
+				"config.server.admin.tls.key_file: server.key",
+				"config.server.admin.tls.min_version: 1.2",
+				"config.server.admin.tls.cipher_suites: [TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256]",
+				"config.server.admin.tls.client_ca_file: ca.crt",
+				"config.server.admin.tls.reload_interval_seconds: 60",
+			},
+		},
+		{
+			description: "Every invalid TLS setting is reported",
+			inTLSCfg: &TLS{
+				Enabled:               true,
+				MinVersion:            "1.4",
+				CipherSuites:          []string{"TLS_RSA_WITH_RC4_128_SHA", "TLS_MADE_UP"},
+				ReloadIntervalSeconds: -1,
+			},
+			expectedErrors: []error{
+				fmt.Errorf("config.server.admin.tls.cert_file is required when TLS is enabled"),
+				fmt.Errorf("config.server.admin.tls.key_file is required when TLS is enabled"),
+				fmt.Errorf(`invalid config.server.admin.tls.min_version: 1.4. It must be "1.0", "1.1", "1.2" or "1.3"`),
+				fmt.Errorf("invalid config.server.admin.tls.cipher_suites: TLS_RSA_WITH_RC4_128_SHA is not a supported secure cipher suite"),
+				fmt.Errorf("invalid config.server.admin.tls.cipher_suites: TLS_MADE_UP is not a supported secure cipher suite"),
+				fmt.Errorf("invalid config.server.admin.tls.reload_interval_seconds: -1. Value cannot be negative."),
+			},
+			expectedLogInfo: []string{},
+		},
+		{
+			description: "Cipher suites with TLS 1.3",
+			inTLSCfg: &TLS{
+				Enabled:      true,
+				CertFile:     "server.crt",
+				KeyFile:      "server.key",
+				MinVersion:   "1.3",
+				CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			},
+			expectedErrors:  []error{fmt.Errorf("config.server.admin.tls.cipher_suites can't be set when config.server.admin.tls.min_version is 1.3")},
+			expectedLogInfo: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		err := tc.inTLSCfg.validateAndLog("config.server.admin.tls")
+
+		// Assertions
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i], hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, logrus.InfoLevel, hook.Entries[i].Level, tc.description+":log level")
+			}
+		}
+
+		//Reset log after every test and assert successful reset
+		hook.Reset()
+		assert.Nil(t, hook.LastEntry())
+	}
+}
+
+func TestTLSVersionAndCipherSuites(t *testing.T) {
+	cfg := TLS{MinVersion: "1.2"}
+	assert.Equal(t, uint16(tls.VersionTLS12), cfg.Version())
+	assert.Nil(t, cfg.CipherSuiteIDs(), "Go's default cipher suites should be used when none are configured")
+
+	cfg = TLS{MinVersion: "1.1", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"}}
+	assert.Equal(t, uint16(tls.VersionTLS11), cfg.Version())
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}, cfg.CipherSuiteIDs())
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tracing records spans for the requests Prebid Cache serves, their backend calls and the
+// compression of their values. Incoming W3C traceparent headers are honored, so the spans join the
+// traces of the callers.
+type Tracing struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRatio is the fraction of the traces started by Prebid Cache that get exported. Requests
+	// that come with a traceparent header follow the sampling decision of the caller.
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	// QueueSize caps the spans waiting to be exported. Spans ended while the queue is full are dropped.
+	QueueSize int `mapstructure:"queue_size"`
+	// BatchSize is the maximum number of spans sent to the exporter at once
+	BatchSize           int             `mapstructure:"batch_size"`
+	FlushIntervalMillis int             `mapstructure:"flush_interval_ms"`
+	Exporter            TracingExporter `mapstructure:"exporter"`
+}
+
+func (cfg *Tracing) validateAndLog() []error {
+	log.Infof("config.tracing.enabled: %t", cfg.Enabled)
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if cfg.ServiceName == "" {
+		errs = append(errs, fmt.Errorf("config.tracing.service_name must not be empty"))
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("invalid config.tracing.sample_ratio: %v. It must be between 0 and 1.", cfg.SampleRatio))
+	}
+	settings := []struct {
+		name  string
+		value int
+	}{
+		{"queue_size", cfg.QueueSize},
+		{"batch_size", cfg.BatchSize},
+		{"flush_interval_ms", cfg.FlushIntervalMillis},
+	}
+	for _, setting := range settings {
+		if setting.value <= 0 {
+			errs = append(errs, fmt.Errorf("invalid config.tracing.%s: %d. Value must be positive.", setting.name, setting.value))
+		}
+	}
+	if len(errs) == 0 {
+		log.Infof("config.tracing.service_name: %s", cfg.ServiceName)
+		log.Infof("config.tracing.sample_ratio: %v", cfg.SampleRatio)
+		for _, setting := range settings {
+			log.Infof("config.tracing.%s: %d", setting.name, setting.value)
+		}
+	}
+
+	return append(errs, cfg.Exporter.validateAndLog()...)
+}
+
+// FlushInterval is how long ended spans can wait in the queue before they are exported
+func (cfg *Tracing) FlushInterval() time.Duration {
+	return time.Duration(cfg.FlushIntervalMillis) * time.Millisecond
+}
+
+// TracingExporter selects where the spans are sent
+type TracingExporter struct {
+	Type TracingExporterType `mapstructure:"type"`
+	// FilePath is the file the "file" exporter appends the spans to
+	FilePath string            `mapstructure:"file_path"`
+	OTLP     OTLPTraceExporter `mapstructure:"otlp"`
+}
+
+func (cfg *TracingExporter) validateAndLog() []error {
+	switch cfg.Type {
+	case TracingExporterStdout:
+	case TracingExporterFile:
+		if cfg.FilePath == "" {
+			return []error{fmt.Errorf(`config.tracing.exporter.file_path is required by the "file" exporter`)}
+		}
+		log.Infof("config.tracing.exporter.file_path: %s", cfg.FilePath)
+	case TracingExporterOTLPHTTP:
+		if errs := cfg.OTLP.validateAndLog(); len(errs) > 0 {
+			return errs
+		}
+	default:
+		return []error{fmt.Errorf(`invalid config.tracing.exporter.type: %s. It must be "stdout", "file" or "otlp_http"`, cfg.Type)}
+	}
+	log.Infof("config.tracing.exporter.type: %s", cfg.Type)
+	return nil
+}
+
+type TracingExporterType string
+
+const (
+	// TracingExporterStdout writes a JSON line per span to the standard output
+	TracingExporterStdout TracingExporterType = "stdout"
+	// TracingExporterFile appends a JSON line per span to a file
+	TracingExporterFile TracingExporterType = "file"
+	// TracingExporterOTLPHTTP sends the spans to an OpenTelemetry collector with the OTLP/HTTP protocol
+	TracingExporterOTLPHTTP TracingExporterType = "otlp_http"
+)
+
+// OTLPTraceExporter holds the settings of the OTLP/HTTP exporter, which posts the spans encoded
+// as JSON to a collector
+type OTLPTraceExporter struct {
+	// Endpoint is the full URL spans are posted to, usually ending in /v1/traces
+	Endpoint string `mapstructure:"endpoint"`
+	// Authorization is sent as the Authorization header, for collectors that require it
+	Authorization     string `mapstructure:"authorization" secret:"true"`
+	AuthorizationFile string `mapstructure:"authorization_file"`
+	TimeoutMillis     int    `mapstructure:"timeout_ms"`
+}
+
+func (cfg *OTLPTraceExporter) validateAndLog() []error {
+	var errs []error
+	if endpoint, err := url.Parse(cfg.Endpoint); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid config.tracing.exporter.otlp.endpoint: %s. It must be an http or https URL.", cfg.Endpoint))
+	}
+	if cfg.TimeoutMillis <= 0 {
+		errs = append(errs, fmt.Errorf("invalid config.tracing.exporter.otlp.timeout_ms: %d. Value must be positive.", cfg.TimeoutMillis))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	log.Infof("config.tracing.exporter.otlp.endpoint: %s", cfg.Endpoint)
+	log.Infof("config.tracing.exporter.otlp.timeout_ms: %d", cfg.TimeoutMillis)
+	return nil
+}
+
+// Timeout is how long the exporter waits for the collector to accept a batch of spans
+func (cfg *OTLPTraceExporter) Timeout() time.Duration {
+	return time.Duration(cfg.TimeoutMillis) * time.Millisecond
+}
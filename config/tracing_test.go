@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	testLogrus "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracingValidateAndLog(t *testing.T) {
+	hook := testLogrus.NewGlobal()
+	defer func() { logrus.StandardLogger().ExitFunc = nil }()
+	logrus.StandardLogger().ExitFunc = func(int) {}
+
+	validOTLP := OTLPTraceExporter{Endpoint: "http://localhost:4318/v1/traces", TimeoutMillis: 1000}
+
+	testCases := []struct {
+		description     string
+		inTracingCfg    *Tracing
+		expectedErrors  []error
+		expectedLogInfo []string
+	}{
+		{
+			description:     "Tracing disabled. The rest of the settings are not validated",
+			inTracingCfg:    &Tracing{Enabled: false, SampleRatio: 2},
+			expectedLogInfo: []string{"config.tracing.enabled: false"},
+		},
+		{
+			description: "Valid settings with the stdout exporter",
+			inTracingCfg: &Tracing{
+				Enabled:             true,
+				ServiceName:         "prebid-cache",
+				SampleRatio:         0.5,
+				QueueSize:           100,
+				BatchSize:           10,
+				FlushIntervalMillis: 1000,
+				Exporter:            TracingExporter{Type: TracingExporterStdout},
+			},
+			expectedLogInfo: []string{
+				"config.tracing.enabled: true",
+				"config.tracing.service_name: prebid-cache",
+				"config.tracing.sample_ratio: 0.5",
+				"config.tracing.queue_size: 100",
+				"config.tracing.batch_size: 10",
+				"config.tracing.flush_interval_ms: 1000",
+				"config.tracing.exporter.type: stdout",
+			},
+		},
+		{
+			description: "Valid settings with the OTLP/HTTP exporter. The authorization is not logged",
+			inTracingCfg: &Tracing{
+				Enabled:             true,
+				ServiceName:         "prebid-cache",
+				SampleRatio:         1,
+				QueueSize:           100,
+				BatchSize:           10,
+				FlushIntervalMillis: 1000,
+				Exporter: TracingExporter{
+					Type: TracingExporterOTLPHTTP,
+					OTLP: OTLPTraceExporter{Endpoint: "https://collector:4318/v1/traces", Authorization: "Bearer secret", TimeoutMillis: 500},
+				},
+			},
+			expectedLogInfo: []string{
+				"config.tracing.enabled: true",
+				"config.tracing.service_name: prebid-cache",
+				"config.tracing.sample_ratio: 1",
+				"config.tracing.queue_size: 100",
+				"config.tracing.batch_size: 10",
+				"config.tracing.flush_interval_ms: 1000",
+				"config.tracing.exporter.otlp.endpoint: https://collector:4318/v1/traces",
+				"config.tracing.exporter.otlp.timeout_ms: 500",
+				"config.tracing.exporter.type: otlp_http",
+			},
+		},
+		{
+			description: "Every invalid tracing setting is reported",
+			inTracingCfg: &Tracing{
+				Enabled:             true,
+				ServiceName:         "",
+				SampleRatio:         1.5,
+				QueueSize:           0,
+				BatchSize:           -1,
+				FlushIntervalMillis: 0,
+				Exporter:            TracingExporter{Type: "zipkin", OTLP: validOTLP},
+			},
+			expectedErrors: []error{
+				fmt.Errorf("config.tracing.service_name must not be empty"),
+				fmt.Errorf("invalid config.tracing.sample_ratio: 1.5. It must be between 0 and 1."),
+				fmt.Errorf("invalid config.tracing.queue_size: 0. Value must be positive."),
+				fmt.Errorf("invalid config.tracing.batch_size: -1. Value must be positive."),
+				fmt.Errorf("invalid config.tracing.flush_interval_ms: 0. Value must be positive."),
+				fmt.Errorf(`invalid config.tracing.exporter.type: zipkin. It must be "stdout", "file" or "otlp_http"`),
+			},
+			expectedLogInfo: []string{"config.tracing.enabled: true"},
+		},
+		{
+			description: "File exporter without a path",
+			inTracingCfg: &Tracing{
+				Enabled:             true,
+				ServiceName:         "prebid-cache",
+				SampleRatio:         1,
+				QueueSize:           100,
+				BatchSize:           10,
+				FlushIntervalMillis: 1000,
+				Exporter:            TracingExporter{Type: TracingExporterFile},
+			},
+			expectedErrors: []error{
+				fmt.Errorf(`config.tracing.exporter.file_path is required by the "file" exporter`),
+			},
+			expectedLogInfo: []string{
+				"config.tracing.enabled: true",
+				"config.tracing.service_name: prebid-cache",
+				"config.tracing.sample_ratio: 1",
+				"config.tracing.queue_size: 100",
+				"config.tracing.batch_size: 10",
+				"config.tracing.flush_interval_ms: 1000",
+			},
+		},
+		{
+			description: "OTLP/HTTP exporter with an invalid endpoint and timeout",
+			inTracingCfg: &Tracing{
+				Enabled:             true,
+				ServiceName:         "prebid-cache",
+				SampleRatio:         0,
+				QueueSize:           100,
+				BatchSize:           10,
+				FlushIntervalMillis: 1000,
+				Exporter: TracingExporter{
+					Type: TracingExporterOTLPHTTP,
+					OTLP: OTLPTraceExporter{Endpoint: "localhost:4318", TimeoutMillis: 0},
+				},
+			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid config.tracing.exporter.otlp.endpoint: localhost:4318. It must be an http or https URL."),
+				fmt.Errorf("invalid config.tracing.exporter.otlp.timeout_ms: 0. Value must be positive."),
+			},
+			expectedLogInfo: []string{
+				"config.tracing.enabled: true",
+				"config.tracing.service_name: prebid-cache",
+				"config.tracing.sample_ratio: 0",
+				"config.tracing.queue_size: 100",
+				"config.tracing.batch_size: 10",
+				"config.tracing.flush_interval_ms: 1000",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		err := tc.inTracingCfg.validateAndLog()
+
+		// Assertions
+		assert.Equal(t, tc.expectedErrors, err, tc.description)
+		if assert.Len(t, hook.Entries, len(tc.expectedLogInfo), tc.description) {
+			for i := 0; i < len(tc.expectedLogInfo); i++ {
+				assert.Equal(t, tc.expectedLogInfo[i], hook.Entries[i].Message, tc.description+":message")
+				assert.Equal(t, logrus.InfoLevel, hook.Entries[i].Level, tc.description+":log level")
+			}
+		}
+
+		//Reset log after every test and assert successful reset
+		hook.Reset()
+		assert.Nil(t, hook.LastEntry())
+	}
+}
@@ -0,0 +1,114 @@
+package endpoints
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// limitedBody is a request body that can't be read past maxBytes. Reading further fails with a
+// PUT_BODY_TOO_LARGE error.
+type limitedBody struct {
+	io.ReadCloser
+	maxBytes int64
+	read     int64
+	desc     string
+}
+
+// newLimitedBody wraps the body in an http.MaxBytesReader, which also tells the server to close the
+// connection once the limit is hit. desc names the body in the error message.
+func newLimitedBody(w http.ResponseWriter, body io.ReadCloser, maxBytes int64, desc string) *limitedBody {
+	return &limitedBody{
+		ReadCloser: http.MaxBytesReader(w, body, maxBytes),
+		maxBytes:   maxBytes,
+		desc:       desc,
+	}
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if err != nil && err != io.EOF && b.read >= b.maxBytes {
+		// http.MaxBytesReader only fails after reading maxBytes when there's more to read
+		err = utils.NewPBCError(utils.PUT_BODY_TOO_LARGE, fmt.Sprintf("POST /cache %s exceeded max size: %d bytes", b.desc, b.maxBytes))
+	}
+	return n, err
+}
+
+// putBody is the body of a POST request, limited in size and decompressed according to its
+// Content-Encoding header
+type putBody struct {
+	*limitedBody
+	// raw is the body as it was received. It's the same as limitedBody unless the body is compressed.
+	raw *limitedBody
+}
+
+// newPutBody limits the size of the request body and, if it's compressed, decompresses it. The size
+// of a compressed body is limited both before and after decompression, so that a small body can't
+// expand into more than maxDecompressedSize bytes.
+func newPutBody(w http.ResponseWriter, r *http.Request, maxSize int, maxDecompressedSize int) (*putBody, error) {
+	raw := newLimitedBody(w, r.Body, int64(maxSize), "request body")
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" {
+		return &putBody{limitedBody: raw, raw: raw}, nil
+	}
+
+	decompressor, err := newDecompressor(encoding, raw)
+	if err != nil {
+		raw.Close()
+		var pbcErr utils.PBCError
+		if errors.As(err, &pbcErr) {
+			return nil, err
+		}
+		return nil, utils.NewPBCError(utils.PUT_BAD_REQUEST, fmt.Sprintf("Invalid request body: %v", err))
+	}
+	decompressed := newLimitedBody(w, decompressedBody{Reader: decompressor, decompressor: decompressor, body: raw}, int64(maxDecompressedSize), "decompressed request body")
+	return &putBody{limitedBody: decompressed, raw: raw}, nil
+}
+
+// compressed tells whether the body came compressed
+func (b *putBody) compressed() bool {
+	return b.limitedBody != b.raw
+}
+
+// newDecompressor returns a reader of the decompressed body. "deflate" is meant to be the zlib
+// format, but some clients send raw deflate data, so both are accepted.
+func newDecompressor(encoding string, body io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		buffered := bufio.NewReader(body)
+		if header, err := buffered.Peek(2); err == nil && isZlibHeader(header) {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	}
+	return nil, utils.NewPBCError(utils.PUT_UNSUPPORTED_ENCODING, fmt.Sprintf("Content-Encoding must be one of [\"gzip\", \"deflate\", \"identity\"]. Found '%s'", encoding))
+}
+
+// isZlibHeader tells whether the two bytes are a zlib header of deflate compressed data, as
+// described in RFC 1950
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// decompressedBody closes both the decompressor and the request body it reads from
+type decompressedBody struct {
+	io.Reader
+	decompressor io.Closer
+	body         io.Closer
+}
+
+func (b decompressedBody) Close() error {
+	b.decompressor.Close()
+	return b.body.Close()
+}
@@ -0,0 +1,183 @@
+package endpoints
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testPutRequest = `{"puts":[{"type":"json","value":true},{"type":"xml","value":"<tag></tag>"}]}`
+
+func compress(t *testing.T, encoding string, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestPutCompressedBody(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		inContentEncoding    string
+		inBody               []byte
+		inMaxDecompressed    int
+		expectedStatus       int
+		expectedBody         string
+		expectedPutCalls     int
+		expectedSizesMetrics bool
+	}{
+		{
+			desc:                 "gzip",
+			inContentEncoding:    "gzip",
+			inBody:               compress(t, "gzip", testPutRequest),
+			inMaxDecompressed:    1024,
+			expectedStatus:       http.StatusOK,
+			expectedPutCalls:     2,
+			expectedSizesMetrics: true,
+		},
+		{
+			desc:                 "deflate in the zlib format",
+			inContentEncoding:    "Deflate",
+			inBody:               compress(t, "zlib", testPutRequest),
+			inMaxDecompressed:    1024,
+			expectedStatus:       http.StatusOK,
+			expectedPutCalls:     2,
+			expectedSizesMetrics: true,
+		},
+		{
+			desc:                 "raw deflate",
+			inContentEncoding:    "deflate",
+			inBody:               compress(t, "flate", testPutRequest),
+			inMaxDecompressed:    1024,
+			expectedStatus:       http.StatusOK,
+			expectedPutCalls:     2,
+			expectedSizesMetrics: true,
+		},
+		{
+			desc:              "identity",
+			inContentEncoding: "identity",
+			inBody:            []byte(testPutRequest),
+			inMaxDecompressed: 1024,
+			expectedStatus:    http.StatusOK,
+			expectedPutCalls:  2,
+		},
+		{
+			desc:                 "Decompressed body over the limit",
+			inContentEncoding:    "gzip",
+			inBody:               compress(t, "gzip", testPutRequest),
+			inMaxDecompressed:    32,
+			expectedStatus:       http.StatusRequestEntityTooLarge,
+			expectedBody:         "POST /cache decompressed request body exceeded max size: 32 bytes\n",
+			expectedSizesMetrics: true,
+		},
+		{
+			desc:                 "Corrupted gzip data",
+			inContentEncoding:    "gzip",
+			inBody:               append(compress(t, "gzip", testPutRequest)[:20], []byte("corrupted")...),
+			inMaxDecompressed:    1024,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBody:         "Invalid request body: unexpected EOF\n",
+			expectedSizesMetrics: true,
+		},
+		{
+			desc:              "Not gzip data",
+			inContentEncoding: "gzip",
+			inBody:            []byte(testPutRequest),
+			inMaxDecompressed: 1024,
+			expectedStatus:    http.StatusBadRequest,
+			expectedBody:      "Invalid request body: gzip: invalid header\n",
+		},
+		{
+			desc:              "Unsupported encoding",
+			inContentEncoding: "br",
+			inBody:            []byte(testPutRequest),
+			inMaxDecompressed: 1024,
+			expectedStatus:    http.StatusUnsupportedMediaType,
+			expectedBody:      "Content-Encoding must be one of [\"gzip\", \"deflate\", \"identity\"]. Found 'br'\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := &mockBackend{}
+		backend.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				mockMetrics,
+			},
+		}
+		router := httprouter.New()
+		router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, tc.inMaxDecompressed, false, config.Keys{}))
+
+		request := httptest.NewRequest("POST", "/cache", bytes.NewReader(tc.inBody))
+		request.Header.Set("Content-Encoding", tc.inContentEncoding)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, tc.expectedStatus, recorder.Code, tc.desc)
+		backend.AssertNumberOfCalls(t, "Put", tc.expectedPutCalls)
+
+		expectedMetrics := []string{"RecordPutTotal"}
+		if tc.expectedStatus == http.StatusOK {
+			expectedMetrics = append(expectedMetrics, "RecordPutDuration")
+		} else {
+			assert.Equal(t, tc.expectedBody, recorder.Body.String(), tc.desc)
+			expectedMetrics = append(expectedMetrics, "RecordPutBadRequest")
+		}
+		if tc.expectedSizesMetrics {
+			expectedMetrics = append(expectedMetrics, "RecordPutCompressedSize", "RecordPutDecompressedSize")
+		}
+		metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
+	}
+}
+
+func TestPutBodySizes(t *testing.T) {
+	compressed := compress(t, "gzip", testPutRequest)
+	request := httptest.NewRequest("POST", "/cache", bytes.NewReader(compressed))
+	request.Header.Set("Content-Encoding", "gzip")
+
+	body, err := newPutBody(httptest.NewRecorder(), request, 1024, 1024)
+	if !assert.NoError(t, err) {
+		return
+	}
+	decompressed, err := ioutil.ReadAll(body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testPutRequest, string(decompressed))
+	assert.True(t, body.compressed())
+	assert.Equal(t, int64(len(compressed)), body.raw.read)
+	assert.Equal(t, int64(len(testPutRequest)), body.read)
+	assert.NoError(t, body.Close())
+
+	request = httptest.NewRequest("POST", "/cache", strings.NewReader(testPutRequest))
+	body, err = newPutBody(httptest.NewRecorder(), request, 1024, 1024)
+
+	assert.NoError(t, err)
+	assert.False(t, body.compressed(), "A body without Content-Encoding isn't compressed")
+}
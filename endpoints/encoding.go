@@ -0,0 +1,102 @@
+package endpoints
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prebid/prebid-cache/config"
+)
+
+// negotiateEncoding picks the content coding of the response out of the ones the server supports,
+// listed in order of preference, and the ones the Accept-Encoding header of the request lists. An
+// empty string means the response shouldn't be encoded.
+func negotiateEncoding(r *http.Request, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	accepted := make(map[string]float64)
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for _, element := range strings.Split(header, ",") {
+			coding, q := parseAcceptEncoding(element)
+			if coding == "" {
+				continue
+			}
+			if coding == "x-gzip" {
+				coding = config.EncodingGzip
+			}
+			accepted[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range supported {
+		q, ok := accepted[encoding]
+		if !ok {
+			q = accepted["*"]
+		}
+		// Ties go to the encoding listed first
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// parseAcceptEncoding parses an element of the Accept-Encoding header, such as "gzip;q=0.8", into
+// its lower cased content coding and quality value. Elements without a valid quality value have a
+// quality of 1.
+func parseAcceptEncoding(element string) (string, float64) {
+	params := strings.Split(element, ";")
+	coding := strings.ToLower(strings.TrimSpace(params[0]))
+	q := 1.0
+	for _, param := range params[1:] {
+		name, value := param, ""
+		if i := strings.Index(param, "="); i >= 0 {
+			name, value = param[:i], param[i+1:]
+		}
+		if strings.ToLower(strings.TrimSpace(name)) != "q" {
+			continue
+		}
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && parsed >= 0 && parsed <= 1 {
+			q = parsed
+		}
+	}
+	return coding, q
+}
+
+// newEncoder returns a writer that encodes what's written to w with the content coding. The encoder
+// must be closed to flush the encoded data. gzip is the only content coding supported.
+func newEncoder(w io.Writer, encoding string) io.WriteCloser {
+	return gzip.NewWriter(w)
+}
+
+// entityTag returns a strong entity tag of the stored data as it's sent with the content coding.
+// Each encoding of the same data gets its own tag, because their bytes differ.
+func entityTag(storedData string, encoding string) string {
+	sum := sha256.Sum256([]byte(storedData))
+	tag := hex.EncodeToString(sum[:16])
+	if encoding != "" {
+		tag += "-" + encoding
+	}
+	return `"` + tag + `"`
+}
+
+// matchesETag tells whether the If-None-Match header of the request lists the entity tag. Tags are
+// compared with the weak comparison function of RFC 7232, as If-None-Match requires.
+func matchesETag(r *http.Request, etag string) bool {
+	for _, header := range r.Header.Values("If-None-Match") {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
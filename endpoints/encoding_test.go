@@ -0,0 +1,60 @@
+package endpoints
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		inSupported      []string
+		inAcceptEncoding []string
+		expectedEncoding string
+	}{
+		{
+			desc:             "Content codings are case insensitive",
+			inSupported:      []string{"gzip"},
+			inAcceptEncoding: []string{"GZIP"},
+			expectedEncoding: "gzip",
+		},
+		{
+			desc:             "x-gzip is an alias of gzip",
+			inSupported:      []string{"gzip"},
+			inAcceptEncoding: []string{"x-gzip"},
+			expectedEncoding: "gzip",
+		},
+		{
+			desc:             "Quality values across several headers",
+			inSupported:      []string{"gzip", "deflate"},
+			inAcceptEncoding: []string{"gzip; q=0.2", "deflate;Q=0.7"},
+			expectedEncoding: "deflate",
+		},
+		{
+			desc:             "Invalid quality values count as 1",
+			inSupported:      []string{"deflate", "gzip"},
+			inAcceptEncoding: []string{"gzip;q=2, deflate;q=0.5"},
+			expectedEncoding: "gzip",
+		},
+		{
+			desc:             "Everything refused",
+			inSupported:      []string{"gzip", "deflate"},
+			inAcceptEncoding: []string{"*;q=0"},
+		},
+		{
+			desc:             "Unsupported encodings only",
+			inSupported:      []string{"gzip"},
+			inAcceptEncoding: []string{"br, identity"},
+		},
+	}
+
+	for _, tc := range testCases {
+		request := httptest.NewRequest("GET", "/cache?uuid=key", nil)
+		for _, value := range tc.inAcceptEncoding {
+			request.Header.Add("Accept-Encoding", value)
+		}
+		assert.Equal(t, tc.expectedEncoding, negotiateEncoding(request, tc.inSupported), tc.desc)
+	}
+}
@@ -0,0 +1,84 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// noIndex is passed to writeError for the errors that don't come from an element of a put request
+const noIndex = -1
+
+// errorResponse is the body of the error responses written as JSON
+type errorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	Index     *int   `json:"index,omitempty"`
+}
+
+// putElementError tells which element of the "puts" array of a request an error comes from
+type putElementError struct {
+	index int
+	err   error
+}
+
+func (e putElementError) Error() string {
+	return e.err.Error()
+}
+
+func (e putElementError) Unwrap() error {
+	return e.err
+}
+
+// writeError replies with the error message and status code. The response is plain text, unless the
+// client accepts JSON or the server was configured to write errors as JSON. The JSON body adds the
+// code of the error, the request ID and, if index isn't noIndex, the index of the put element that
+// failed.
+func writeError(w http.ResponseWriter, r *http.Request, err error, msg string, statusCode int, index int) {
+	if !utils.JSONErrors(r.Context()) && !acceptsJSON(r) {
+		http.Error(w, msg, statusCode)
+		return
+	}
+
+	resp := errorResponse{
+		Code:      utils.ErrorCode(err),
+		Message:   msg,
+		RequestID: utils.RequestID(r.Context()),
+	}
+	if index != noIndex {
+		resp.Index = &index
+	}
+	body, marshalErr := json.Marshal(resp)
+	if marshalErr != nil {
+		http.Error(w, msg, statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
+// acceptsJSON tells whether the Accept header of the request explicitly lists application/json
+func acceptsJSON(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || mediaType != "application/json" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				// q=0 means the client doesn't want JSON
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteError(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		inAccept            string
+		inJSONErrors        bool
+		inError             error
+		inIndex             int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			desc:                "No Accept header, plain text",
+			inError:             utils.NewPBCError(utils.KEY_NOT_FOUND),
+			inIndex:             noIndex,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "GET /cache uuid=abc: Key not found\n",
+		},
+		{
+			desc:                "Client accepts JSON",
+			inAccept:            "text/html, application/json;q=0.9",
+			inError:             utils.NewPBCError(utils.KEY_NOT_FOUND),
+			inIndex:             noIndex,
+			expectedContentType: "application/json",
+			expectedBody:        `{"code":"KEY_NOT_FOUND","message":"GET /cache uuid=abc: Key not found","request_id":"req-1"}`,
+		},
+		{
+			desc:                "Client refuses JSON",
+			inAccept:            "application/json;q=0",
+			inError:             utils.NewPBCError(utils.KEY_NOT_FOUND),
+			inIndex:             noIndex,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "GET /cache uuid=abc: Key not found\n",
+		},
+		{
+			desc:                "Server configured to write JSON errors",
+			inJSONErrors:        true,
+			inError:             putElementError{index: 2, err: utils.NewPBCError(utils.NEGATIVE_TTL, "negative")},
+			inIndex:             2,
+			expectedContentType: "application/json",
+			expectedBody:        `{"code":"NEGATIVE_TTL","message":"GET /cache uuid=abc: Key not found","request_id":"req-1","index":2}`,
+		},
+		{
+			desc:                "Error that isn't a PBCError",
+			inAccept:            "application/json",
+			inError:             errors.New("unexpected"),
+			inIndex:             noIndex,
+			expectedContentType: "application/json",
+			expectedBody:        `{"code":"INTERNAL_ERROR","message":"GET /cache uuid=abc: Key not found","request_id":"req-1"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		request := httptest.NewRequest("GET", "/cache?uuid=abc", nil)
+		if tc.inAccept != "" {
+			request.Header.Set("Accept", tc.inAccept)
+		}
+		ctx := utils.WithRequestID(context.Background(), "req-1")
+		if tc.inJSONErrors {
+			ctx = utils.WithJSONErrors(ctx)
+		}
+		recorder := httptest.NewRecorder()
+
+		writeError(recorder, request.WithContext(ctx), tc.inError, "GET /cache uuid=abc: Key not found", http.StatusNotFound, tc.inIndex)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code, tc.desc)
+		assert.Equal(t, tc.expectedContentType, recorder.Header().Get("Content-Type"), tc.desc)
+		assert.Equal(t, tc.expectedBody, recorder.Body.String(), tc.desc)
+	}
+}
+
+func TestJSONErrorResponses(t *testing.T) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{mockMetrics}}
+	backend := backends.NewMemoryBackend()
+	router := httprouter.New()
+	router.GET("/cache", NewGetHandler(backend, m, false, nil, config.Keys{}, config.Peers{}))
+	router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, false, config.Keys{}))
+
+	testCases := []struct {
+		desc           string
+		inRequest      *http.Request
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "GET of a missing key",
+			inRequest:      httptest.NewRequest("GET", "/cache?uuid=fdd9405b-ef2b-46da-a55a-2f526d338e16", nil),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"code":"KEY_NOT_FOUND","message":"GET /cache uuid=fdd9405b-ef2b-46da-a55a-2f526d338e16: Key not found"}`,
+		},
+		{
+			desc:           "GET without a key",
+			inRequest:      httptest.NewRequest("GET", "/cache", nil),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"MISSING_KEY","message":"GET /cache: Missing required parameter uuid"}`,
+		},
+		{
+			desc:           "POST with an invalid second element",
+			inRequest:      httptest.NewRequest("POST", "/cache", strings.NewReader(`{"puts":[{"type":"json","value":true},{"type":"json","value":true,"ttlseconds":-1}]}`)),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"NEGATIVE_TTL","message":"ttlseconds must not be negative -1.","index":1}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc.inRequest.Header.Set("Accept", "application/json")
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, tc.inRequest)
+
+		assert.Equal(t, tc.expectedStatus, recorder.Code, tc.desc)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), tc.desc)
+		assert.JSONEq(t, tc.expectedBody, recorder.Body.String(), tc.desc)
+	}
+}
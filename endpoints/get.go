@@ -2,34 +2,47 @@ package endpoints
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/utils"
-	log "github.com/sirupsen/logrus"
 )
 
-// GetHandler serves "GET /cache" requests.
+// GetHandler serves "GET /cache" and "HEAD /cache" requests.
 type GetHandler struct {
-	backend         backends.Backend
-	metrics         *metrics.Metrics
-	allowCustomKeys bool
+	backend           backends.Backend
+	metrics           *metrics.Metrics
+	allowCustomKeys   bool
+	ids               utils.IDGenerator
+	responseEncodings []string
+	peers             *peerClient
+	now               func() time.Time
 }
 
-// NewGetHandler returns the handle function for the "/cache" endpoint when it receives a GET request
-func NewGetHandler(storage backends.Backend, metrics *metrics.Metrics, allowCustomKeys bool) func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+// NewGetHandler returns the handle function for the "/cache" endpoint when it receives a GET or a
+// HEAD request. Responses are compressed with the first of responseEncodings the client accepts.
+// Unless custom keys are allowed, requests for keys without the format set by keys are rejected.
+// Keys not found locally are looked up in the peer a request names, if peers allows it.
+func NewGetHandler(storage backends.Backend, metrics *metrics.Metrics, allowCustomKeys bool, responseEncodings []string, keys config.Keys, peers config.Peers) func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	getHandler := &GetHandler{
 		// Assign storage client to get endpoint
 		backend: storage,
 		// pass metrics engine
 		metrics: metrics,
-		// Pass configuration value
-		allowCustomKeys: allowCustomKeys,
+		// Pass configuration values
+		allowCustomKeys:   allowCustomKeys,
+		ids:               newIDGenerator(keys),
+		responseEncodings: responseEncodings,
+		peers:             newPeerClient(peers),
+		now:               time.Now,
 	}
 
 	// Return handle function
@@ -40,25 +53,36 @@ func (e *GetHandler) handle(w http.ResponseWriter, r *http.Request, ps httproute
 	e.metrics.RecordGetTotal()
 	start := time.Now()
 
-	uuid, parseErr := parseUUID(r, e.allowCustomKeys)
+	// Peers may generate keys of another format, so they're only checked by the peer
+	peerHost := r.URL.Query().Get("ch")
+	if e.peers == nil || !e.peers.allowed(peerHost) {
+		peerHost = ""
+	}
+
+	uuid, parseErr := parseUUID(r, e.allowCustomKeys || peerHost != "", e.ids)
 	if parseErr != nil {
 		// parseUUID either returns http.StatusBadRequest or http.StatusNotFound. Both should be
 		// accounted using RecordGetBadRequest()
-		e.handleException(w, uuid, parseErr)
+		e.handleException(w, r, uuid, parseErr)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(utils.DetachContext(r.Context()), 500*time.Millisecond)
 	defer cancel()
+	expiration := &utils.ValueExpiration{}
+	ctx = utils.WithValueExpiration(ctx, expiration)
 
 	storedData, err := e.backend.Get(ctx, uuid)
+	if peerHost != "" && errors.Is(err, utils.NewPBCError(utils.KEY_NOT_FOUND)) {
+		storedData, err = e.getFromPeer(ctx, peerHost, uuid, err)
+	}
 	if err != nil {
-		e.handleException(w, uuid, err)
+		e.handleException(w, r, uuid, err)
 		return
 	}
 
-	if err := writeGetResponse(w, storedData); err != nil {
-		e.handleException(w, uuid, err)
+	if err := e.writeGetResponse(w, r, storedData, expiration); err != nil {
+		e.handleException(w, r, uuid, err)
 		return
 	}
 
@@ -67,39 +91,104 @@ func (e *GetHandler) handle(w http.ResponseWriter, r *http.Request, ps httproute
 	return
 }
 
+// getFromPeer looks the key up in the peer at host. If the peer fails, the request gets notFoundErr,
+// the error of the local lookup.
+func (e *GetHandler) getFromPeer(ctx context.Context, host string, uuid string, notFoundErr error) (string, error) {
+	e.metrics.RecordGetPeerTotal()
+	start := time.Now()
+	storedData, expiresAt, err := e.peers.get(utils.DetachContext(ctx), host, uuid)
+	e.metrics.RecordGetPeerDuration(time.Since(start))
+
+	switch {
+	case err == nil:
+		e.metrics.RecordGetPeerHit()
+		if !expiresAt.IsZero() {
+			utils.RecordValueExpiration(ctx, expiresAt)
+		}
+		return storedData, nil
+	case errors.Is(err, utils.NewPBCError(utils.KEY_NOT_FOUND)):
+		return "", notFoundErr
+	default:
+		e.metrics.RecordGetPeerError()
+		utils.Logger(ctx).Errorf("GET /cache uuid=%s: peer %s failed: %s", uuid, host, err.Error())
+		return "", notFoundErr
+	}
+}
+
 // parseUUID extracts the uuid value from the query and validates its
-// lenght in case custom keys are not allowed.
-func parseUUID(r *http.Request, allowCustomKeys bool) (string, error) {
+// shape against the ID generator in case custom keys are not allowed.
+func parseUUID(r *http.Request, allowCustomKeys bool, ids utils.IDGenerator) (string, error) {
 	uuid := r.URL.Query().Get("uuid")
 	if uuid == "" {
 		return "", utils.NewPBCError(utils.MISSING_KEY)
 	}
-	// UUIDs are 36 characters long... so this quick check lets us filter out most invalid
-	// ones before even checking the backend.
-	if len(uuid) != 36 && (!allowCustomKeys) {
+	// Keys must have the configured prefix and format, UUIDs or ULIDs, so this quick check lets us
+	// filter out most invalid ones before even checking the backend.
+	if !allowCustomKeys && !ids.Valid(uuid) {
 		return uuid, utils.NewPBCError(utils.KEY_LENGTH)
 	}
 	return uuid, nil
 }
 
 // writeGetResponse writes the "Content-Type" header and sends back the stored data as a response if
-// the sotred data is prefixed by either the "xml" or "json"
-func writeGetResponse(w http.ResponseWriter, storedData string) error {
+// the sotred data is prefixed by either the "xml" or "json". The response is compressed if the client
+// accepts one of the configured encodings, and is cacheable for as long as the value has left to live.
+// Requests whose If-None-Match header lists the entity tag of the response get a 304 without a body.
+// HEAD requests get the same headers as a GET, without the body.
+func (e *GetHandler) writeGetResponse(w http.ResponseWriter, r *http.Request, storedData string, expiration *utils.ValueExpiration) error {
+	var contentType, value string
 	if strings.HasPrefix(storedData, utils.XML_PREFIX) {
-		w.Header().Set("Content-Type", "application/xml")
-		w.Write([]byte(storedData)[len(utils.XML_PREFIX):])
+		contentType, value = "application/xml", storedData[len(utils.XML_PREFIX):]
 	} else if strings.HasPrefix(storedData, utils.JSON_PREFIX) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(storedData)[len(utils.JSON_PREFIX):])
+		contentType, value = "application/json", storedData[len(utils.JSON_PREFIX):]
 	} else {
 		return utils.NewPBCError(utils.UNKNOWN_STORED_DATA_TYPE)
 	}
+
+	encoding := negotiateEncoding(r, e.responseEncodings)
+	etag := entityTag(storedData, encoding)
+	w.Header().Set("ETag", etag)
+	if len(e.responseEncodings) > 0 {
+		w.Header().Set("Vary", "Accept-Encoding")
+	}
+	if expiresAt, ok := expiration.ExpiresAt(); ok {
+		remaining := int64(expiresAt.Sub(e.now()) / time.Second)
+		if remaining < 0 {
+			remaining = 0
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", remaining))
+		w.Header().Set("Expires", expiresAt.UTC().Format(http.TimeFormat))
+		w.Header().Set("X-Cache-TTL-Remaining", strconv.FormatInt(remaining, 10))
+	}
+
+	if matchesETag(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if encoding == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(len(value)))
+		if r.Method != http.MethodHead {
+			w.Write([]byte(value))
+		}
+		return nil
+	}
+
+	// The length of an encoded response isn't known until it's encoded
+	w.Header().Set("Content-Encoding", encoding)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	encoder := newEncoder(w, encoding)
+	encoder.Write([]byte(value))
+	encoder.Close()
 	return nil
 }
 
 // handleException logs the error message, updates the error metrics based on error type and replies
-// back with the error message and an HTTP error code
-func (e *GetHandler) handleException(w http.ResponseWriter, uuid string, err error) {
+// back with the error message and an HTTP error code, as plain text or JSON
+func (e *GetHandler) handleException(w http.ResponseWriter, r *http.Request, uuid string, err error) {
 	if err != nil {
 		// Prefix error message with "GET /cache " or "GET /cache uuid=..."
 		errMsgBuilder := strings.Builder{}
@@ -113,7 +202,8 @@ func (e *GetHandler) handleException(w http.ResponseWriter, uuid string, err err
 		// Determine the response status code based on error type
 		errCode := http.StatusInternalServerError
 		isKeyNotFound := false
-		if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
+		var pbcErr utils.PBCError
+		if errors.As(err, &pbcErr) {
 			errCode = pbcErr.StatusCode
 			isKeyNotFound = pbcErr.Type == utils.KEY_NOT_FOUND
 		}
@@ -128,12 +218,12 @@ func (e *GetHandler) handleException(w http.ResponseWriter, uuid string, err err
 
 		// Determine log level
 		if isKeyNotFound {
-			log.Debug(errMsg)
+			utils.Logger(r.Context()).Debug(errMsg)
 		} else {
-			log.Error(errMsg)
+			utils.Logger(r.Context()).Error(errMsg)
 		}
 
 		// Write error response
-		http.Error(w, errMsg, errCode)
+		writeError(w, r, err, errMsg, errCode, noIndex)
 	}
 }
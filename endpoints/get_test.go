@@ -1,16 +1,28 @@
 package endpoints
 
 import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/tracing"
+	"github.com/prebid/prebid-cache/tracing/tracingtest"
+	"github.com/prebid/prebid-cache/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetInvalidUUIDs(t *testing.T) {
@@ -20,11 +32,11 @@ func TestGetInvalidUUIDs(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 
-	router.GET("/cache", NewGetHandler(backend, m, false))
+	router.GET("/cache", NewGetHandler(backend, m, false, nil, config.Keys{}, config.Peers{}))
 
 	getResults := doMockGet(t, router, "fdd9405b-ef2b-46da-a55a-2f526d338e16")
 	if getResults.Code != http.StatusNotFound {
@@ -39,6 +51,71 @@ func TestGetInvalidUUIDs(t *testing.T) {
 	}
 }
 
+func TestGetKeyFormats(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		inKeys       config.Keys
+		inAllowKeys  bool
+		inUUID       string
+		expectedCode int
+	}{
+		{
+			desc:         "ULID",
+			inKeys:       config.Keys{Format: config.IDFormatULID},
+			inUUID:       "01ARYZ6S41TSV4RRFFQ69G5FAV",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "UUID where ULIDs are expected",
+			inKeys:       config.Keys{Format: config.IDFormatULID},
+			inUUID:       "fdd9405b-ef2b-46da-a55a-2f526d338e16",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "UUID where ULIDs are expected, with custom keys allowed",
+			inKeys:       config.Keys{Format: config.IDFormatULID},
+			inAllowKeys:  true,
+			inUUID:       "fdd9405b-ef2b-46da-a55a-2f526d338e16",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "Version 4 UUID where version 7 UUIDs are expected",
+			inKeys:       config.Keys{Format: config.IDFormatUUIDv7},
+			inUUID:       "fdd9405b-ef2b-46da-a55a-2f526d338e16",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "Prefixed UUID",
+			inKeys:       config.Keys{Format: config.IDFormatUUIDv4, Prefix: "use1-"},
+			inUUID:       "use1-fdd9405b-ef2b-46da-a55a-2f526d338e16",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "UUID without the prefix",
+			inKeys:       config.Keys{Format: config.IDFormatUUIDv4, Prefix: "use1-"},
+			inUUID:       "fdd9405b-ef2b-46da-a55a-2f526d338e16",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := backends.NewMemoryBackend()
+		backend.Put(context.Background(), tc.inUUID, `xml<VAST/>`, 0)
+
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{mockMetrics}}
+		router := httprouter.New()
+		router.GET("/cache", NewGetHandler(backend, m, tc.inAllowKeys, nil, tc.inKeys, config.Peers{}))
+
+		getResults := doMockGet(t, router, tc.inUUID)
+
+		assert.Equal(t, tc.expectedCode, getResults.Code, tc.desc)
+		if tc.expectedCode == http.StatusNotFound {
+			assert.Equal(t, "GET /cache uuid="+tc.inUUID+": invalid uuid length\n", getResults.Body.String(), tc.desc)
+		}
+	}
+}
+
 func TestGetHandler(t *testing.T) {
 	type logEntry struct {
 		msg string
@@ -189,10 +266,10 @@ func TestGetHandler(t *testing.T) {
 		mockMetrics := metricstest.CreateMockMetrics()
 		m := &metrics.Metrics{
 			MetricEngines: []metrics.CacheMetrics{
-				&mockMetrics,
+				mockMetrics,
 			},
 		}
-		router.GET("/cache", NewGetHandler(backend, m, test.in.allowKeys))
+		router.GET("/cache", NewGetHandler(backend, m, test.in.allowKeys, nil, config.Keys{}, config.Peers{}))
 
 		// Run test
 		getResults := doMockGet(t, router, test.in.uuid)
@@ -218,3 +295,495 @@ func TestGetHandler(t *testing.T) {
 		hook.Reset()
 	}
 }
+
+func TestGetResponseEncoding(t *testing.T) {
+	const storedValue = `json{"field":"value"}`
+
+	testCases := []struct {
+		desc             string
+		inEncodings      []string
+		inAcceptEncoding string
+		expectedEncoding string
+	}{
+		{
+			desc:             "Client accepts gzip",
+			inEncodings:      []string{"gzip"},
+			inAcceptEncoding: "gzip, deflate",
+			expectedEncoding: "gzip",
+		},
+		{
+			desc:             "Client accepts any encoding",
+			inEncodings:      []string{"gzip"},
+			inAcceptEncoding: "*",
+			expectedEncoding: "gzip",
+		},
+		{
+			desc:             "Client refuses gzip",
+			inEncodings:      []string{"gzip"},
+			inAcceptEncoding: "gzip;q=0, *",
+		},
+		{
+			desc:        "Client doesn't accept encodings",
+			inEncodings: []string{"gzip"},
+		},
+		{
+			desc:             "Server doesn't compress responses",
+			inAcceptEncoding: "gzip",
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := &mockBackend{}
+		backend.On("Get", mock.Anything, "key").Return(storedValue, nil)
+
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{mockMetrics}}
+		router := httprouter.New()
+		router.GET("/cache", NewGetHandler(backend, m, true, tc.inEncodings, config.Keys{}, config.Peers{}))
+
+		request := httptest.NewRequest("GET", "/cache?uuid=key", nil)
+		if tc.inAcceptEncoding != "" {
+			request.Header.Set("Accept-Encoding", tc.inAcceptEncoding)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, tc.desc)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), tc.desc)
+		assert.Equal(t, tc.expectedEncoding, recorder.Header().Get("Content-Encoding"), tc.desc)
+		assert.Equal(t, entityTag(storedValue, tc.expectedEncoding), recorder.Header().Get("ETag"), tc.desc)
+		if len(tc.inEncodings) > 0 {
+			assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"), tc.desc)
+		} else {
+			assert.Empty(t, recorder.Header().Get("Vary"), tc.desc)
+		}
+
+		var body io.Reader = recorder.Body
+		if tc.expectedEncoding == "gzip" {
+			gzipReader, err := gzip.NewReader(body)
+			if !assert.NoError(t, err, tc.desc) {
+				continue
+			}
+			body = gzipReader
+		}
+		decoded, err := ioutil.ReadAll(body)
+		assert.NoError(t, err, tc.desc)
+		assert.Equal(t, `{"field":"value"}`, string(decoded), tc.desc)
+
+		metricstest.AssertMetrics(t, []string{"RecordGetTotal", "RecordGetDuration"}, mockMetrics)
+	}
+}
+
+func TestGetConditionalRequest(t *testing.T) {
+	const storedValue = "xml<tag></tag>"
+	etag := entityTag(storedValue, "")
+
+	testCases := []struct {
+		desc           string
+		inIfNoneMatch  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "Matching entity tag",
+			inIfNoneMatch:  etag,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			desc:           "Weak matching entity tag among others",
+			inIfNoneMatch:  `"other", W/` + etag,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			desc:           "Any entity tag",
+			inIfNoneMatch:  "*",
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			desc:           "Entity tag of another encoding",
+			inIfNoneMatch:  entityTag(storedValue, "gzip"),
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<tag></tag>",
+		},
+		{
+			desc:           "No entity tag",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<tag></tag>",
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := &mockBackend{}
+		backend.On("Get", mock.Anything, "key").Return(storedValue, nil)
+
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{mockMetrics}}
+		router := httprouter.New()
+		router.GET("/cache", NewGetHandler(backend, m, true, []string{"gzip"}, config.Keys{}, config.Peers{}))
+
+		request := httptest.NewRequest("GET", "/cache?uuid=key", nil)
+		if tc.inIfNoneMatch != "" {
+			request.Header.Set("If-None-Match", tc.inIfNoneMatch)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, tc.expectedStatus, recorder.Code, tc.desc)
+		assert.Equal(t, tc.expectedBody, recorder.Body.String(), tc.desc)
+		assert.Equal(t, etag, recorder.Header().Get("ETag"), tc.desc)
+		metricstest.AssertMetrics(t, []string{"RecordGetTotal", "RecordGetDuration"}, mockMetrics)
+	}
+}
+
+func TestGetFromPeer(t *testing.T) {
+	const localKey = "fdd9405b-ef2b-46da-a55a-2f526d338e16"
+	const peerKey = "euw1-01ARYZ6S41TSV4RRFFQ69G5FAV"
+
+	// A Prebid Cache cluster of another region, which generates prefixed ULIDs
+	peerBackend := backends.NewMemoryBackend()
+	peerBackend.Put(context.Background(), peerKey, "xml<VAST/>", 0)
+	peerRouter := httprouter.New()
+	peerRouter.GET("/cache", NewGetHandler(peerBackend, &metrics.Metrics{}, false, nil, config.Keys{Format: config.IDFormatULID, Prefix: "euw1-"}, config.Peers{}))
+
+	testCases := []struct {
+		desc              string
+		inPeer            http.Handler
+		inCh              string
+		inUUID            string
+		inLocalValue      bool
+		expectedCode      int
+		expectedBody      string
+		expectedPeerCalls int
+		expectedMetrics   []string
+	}{
+		{
+			desc:              "Value found by the peer, under a key of its own format",
+			inPeer:            peerRouter,
+			inUUID:            peerKey,
+			expectedCode:      http.StatusOK,
+			expectedBody:      "<VAST/>",
+			expectedPeerCalls: 1,
+			expectedMetrics:   []string{"RecordGetTotal", "RecordGetDuration", "RecordGetPeerTotal", "RecordGetPeerDuration", "RecordGetPeerHit"},
+		},
+		{
+			desc:              "Value found by neither",
+			inPeer:            peerRouter,
+			inUUID:            "euw1-01ARYZ6S41TSV4RRFFQ69G5FAW",
+			expectedCode:      http.StatusNotFound,
+			expectedBody:      "GET /cache uuid=euw1-01ARYZ6S41TSV4RRFFQ69G5FAW: Key not found\n",
+			expectedPeerCalls: 1,
+			expectedMetrics:   []string{"RecordGetTotal", "RecordGetBadRequest", "RecordGetPeerTotal", "RecordGetPeerDuration"},
+		},
+		{
+			desc:            "Value found locally",
+			inPeer:          peerRouter,
+			inUUID:          localKey,
+			inLocalValue:    true,
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"local":true}`,
+			expectedMetrics: []string{"RecordGetTotal", "RecordGetDuration"},
+		},
+		{
+			desc:            "Peer not allowed",
+			inPeer:          peerRouter,
+			inCh:            "cache.attacker.example.com",
+			inUUID:          localKey,
+			expectedCode:    http.StatusNotFound,
+			expectedBody:    "GET /cache uuid=" + localKey + ": Key not found\n",
+			expectedMetrics: []string{"RecordGetTotal", "RecordGetBadRequest"},
+		},
+		{
+			desc:            "Key of another format without an allowed peer",
+			inPeer:          peerRouter,
+			inCh:            "cache.attacker.example.com",
+			inUUID:          peerKey,
+			expectedCode:    http.StatusNotFound,
+			expectedBody:    "GET /cache uuid=" + peerKey + ": invalid uuid length\n",
+			expectedMetrics: []string{"RecordGetTotal", "RecordGetBadRequest"},
+		},
+		{
+			desc: "Peer too slow",
+			inPeer: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			}),
+			inUUID:            localKey,
+			expectedCode:      http.StatusNotFound,
+			expectedBody:      "GET /cache uuid=" + localKey + ": Key not found\n",
+			expectedPeerCalls: 1,
+			expectedMetrics:   []string{"RecordGetTotal", "RecordGetBadRequest", "RecordGetPeerTotal", "RecordGetPeerDuration", "RecordGetPeerError"},
+		},
+		{
+			desc: "Peer value larger than allowed",
+			inPeer: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/xml")
+				w.Write([]byte("<VAST>" + strings.Repeat(" ", 1024) + "</VAST>"))
+			}),
+			inUUID:            localKey,
+			expectedCode:      http.StatusNotFound,
+			expectedBody:      "GET /cache uuid=" + localKey + ": Key not found\n",
+			expectedPeerCalls: 1,
+			expectedMetrics:   []string{"RecordGetTotal", "RecordGetBadRequest", "RecordGetPeerTotal", "RecordGetPeerDuration", "RecordGetPeerError"},
+		},
+		{
+			desc: "Peer error",
+			inPeer: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}),
+			inUUID:            localKey,
+			expectedCode:      http.StatusNotFound,
+			expectedBody:      "GET /cache uuid=" + localKey + ": Key not found\n",
+			expectedPeerCalls: 1,
+			expectedMetrics:   []string{"RecordGetTotal", "RecordGetBadRequest", "RecordGetPeerTotal", "RecordGetPeerDuration", "RecordGetPeerError"},
+		},
+		{
+			desc: "Peer redirects are not followed",
+			inPeer: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/cache?uuid=other", http.StatusFound)
+			}),
+			inUUID:            localKey,
+			expectedCode:      http.StatusNotFound,
+			expectedBody:      "GET /cache uuid=" + localKey + ": Key not found\n",
+			expectedPeerCalls: 1,
+			expectedMetrics:   []string{"RecordGetTotal", "RecordGetBadRequest", "RecordGetPeerTotal", "RecordGetPeerDuration", "RecordGetPeerError"},
+		},
+		{
+			desc: "Peer value of an unknown type",
+			inPeer: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.Write([]byte("<html/>"))
+			}),
+			inUUID:            localKey,
+			expectedCode:      http.StatusNotFound,
+			expectedBody:      "GET /cache uuid=" + localKey + ": Key not found\n",
+			expectedPeerCalls: 1,
+			expectedMetrics:   []string{"RecordGetTotal", "RecordGetBadRequest", "RecordGetPeerTotal", "RecordGetPeerDuration", "RecordGetPeerError"},
+		},
+	}
+
+	for _, tc := range testCases {
+		peerCalls := 0
+		peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peerCalls++
+			assert.Empty(t, r.URL.Query().Get("ch"), tc.desc+": requests must not be forwarded any further")
+			tc.inPeer.ServeHTTP(w, r)
+		}))
+		peerHost := strings.TrimPrefix(peer.URL, "http://")
+
+		backend := backends.NewMemoryBackend()
+		if tc.inLocalValue {
+			backend.Put(context.Background(), tc.inUUID, `json{"local":true}`, 0)
+		}
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{mockMetrics}}
+		peers := config.Peers{AllowedHosts: []string{peerHost}, Scheme: "http", TimeoutMillis: 50, MaxResponseSizeBytes: 1024}
+		router := httprouter.New()
+		router.GET("/cache", NewGetHandler(backend, m, false, nil, config.Keys{}, peers))
+
+		ch := tc.inCh
+		if ch == "" {
+			ch = peerHost
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/cache?uuid="+tc.inUUID+"&ch="+ch, nil))
+		peer.Close()
+
+		assert.Equal(t, tc.expectedCode, recorder.Code, tc.desc)
+		assert.Equal(t, tc.expectedBody, recorder.Body.String(), tc.desc)
+		assert.Equal(t, tc.expectedPeerCalls, peerCalls, tc.desc)
+		metricstest.AssertMetrics(t, tc.expectedMetrics, mockMetrics)
+	}
+}
+
+func TestGetFromPeerExpiration(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Cache-TTL-Remaining", "120")
+		w.Write([]byte(`{"field":"value"}`))
+	}))
+	defer peer.Close()
+	peerHost := strings.ToUpper(strings.TrimPrefix(peer.URL, "http://"))
+
+	now := time.Now()
+	handler := &GetHandler{
+		backend: backends.NewMemoryBackend(),
+		metrics: &metrics.Metrics{},
+		ids:     newIDGenerator(config.Keys{}),
+		peers:   newPeerClient(config.Peers{AllowedHosts: []string{strings.ToLower(peerHost)}, Scheme: "http", TimeoutMillis: 1000, MaxResponseSizeBytes: 1024}),
+		now:     func() time.Time { return now },
+	}
+	router := httprouter.New()
+	router.GET("/cache", handler.handle)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/cache?uuid=fdd9405b-ef2b-46da-a55a-2f526d338e16&ch="+peerHost, nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, "Peer hosts are case insensitive")
+	assert.Equal(t, `{"field":"value"}`, recorder.Body.String())
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Regexp(t, "^max-age=1(19|20)$", recorder.Header().Get("Cache-Control"), "The value lives as long as the peer said")
+}
+
+func TestGetFromPeerTracing(t *testing.T) {
+	var receivedTraceparent string
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedTraceparent = r.Header.Get(tracing.TraceparentHeader)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte("<VAST/>"))
+	}))
+	defer peer.Close()
+	peerHost := strings.TrimPrefix(peer.URL, "http://")
+
+	tracer, exporter := tracingtest.NewTracer()
+	ctx, requestSpan := tracer.StartRequestSpan(context.Background(), "GET /cache", tracing.SpanContext{})
+	peers := newPeerClient(config.Peers{AllowedHosts: []string{peerHost}, Scheme: "http", TimeoutMillis: 1000, MaxResponseSizeBytes: 1024})
+
+	value, _, err := peers.get(ctx, peerHost, "fdd9405b-ef2b-46da-a55a-2f526d338e16")
+	requestSpan.End()
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "xml<VAST/>", value)
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	peerSpan := spans[0]
+	assert.Equal(t, "peer.get", peerSpan.Name)
+	assert.Equal(t, tracing.SpanKindClient, peerSpan.Kind)
+	assert.Equal(t, requestSpan.SpanContext.SpanID, peerSpan.ParentSpanID)
+	assert.Equal(t, peerHost, peerSpan.Attributes["peer.host"])
+	assert.Equal(t, true, peerSpan.Attributes["cache.hit"])
+	assert.Equal(t, peerSpan.SpanContext.Traceparent(), receivedTraceparent, "The peer joins the trace as a child of the peer span")
+}
+
+func TestGetCacheControl(t *testing.T) {
+	now := time.Date(2022, 5, 4, 10, 11, 12, 0, time.UTC)
+
+	testCases := []struct {
+		desc                 string
+		inExpiresAt          time.Time
+		expectedCacheControl string
+		expectedExpires      string
+		expectedTTLRemaining string
+	}{
+		{
+			desc:                 "Value expires in a minute and a half",
+			inExpiresAt:          now.Add(90*time.Second + 500*time.Millisecond),
+			expectedCacheControl: "max-age=90",
+			expectedExpires:      "Wed, 04 May 2022 10:12:42 GMT",
+			expectedTTLRemaining: "90",
+		},
+		{
+			desc:                 "Value is past its expiry",
+			inExpiresAt:          now.Add(-time.Second),
+			expectedCacheControl: "max-age=0",
+			expectedExpires:      "Wed, 04 May 2022 10:11:11 GMT",
+			expectedTTLRemaining: "0",
+		},
+		{
+			desc: "Backend doesn't know when the value expires",
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := &mockBackend{}
+		expiresAt := tc.inExpiresAt
+		backend.On("Get", mock.Anything, "key").Return("json{}", nil).Run(func(args mock.Arguments) {
+			if !expiresAt.IsZero() {
+				utils.RecordValueExpiration(args.Get(0).(context.Context), expiresAt)
+			}
+		})
+
+		mockMetrics := metricstest.CreateMockMetrics()
+		handler := &GetHandler{
+			backend:         backend,
+			metrics:         &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{mockMetrics}},
+			allowCustomKeys: true,
+			now:             func() time.Time { return now },
+		}
+		router := httprouter.New()
+		router.GET("/cache", handler.handle)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/cache?uuid=key", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code, tc.desc)
+		assert.Equal(t, tc.expectedCacheControl, recorder.Header().Get("Cache-Control"), tc.desc)
+		assert.Equal(t, tc.expectedExpires, recorder.Header().Get("Expires"), tc.desc)
+		assert.Equal(t, tc.expectedTTLRemaining, recorder.Header().Get("X-Cache-TTL-Remaining"), tc.desc)
+	}
+}
+
+func TestHeadRequest(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		inUUID                string
+		inAcceptEncoding      string
+		expectedStatus        int
+		expectedContentLength string
+		expectedEncoding      string
+		expectedMetrics       []string
+	}{
+		{
+			desc:                  "Stored value",
+			inUUID:                "key",
+			expectedStatus:        http.StatusOK,
+			expectedContentLength: "17",
+			expectedMetrics:       []string{"RecordGetTotal", "RecordGetDuration"},
+		},
+		{
+			desc:             "Stored value the client wants compressed",
+			inUUID:           "key",
+			inAcceptEncoding: "gzip",
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: "gzip",
+			expectedMetrics:  []string{"RecordGetTotal", "RecordGetDuration"},
+		},
+		{
+			desc:            "Missing value",
+			inUUID:          "missing",
+			expectedStatus:  http.StatusNotFound,
+			expectedMetrics: []string{"RecordGetTotal", "RecordGetBadRequest"},
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := &mockBackend{}
+		backend.On("Get", mock.Anything, "key").Return(`json{"field":"value"}`, nil)
+		backend.On("Get", mock.Anything, "missing").Return("", utils.NewPBCError(utils.KEY_NOT_FOUND))
+
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{mockMetrics}}
+		router := httprouter.New()
+		router.HEAD("/cache", NewGetHandler(backend, m, true, []string{"gzip"}, config.Keys{}, config.Peers{}))
+
+		request := httptest.NewRequest("HEAD", "/cache?uuid="+tc.inUUID, nil)
+		if tc.inAcceptEncoding != "" {
+			request.Header.Set("Accept-Encoding", tc.inAcceptEncoding)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, tc.expectedStatus, recorder.Code, tc.desc)
+		if tc.expectedStatus == http.StatusOK {
+			assert.Empty(t, recorder.Body.String(), tc.desc)
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), tc.desc)
+			assert.Equal(t, tc.expectedContentLength, recorder.Header().Get("Content-Length"), tc.desc)
+			assert.Equal(t, tc.expectedEncoding, recorder.Header().Get("Content-Encoding"), tc.desc)
+		}
+		metricstest.AssertMetrics(t, tc.expectedMetrics, mockMetrics)
+	}
+}
+
+func TestNewPeerClient(t *testing.T) {
+	assert.Nil(t, newPeerClient(config.Peers{}), "No client without allowed peers")
+
+	peers := newPeerClient(config.Peers{AllowedHosts: []string{"cache.example.com", "cache.example.org"}})
+	transport, ok := peers.client.Transport.(*http.Transport)
+	if !assert.True(t, ok, "Peers have a transport of their own") {
+		return
+	}
+	assert.NotSame(t, http.DefaultTransport, transport)
+	assert.Equal(t, peerMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 2*peerMaxIdleConnsPerHost, transport.MaxIdleConns)
+}
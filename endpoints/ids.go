@@ -0,0 +1,21 @@
+package endpoints
+
+import (
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// newIDGenerator returns the generator of the keys of the values put without their own key, which
+// the keys of get requests are also validated against
+func newIDGenerator(cfg config.Keys) utils.IDGenerator {
+	var generator utils.IDGenerator
+	switch cfg.Format {
+	case config.IDFormatUUIDv7:
+		generator = utils.NewUUIDv7Generator()
+	case config.IDFormatULID:
+		generator = utils.NewULIDGenerator()
+	default:
+		generator = utils.NewUUIDv4Generator()
+	}
+	return utils.WithIDPrefix(generator, cfg.Prefix)
+}
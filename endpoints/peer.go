@@ -0,0 +1,144 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/tracing"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// peerMaxIdleConnsPerHost is how many idle connections are kept open to each peer. Every get
+// request naming a peer may call it, so the default of 2 would make most calls open a new
+// connection under load.
+const peerMaxIdleConnsPerHost = 100
+
+// peerClient reads values from the other Prebid Cache clusters that get requests can name in their
+// "ch" parameter, when their key isn't found locally
+type peerClient struct {
+	allowedHosts    map[string]bool
+	scheme          string
+	timeout         time.Duration
+	maxResponseSize int64
+	client          *http.Client
+}
+
+// newPeerClient returns a client of the peers of cfg, or nil if no peer is allowed
+func newPeerClient(cfg config.Peers) *peerClient {
+	if len(cfg.AllowedHosts) == 0 {
+		return nil
+	}
+
+	allowedHosts := make(map[string]bool, len(cfg.AllowedHosts))
+	for _, host := range cfg.AllowedHosts {
+		allowedHosts[strings.ToLower(host)] = true
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = peerMaxIdleConnsPerHost * len(allowedHosts)
+	transport.MaxIdleConnsPerHost = peerMaxIdleConnsPerHost
+	return &peerClient{
+		allowedHosts:    allowedHosts,
+		scheme:          cfg.Scheme,
+		timeout:         cfg.Timeout(),
+		maxResponseSize: int64(cfg.MaxResponseSizeBytes),
+		client: &http.Client{
+			Transport: transport,
+			// A redirect could lead to a host that isn't allowed
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// allowed tells whether requests can be forwarded to host. Host names are case insensitive.
+func (c *peerClient) allowed(host string) bool {
+	return c.allowedHosts[strings.ToLower(host)]
+}
+
+// get asks the peer at host for the value under key. The value is returned prefixed by its type,
+// as backends store it, along with the time it expires at if the peer sent it. Returns a
+// KEY_NOT_FOUND error if the peer doesn't have the value either. The peer isn't passed the "ch"
+// parameter, so the request can't be forwarded any further.
+func (c *peerClient) get(ctx context.Context, host string, key string) (string, time.Time, error) {
+	ctx, span := tracing.StartSpan(ctx, "peer.get", tracing.SpanKindClient)
+	defer span.End()
+	span.SetAttribute("peer.host", host)
+
+	value, expiresAt, err := c.fetch(ctx, host, key)
+	if err != nil {
+		if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr && pbcErr.Type == utils.KEY_NOT_FOUND {
+			span.SetAttribute("cache.hit", false)
+		} else {
+			span.SetError(err)
+		}
+		return value, expiresAt, err
+	}
+	span.SetAttribute("cache.hit", true)
+	span.SetAttribute("cache.value_bytes", len(value))
+	return value, expiresAt, nil
+}
+
+// fetch sends the request of get to the peer, passing along the request ID and the trace context
+func (c *peerClient) fetch(ctx context.Context, host string, key string) (string, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	peerURL := url.URL{Scheme: c.scheme, Host: host, Path: "/cache", RawQuery: url.Values{"uuid": {key}}.Encode()}
+	request, err := http.NewRequest(http.MethodGet, peerURL.String(), nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if requestID := utils.RequestID(ctx); requestID != "" {
+		request.Header.Set(utils.RequestIDHeader, requestID)
+	}
+	tracing.InjectHeaders(ctx, request.Header)
+
+	response, err := c.client.Do(request.WithContext(ctx))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		io.Copy(ioutil.Discard, io.LimitReader(response.Body, c.maxResponseSize))
+		return "", time.Time{}, utils.NewPBCError(utils.KEY_NOT_FOUND)
+	default:
+		return "", time.Time{}, fmt.Errorf("peer %s answered with status %d", host, response.StatusCode)
+	}
+
+	var prefix string
+	switch contentType := response.Header.Get("Content-Type"); {
+	case strings.HasPrefix(contentType, "application/xml"):
+		prefix = utils.XML_PREFIX
+	case strings.HasPrefix(contentType, "application/json"):
+		prefix = utils.JSON_PREFIX
+	default:
+		return "", time.Time{}, fmt.Errorf("peer %s answered with an unknown content type: %q", host, contentType)
+	}
+
+	// Read one byte past the limit to tell a value of the maximum size from a larger one
+	value, err := ioutil.ReadAll(io.LimitReader(response.Body, c.maxResponseSize+1))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if int64(len(value)) > c.maxResponseSize {
+		return "", time.Time{}, fmt.Errorf("peer %s answered with a value larger than %d bytes", host, c.maxResponseSize)
+	}
+
+	var expiresAt time.Time
+	if remaining, err := strconv.Atoi(response.Header.Get("X-Cache-TTL-Remaining")); err == nil && remaining >= 0 {
+		expiresAt = time.Now().Add(time.Duration(remaining) * time.Second)
+	}
+	return prefix + string(value), expiresAt, nil
+}
@@ -3,18 +3,20 @@ package endpoints
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/prebid/prebid-cache/backends"
 	backendDecorators "github.com/prebid/prebid-cache/backends/decorators"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/utils"
-	"github.com/sirupsen/logrus"
 )
 
 // PutHandler serves "POST /cache" requests.
@@ -23,11 +25,15 @@ type PutHandler struct {
 	cfg     putHandlerConfig
 	memory  syncPools
 	metrics *metrics.Metrics
+	ids     utils.IDGenerator
 }
 
 type putHandlerConfig struct {
-	maxNumValues int
-	allowKeys    bool
+	maxNumValues            int
+	maxBodySize             int
+	maxDecompressedBodySize int
+	allowKeys               bool
+	keys                    config.Keys
 }
 
 type syncPools struct {
@@ -35,8 +41,9 @@ type syncPools struct {
 	putResponsePool sync.Pool
 }
 
-// NewPutHandler returns the handle function for the "/cache" endpoint when it receives a POST request
-func NewPutHandler(storage backends.Backend, metrics *metrics.Metrics, maxNumValues int, allowKeys bool) func(http.ResponseWriter, *http.Request, httprouter.Params) {
+// NewPutHandler returns the handle function for the "/cache" endpoint when it receives a POST request.
+// keys controls the keys of the values put without their own key.
+func NewPutHandler(storage backends.Backend, metrics *metrics.Metrics, maxNumValues int, maxBodySize int, maxDecompressedBodySize int, allowKeys bool, keys config.Keys) func(http.ResponseWriter, *http.Request, httprouter.Params) {
 	putHandler := &PutHandler{}
 
 	// Assign storage client to put endpoint
@@ -47,9 +54,13 @@ func NewPutHandler(storage backends.Backend, metrics *metrics.Metrics, maxNumVal
 
 	// Pass configuration values
 	putHandler.cfg = putHandlerConfig{
-		maxNumValues: maxNumValues,
-		allowKeys:    allowKeys,
+		maxNumValues:            maxNumValues,
+		maxBodySize:             maxBodySize,
+		maxDecompressedBodySize: maxDecompressedBodySize,
+		allowKeys:               allowKeys,
+		keys:                    keys,
 	}
+	putHandler.ids = newIDGenerator(keys)
 
 	// Instantiate thread-safe memory pools
 	putHandler.memory = syncPools{
@@ -68,40 +79,110 @@ func NewPutHandler(storage backends.Backend, metrics *metrics.Metrics, maxNumVal
 	return putHandler.handle
 }
 
-// parseRequest unmarshals the incoming put request into a thread-safe memory pool. If
-// the incoming request could not be unmarshalled or if the request comes with more
+// parseRequest decodes the incoming put request into a thread-safe memory pool. If
+// the incoming request could not be decoded or if the request comes with more
 // elements to put than the maximum allowed in Prebid Cache's configuration, the
 // corresponding error is returned
 func (e *PutHandler) parseRequest(r *http.Request) (*putRequest, error) {
 	if r == nil {
 		return nil, utils.NewPBCError(utils.PUT_BAD_REQUEST)
 	}
-
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return nil, utils.NewPBCError(utils.PUT_BAD_REQUEST)
-	}
 	defer r.Body.Close()
 
 	// Allocate a PutRequest object in thread-safe memory
 	put := e.memory.requestPool.Get().(*putRequest)
 	put.Puts = make([]putObject, 0)
 
-	if err := json.Unmarshal(body, put); err != nil {
+	if err := e.decodeRequest(r.Body, put); err != nil {
 		// place memory back in sync pool
 		e.memory.requestPool.Put(put)
-		return nil, utils.NewPBCError(utils.PUT_BAD_REQUEST, string(body))
-	}
 
-	if len(put.Puts) > e.cfg.maxNumValues {
-		// place memory back in sync pool
-		e.memory.requestPool.Put(put)
-		return nil, utils.NewPBCError(utils.PUT_MAX_NUM_VALUES, fmt.Sprintf("More keys than allowed: %d", e.cfg.maxNumValues))
+		var pbcErr utils.PBCError
+		if errors.As(err, &pbcErr) {
+			return nil, err
+		}
+		return nil, utils.NewPBCError(utils.PUT_BAD_REQUEST, fmt.Sprintf("Invalid request body: %v", err))
 	}
 
 	return put, nil
 }
 
+// decodeRequest decodes the body as a stream, so that a request with more elements in its "puts"
+// array than allowed is rejected as soon as the extra element is found instead of after all of
+// them were unmarshalled. Like json.Unmarshal, it ignores unknown fields and matches "puts"
+// regardless of its case.
+func (e *PutHandler) decodeRequest(body io.Reader, put *putRequest) error {
+	decoder := json.NewDecoder(body)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := token.(string); !strings.EqualFold(key, "puts") {
+			var ignored json.RawMessage
+			if err := decoder.Decode(&ignored); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.decodePuts(decoder, put); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return errors.New("unexpected data after the request object")
+	}
+	return nil
+}
+
+// decodePuts decodes the elements of the "puts" array one by one
+func (e *PutHandler) decodePuts(decoder *json.Decoder, put *putRequest) error {
+	put.Puts = put.Puts[:0]
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		// "puts": null
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("puts must be an array, found %v", token)
+	}
+	for decoder.More() {
+		if len(put.Puts) >= e.cfg.maxNumValues {
+			return utils.NewPBCError(utils.PUT_MAX_NUM_VALUES, fmt.Sprintf("More keys than allowed: %d", e.cfg.maxNumValues))
+		}
+		var p putObject
+		if err := decoder.Decode(&p); err != nil {
+			return err
+		}
+		put.Puts = append(put.Puts, p)
+	}
+	return expectDelim(decoder, ']')
+}
+
+// expectDelim reads the next token and returns an error if it isn't the delimiter
+func expectDelim(decoder *json.Decoder, expected json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("expected %v, found %v", expected, token)
+	}
+	return nil
+}
+
 // parsePutObject returns an error if the putObject comes with an invalid field
 // and formats the string according to its type:
 //   - XML content gets unmarshaled in order to un-escape it and then gets
@@ -145,27 +226,25 @@ func parsePutObject(p putObject) (string, error) {
 }
 
 func classifyBackendError(err error, index int) error {
-	if _, ok := err.(*backendDecorators.BadPayloadSize); ok {
-		return utils.NewPBCError(utils.BAD_PAYLOAD_SIZE, fmt.Sprintf("POST /cache element %d exceeded max size: %v", index, err.Error()))
+	var badPayloadSize *backendDecorators.BadPayloadSize
+	if errors.As(err, &badPayloadSize) {
+		return utils.WrapPBCError(utils.BAD_PAYLOAD_SIZE, err, fmt.Sprintf("POST /cache element %d exceeded max size: %v", index, err.Error()))
 	}
 
-	switch err {
-	case context.DeadlineExceeded:
-		return utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED)
-	default:
-		return utils.NewPBCError(utils.PUT_INTERNAL_SERVER, err.Error())
+	if errors.Is(err, context.DeadlineExceeded) {
+		return utils.WrapPBCError(utils.PUT_DEADLINE_EXCEEDED, err)
 	}
-
-	return nil
+	return utils.WrapPBCError(utils.PUT_INTERNAL_SERVER, err, err.Error())
 }
 
-func logBackendError(err error) {
-	logrus.Error("POST /cache Error while writing to the back-end: ", err)
+func logBackendError(ctx context.Context, err error) {
+	logger := utils.Logger(ctx)
+	logger.Error("POST /cache Error while writing to the back-end: ", err)
 
-	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr && pbcErr.StatusCode == utils.PUT_DEADLINE_EXCEEDED {
-		logrus.Error("POST /cache timed out:", err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Error("POST /cache timed out:", err)
 	} else {
-		logrus.Error("POST /cache had an unexpected error:", err)
+		logger.Error("POST /cache had an unexpected error:", err)
 	}
 }
 
@@ -175,27 +254,24 @@ func (e *PutHandler) handle(w http.ResponseWriter, r *http.Request, ps httproute
 
 	start := time.Now()
 
+	var body *putBody
+	if r.Body != nil {
+		var err error
+		if body, err = newPutBody(w, r, e.cfg.maxBodySize, e.cfg.maxDecompressedBodySize); err != nil {
+			e.handleException(w, r, err)
+			return
+		}
+		r.Body = body
+	}
+
 	bytes, err := e.processPutRequest(r)
+	if body != nil && body.compressed() {
+		e.metrics.RecordPutCompressedSize(float64(body.raw.read))
+		e.metrics.RecordPutDecompressedSize(float64(body.read))
+	}
 	if err != nil {
 		// At least one of the elements in the incoming request could not be stored
-		// write the http error and log corresponding metrics
-		var statusCode int
-		if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
-			statusCode = pbcErr.StatusCode
-			if statusCode >= 400 && statusCode < 500 {
-				e.metrics.RecordPutBadRequest()
-			} else {
-				e.metrics.RecordPutError()
-			}
-		} else {
-			// All errors returned by e.processPutRequest(r) should be utils.PBCErrors
-			// if not, consider it an interval server error with a http.StatusInternalServerError
-			// status code and accounted under RecordPutError()
-			statusCode = http.StatusInternalServerError
-			e.metrics.RecordPutError()
-		}
-
-		http.Error(w, err.Error(), statusCode)
+		e.handleException(w, r, err)
 		return
 	}
 
@@ -206,6 +282,33 @@ func (e *PutHandler) handle(w http.ResponseWriter, r *http.Request, ps httproute
 	e.metrics.RecordPutDuration(time.Since(start))
 }
 
+// handleException writes the http error and logs corresponding metrics
+func (e *PutHandler) handleException(w http.ResponseWriter, r *http.Request, err error) {
+	var statusCode int
+	var pbcErr utils.PBCError
+	if errors.As(err, &pbcErr) {
+		statusCode = pbcErr.StatusCode
+		if statusCode >= 400 && statusCode < 500 {
+			e.metrics.RecordPutBadRequest()
+		} else {
+			e.metrics.RecordPutError()
+		}
+	} else {
+		// All errors returned by e.processPutRequest(r) should be utils.PBCErrors
+		// if not, consider it an interval server error with a http.StatusInternalServerError
+		// status code and accounted under RecordPutError()
+		statusCode = http.StatusInternalServerError
+		e.metrics.RecordPutError()
+	}
+
+	index := noIndex
+	var elementErr putElementError
+	if errors.As(err, &elementErr) {
+		index = elementErr.index
+	}
+	writeError(w, r, err, err.Error(), statusCode, index)
+}
+
 // processPutRequest parses, unmarshals, and validates the incoming request; then calls the back-end Put()
 // implementation on every element of the "puts" array. This function exits after all elements in the
 // "puts" array have been stored in the back-end, or after the first error is found
@@ -216,6 +319,7 @@ func (e *PutHandler) processPutRequest(r *http.Request) ([]byte, error) {
 		return nil, err
 	}
 	defer e.memory.requestPool.Put(putRequest)
+	utils.RecordNumPuts(r.Context(), len(putRequest.Puts))
 
 	// Allocate a PutResponse object in thread-safe memory
 	putResponse := e.memory.putResponsePool.Get().(*PutResponse)
@@ -223,7 +327,7 @@ func (e *PutHandler) processPutRequest(r *http.Request) ([]byte, error) {
 	defer e.memory.putResponsePool.Put(putResponse)
 
 	// Send elements to storage service or database
-	if pcErr := e.putElements(putRequest, putResponse); pcErr != nil {
+	if pcErr := e.putElements(r.Context(), putRequest, putResponse); pcErr != nil {
 		return nil, pcErr
 	}
 
@@ -240,22 +344,21 @@ func (e *PutHandler) processPutRequest(r *http.Request) ([]byte, error) {
 // first one in the order its corresponding putObject came inside the []PutRequest.Puts array
 //
 // TODO: For those storage clients that support storing multiple elements in a single call, build a batch and send them together
-// TODO: Allow Prebid Cache to provide error details in an "errors" field in the response
-func (e *PutHandler) putElements(put *putRequest, resps *PutResponse) error {
+func (e *PutHandler) putElements(ctx context.Context, put *putRequest, resps *PutResponse) error {
 	// Call Put() implementation of storage back-end in parrallel
 	var waitGroup sync.WaitGroup
 	waitGroup.Add(len(put.Puts))
 
 	for i := 0; i < len(put.Puts); i++ {
-		go e.put(&put.Puts[i], &resps.Responses[i], i, &waitGroup)
+		go e.put(ctx, &put.Puts[i], &resps.Responses[i], i, &waitGroup)
 	}
 	waitGroup.Wait()
 
-	// Log the first element found and return it
-	for _, resp := range resps.Responses {
+	// Log the first element found and return it, along with its index
+	for i, resp := range resps.Responses {
 		if resp.err != nil {
-			logBackendError(resp.err)
-			return resp.err
+			logBackendError(ctx, resp.err)
+			return putElementError{index: i, err: resp.err}
 		}
 	}
 
@@ -264,8 +367,8 @@ func (e *PutHandler) putElements(put *putRequest, resps *PutResponse) error {
 
 // put parses the putObject, validates it and calls the back-end storage Put() function this Prebid Cache instance
 // is using. Returns a putResponseObject storing either the corresponding UUID's data was stored under, or an error
-// if any.
-func (e *PutHandler) put(po *putObject, resp *putResponseObject, index int, wg *sync.WaitGroup) {
+// if any. Generated keys that are already taken are replaced by new ones up to keys.collision_retries times.
+func (e *PutHandler) put(ctx context.Context, po *putObject, resp *putResponseObject, index int, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	toCache, err := parsePutObject(*po)
@@ -275,40 +378,117 @@ func (e *PutHandler) put(po *putObject, resp *putResponseObject, index int, wg *
 	}
 
 	// Only allow setting a provided key if configured (and ensure a key is provided).
+	contentKey, generatedKey := false, false
 	if e.cfg.allowKeys && len(po.Key) > 0 {
 		// put object comes with custom key, which we are allowed to use
 		resp.UUID = po.Key
 		e.metrics.RecordPutKeyProvided()
+	} else if e.cfg.keys.Dedup {
+		// Identical values get the same key, so they're only stored once
+		resp.UUID = e.ids.ContentID(toCache, po.TTLSeconds)
+		contentKey = true
 	} else {
 		// Either put object doesn't come with a custom key or Prebid Cache is configured
-		// to not use custom keys. Generate a random UUID
-		if resp.UUID, err = utils.GenerateRandomID(); err != nil {
+		// to not use custom keys. Generate a new ID
+		if resp.UUID, err = e.ids.NewID(); err != nil {
 			resp.UUID = ""
-			resp.err = utils.NewPBCError(utils.PUT_INTERNAL_SERVER, "Error generating version 4 UUID")
+			resp.err = utils.NewPBCError(utils.PUT_INTERNAL_SERVER, "Error generating the key")
 			return
 		}
+		generatedKey = true
 	}
 
 	// If we have a blank UUID, don't store anything.
-	// Eventually we may want to provide error details, but as of today this is the only non-fatal error
-	// Future error details could go into a second property of the Responses object, such as "errors"
-	if len(resp.UUID) > 0 {
-		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
-		defer cancel()
+	if len(resp.UUID) == 0 {
+		return
+	}
 
-		err = e.backend.Put(ctx, resp.UUID, toCache, po.TTLSeconds)
-		if err != nil {
-			if pbcErr, isPbcErr := err.(utils.PBCError); isPbcErr && pbcErr.Type == utils.RECORD_EXISTS {
-				// Record didn't get overwritten, return a response with an empty UUID string
-				resp.UUID = ""
-			} else {
-				resp.err = classifyBackendError(err, index)
-			}
+	err = e.storeValue(ctx, resp.UUID, toCache, po.TTLSeconds)
+	if contentKey && isRecordExists(err) {
+		if e.isStored(ctx, resp.UUID, toCache, po.TTLSeconds) {
+			// The same value is already stored under its content key, which can be returned
+			e.metrics.RecordPutDedupHit()
+			return
+		}
+		// Content keys can be predicted, so a client allowed to set keys could have stored another
+		// value under this one. Returning it would serve that value, and returning a value that
+		// expires before the requested time-to-live is up would lose it, so a new key is generated.
+		e.metrics.RecordPutKeyCollision()
+		if resp.UUID, err = e.ids.NewID(); err != nil {
+			resp.UUID = ""
+			resp.err = utils.NewPBCError(utils.PUT_INTERNAL_SERVER, "Error generating the key")
+			return
+		}
+		generatedKey = true
+		err = e.storeValue(ctx, resp.UUID, toCache, po.TTLSeconds)
+	}
+	for retries := 0; generatedKey && isRecordExists(err); retries++ {
+		// Another value is stored under the generated key. Unlike custom keys, the client doesn't
+		// care which key the value gets, so it can be put again under a new one.
+		e.metrics.RecordPutKeyCollision()
+		if retries == e.cfg.keys.CollisionRetries {
+			e.metrics.RecordPutRetriesExhausted()
+			break
+		}
+		if resp.UUID, err = e.ids.NewID(); err != nil {
+			resp.UUID = ""
+			resp.err = utils.NewPBCError(utils.PUT_INTERNAL_SERVER, "Error generating the key")
+			return
+		}
+		err = e.storeValue(ctx, resp.UUID, toCache, po.TTLSeconds)
+	}
+
+	if err != nil {
+		if isRecordExists(err) {
+			// Record didn't get overwritten, return a response with an empty UUID string, which is
+			// the only non-fatal error, and tell why
+			resp.UUID = ""
+			resp.Error = utils.ErrorCode(err)
+		} else {
+			resp.err = classifyBackendError(err, index)
 		}
 	}
 	return
 }
 
+// storeValue puts the value in the backend under key, giving up after 500 milliseconds
+func (e *PutHandler) storeValue(ctx context.Context, key string, value string, ttlSeconds int) error {
+	backendCtx, cancel := context.WithTimeout(utils.DetachContext(ctx), 500*time.Millisecond)
+	defer cancel()
+
+	return e.backend.Put(backendCtx, key, value, ttlSeconds)
+}
+
+// isStored tells whether value is the one stored under key, and lives for at least as long as a
+// value put with ttlSeconds would be stored for, if the backend knows when it expires. Backends
+// count time-to-live in whole seconds, so values put less than a second ago still qualify. The
+// read isn't counted in the get metrics, since no client asked for it. Gives up after 500
+// milliseconds.
+func (e *PutHandler) isStored(ctx context.Context, key string, value string, ttlSeconds int) bool {
+	expiration := &utils.ValueExpiration{}
+	backendCtx := utils.WithInternalRead(utils.WithValueExpiration(utils.DetachContext(ctx), expiration))
+	backendCtx, cancel := context.WithTimeout(backendCtx, 500*time.Millisecond)
+	defer cancel()
+
+	stored, err := e.backend.Get(backendCtx, key)
+	if err != nil || stored != value {
+		return false
+	}
+	if limiter, ok := e.backend.(backends.TTLLimiter); ok {
+		// Values put without a time-to-live, or with one above the maximum, are stored with the maximum
+		ttlSeconds = limiter.LimitTTL(ttlSeconds)
+	}
+	if expiresAt, ok := expiration.ExpiresAt(); ok && ttlSeconds > 0 {
+		return !expiresAt.Add(time.Second).Before(time.Now().Add(time.Duration(ttlSeconds) * time.Second))
+	}
+	return true
+}
+
+// isRecordExists tells whether the backend refused to put a value because its key is taken
+func isRecordExists(err error) bool {
+	return errors.Is(err, utils.NewPBCError(utils.RECORD_EXISTS))
+}
+
 type putRequest struct {
 	Puts []putObject `json:"puts"`
 }
@@ -322,7 +502,9 @@ type putObject struct {
 
 type putResponseObject struct {
 	UUID string `json:"uuid"`
-	err  error
+	// Error is the code of the reason why the value wasn't stored, when uuid is empty
+	Error string `json:"error,omitempty"`
+	err   error
 }
 
 // PutResponse will be marshaled to be written into the http response
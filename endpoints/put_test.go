@@ -119,13 +119,13 @@ func TestPutJsonTests(t *testing.T) {
 			mockMetrics := metricstest.CreateMockMetrics()
 			m := &metrics.Metrics{
 				MetricEngines: []metrics.CacheMetrics{
-					&mockMetrics,
+					mockMetrics,
 				},
 			}
 
 			backend := backendConfig.NewBackend(cfg, m)
 			router := httprouter.New()
-			router.POST("/cache", NewPutHandler(backend, m, testInfo.ServerConfig.MaxNumValues, testInfo.ServerConfig.AllowSettingKeys))
+			router.POST("/cache", NewPutHandler(backend, m, testInfo.ServerConfig.MaxNumValues, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, testInfo.ServerConfig.AllowSettingKeys, config.Keys{}))
 			request, err := http.NewRequest("POST", "/cache", strings.NewReader(string(testInfo.PutRequest)))
 			assert.NoError(t, err, "Failed to create a POST request. Test file: %s Error: %v", testFile, err)
 			rr := httptest.NewRecorder()
@@ -413,12 +413,12 @@ func TestSuccessfulPut(t *testing.T) {
 			mockMetrics := metricstest.CreateMockMetrics()
 			m := &metrics.Metrics{
 				MetricEngines: []metrics.CacheMetrics{
-					&mockMetrics,
+					mockMetrics,
 				},
 			}
 
-			router.POST("/cache", NewPutHandler(backend, m, 10, true))
-			router.GET("/cache", NewGetHandler(backend, m, true))
+			router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
+			router.GET("/cache", NewGetHandler(backend, m, true, nil, config.Keys{}, config.Peers{}))
 
 			// Feed the tests input put request to the endpoint's handle
 			putResponse := doPut(t, router, tc.inPutBody)
@@ -465,13 +465,13 @@ func TestMalformedOrInvalidValue(t *testing.T) {
 		{
 			desc:             "Badly escaped character in value field",
 			inPutBody:        `{"puts":[{"type":"json","value":"badly-esca"ped"}]}`,
-			expectedError:    utils.NewPBCError(utils.PUT_BAD_REQUEST, `{"puts":[{"type":"json","value":"badly-esca"ped"}]}`),
+			expectedError:    utils.NewPBCError(utils.PUT_BAD_REQUEST, "Invalid request body: invalid character 'p' after object key:value pair"),
 			expectedPutCalls: 0,
 		},
 		{
 			desc:             "Malformed JSON in value field",
 			inPutBody:        `{"puts":[{"type":"json","value":malformed}]}`,
-			expectedError:    utils.NewPBCError(utils.PUT_BAD_REQUEST, `{"puts":[{"type":"json","value":malformed}]}`),
+			expectedError:    utils.NewPBCError(utils.PUT_BAD_REQUEST, "Invalid request body: invalid character 'm' looking for beginning of value"),
 			expectedPutCalls: 0,
 		},
 		{
@@ -503,11 +503,11 @@ func TestMalformedOrInvalidValue(t *testing.T) {
 		mockMetrics := metricstest.CreateMockMetrics()
 		m := &metrics.Metrics{
 			MetricEngines: []metrics.CacheMetrics{
-				&mockMetrics,
+				mockMetrics,
 			},
 		}
 
-		router.POST("/cache", NewPutHandler(backend, m, 10, true))
+		router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
 
 		// Run test
 		putResponse := doPut(t, router, tc.inPutBody)
@@ -540,10 +540,10 @@ func TestNonSupportedType(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
 
 	putResponse := doPut(t, router, requestBody)
 
@@ -575,11 +575,11 @@ func TestPutNegativeTTL(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 
-	testRouter.POST("/cache", NewPutHandler(testBackend, m, 10, true))
+	testRouter.POST("/cache", NewPutHandler(testBackend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
 
 	recorder := httptest.NewRecorder()
 
@@ -638,7 +638,7 @@ func TestCustomKey(t *testing.T) {
 			allowSettingKeys: true,
 			testCases: []aTest{
 				{
-					desc:         "Setting keys allowed but key already maps to an element in cache, don't overwrite the value in the data storage and simply respond with blank UUID and a 200 code",
+					desc:         "Setting keys allowed but key already maps to an element in cache, don't overwrite the value in the data storage and simply respond with blank UUID, the reason why and a 200 code",
 					inCustomKey:  "36-char-key-maps-to-actual-xml-value",
 					expectedUUID: "",
 					expectedMetrics: []string{
@@ -669,12 +669,12 @@ func TestCustomKey(t *testing.T) {
 			mockMetrics := metricstest.CreateMockMetrics()
 			m := &metrics.Metrics{
 				MetricEngines: []metrics.CacheMetrics{
-					&mockMetrics,
+					mockMetrics,
 				},
 			}
 
 			router := httprouter.New()
-			putEndpointHandler := NewPutHandler(mockBackendWithValues, m, 10, tgroup.allowSettingKeys)
+			putEndpointHandler := NewPutHandler(mockBackendWithValues, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, tgroup.allowSettingKeys, config.Keys{})
 			router.POST("/cache", putEndpointHandler)
 
 			recorder := httptest.NewRecorder()
@@ -694,7 +694,7 @@ func TestCustomKey(t *testing.T) {
 
 			// Assert response UUID
 			if tc.expectedUUID == "" {
-				assert.Equalf(t, `{"responses":[{"uuid":""}]}`, recorder.Body.String(), tc.desc)
+				assert.Equalf(t, `{"responses":[{"uuid":"","error":"RECORD_EXISTS"}]}`, recorder.Body.String(), tc.desc)
 			} else {
 				assert.Regexp(t, regexp.MustCompile(tc.expectedUUID), recorder.Body.String(), tc.desc)
 			}
@@ -708,10 +708,10 @@ func TestRequestReadError(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
-	putEndpointHandler := NewPutHandler(mockBackendWithValues, m, 10, false)
+	putEndpointHandler := NewPutHandler(mockBackendWithValues, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, false, config.Keys{})
 
 	router := httprouter.New()
 	router.POST("/cache", putEndpointHandler)
@@ -753,10 +753,10 @@ func TestTooManyPutElements(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
-	router.POST("/cache", NewPutHandler(backend, m, len(putElements)-1, true))
+	router.POST("/cache", NewPutHandler(backend, m, len(putElements)-1, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
 
 	putResponse := doPut(t, router, reqBody)
 
@@ -770,6 +770,52 @@ func TestTooManyPutElements(t *testing.T) {
 	metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
 }
 
+func TestPutBodyTooLarge(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		inMaxBodySize  int
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "Body within the limit",
+			inMaxBodySize:  64,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "Body over the limit",
+			inMaxBodySize:  16,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "POST /cache request body exceeded max size: 16 bytes\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := &mockBackend{}
+		backend.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				mockMetrics,
+			},
+		}
+		router := httprouter.New()
+		router.POST("/cache", NewPutHandler(backend, m, 10, tc.inMaxBodySize, utils.REQUEST_MAX_DECOMPRESSED_BYTES, false, config.Keys{}))
+
+		putResponse := doPut(t, router, `{"puts":[{"type":"json","value":true}]}`)
+
+		assert.Equal(t, tc.expectedStatus, putResponse.Code, tc.desc)
+		if tc.expectedStatus == http.StatusOK {
+			backend.AssertNumberOfCalls(t, "Put", 1)
+			continue
+		}
+		assert.Equal(t, tc.expectedBody, putResponse.Body.String(), tc.desc)
+		backend.AssertNotCalled(t, "Put")
+		metricstest.AssertMetrics(t, []string{"RecordPutTotal", "RecordPutBadRequest"}, mockMetrics)
+	}
+}
+
 // TestMultiPutRequest asserts results for requests with more than one element in the "puts" array
 func TestMultiPutRequest(t *testing.T) {
 	type aTest struct {
@@ -807,12 +853,12 @@ func TestMultiPutRequest(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
-	router.GET("/cache", NewGetHandler(backend, m, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
+	router.GET("/cache", NewGetHandler(backend, m, true, nil, config.Keys{}, config.Peers{}))
 
 	rr := httptest.NewRecorder()
 
@@ -844,6 +890,377 @@ func TestMultiPutRequest(t *testing.T) {
 	metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
 }
 
+func TestPutDedup(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		inDedup           bool
+		inPuts            string
+		expectedSameKeys  bool
+		expectedCustomKey bool
+		expectedMetrics   []string
+	}{
+		{
+			desc:             "Identical values share their key",
+			inDedup:          true,
+			inPuts:           `{"type":"xml","value":"<VAST/>","ttlseconds":60},{"type":"xml","value":"<VAST/>","ttlseconds":60}`,
+			expectedSameKeys: true,
+			expectedMetrics:  []string{"RecordPutTotal", "RecordPutDuration", "RecordPutDedupHit"},
+		},
+		{
+			desc:            "Values of different types don't share their key",
+			inDedup:         true,
+			inPuts:          `{"type":"json","value":"\"<VAST/>\"","ttlseconds":60},{"type":"xml","value":"<VAST/>","ttlseconds":60}`,
+			expectedMetrics: []string{"RecordPutTotal", "RecordPutDuration"},
+		},
+		{
+			desc:            "Values with different time-to-live don't share their key",
+			inDedup:         true,
+			inPuts:          `{"type":"xml","value":"<VAST/>","ttlseconds":60},{"type":"xml","value":"<VAST/>","ttlseconds":300}`,
+			expectedMetrics: []string{"RecordPutTotal", "RecordPutDuration"},
+		},
+		{
+			desc:              "Custom keys are kept",
+			inDedup:           true,
+			inPuts:            `{"type":"xml","value":"<VAST/>","ttlseconds":60,"key":"custom-key"},{"type":"xml","value":"<VAST/>","ttlseconds":60}`,
+			expectedCustomKey: true,
+			expectedMetrics:   []string{"RecordPutTotal", "RecordPutDuration", "RecordPutKeyProvided"},
+		},
+		{
+			desc:            "Dedup disabled",
+			inPuts:          `{"type":"xml","value":"<VAST/>","ttlseconds":60},{"type":"xml","value":"<VAST/>","ttlseconds":60}`,
+			expectedMetrics: []string{"RecordPutTotal", "RecordPutDuration"},
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := backends.NewMemoryBackend()
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				mockMetrics,
+			},
+		}
+		router := httprouter.New()
+		router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{Dedup: tc.inDedup}))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", "/cache", strings.NewReader(`{"puts":[`+tc.inPuts+`]}`)))
+
+		assert.Equal(t, http.StatusOK, recorder.Code, tc.desc)
+		var parsed PutResponse
+		if !assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &parsed), tc.desc) || !assert.Len(t, parsed.Responses, 2, tc.desc) {
+			continue
+		}
+		first, second := parsed.Responses[0].UUID, parsed.Responses[1].UUID
+		assert.Len(t, second, 36, tc.desc)
+		if tc.expectedCustomKey {
+			assert.Equal(t, "custom-key", first, tc.desc)
+		} else {
+			assert.Len(t, first, 36, tc.desc)
+		}
+		if tc.expectedSameKeys {
+			assert.Equal(t, first, second, tc.desc)
+		} else {
+			assert.NotEqual(t, first, second, tc.desc)
+		}
+		for _, key := range []string{first, second} {
+			_, err := backend.Get(context.Background(), key)
+			assert.NoError(t, err, tc.desc)
+		}
+		metricstest.AssertMetrics(t, tc.expectedMetrics, mockMetrics)
+	}
+}
+
+func TestPutKeyFormats(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		inKeys        config.Keys
+		expectedRegex string
+	}{
+		{
+			desc:          "Version 4 UUIDs by default",
+			expectedRegex: "^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$",
+		},
+		{
+			desc:          "Version 7 UUIDs",
+			inKeys:        config.Keys{Format: config.IDFormatUUIDv7},
+			expectedRegex: "^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$",
+		},
+		{
+			desc:          "Prefixed ULIDs",
+			inKeys:        config.Keys{Format: config.IDFormatULID, Prefix: "use1-"},
+			expectedRegex: "^use1-[0-7][0-9A-HJKMNP-TV-Z]{25}$",
+		},
+		{
+			desc:          "Prefixed ULIDs derived from the content",
+			inKeys:        config.Keys{Dedup: true, Format: config.IDFormatULID, Prefix: "use1-"},
+			expectedRegex: "^use1-[0-7][0-9A-HJKMNP-TV-Z]{25}$",
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := backends.NewMemoryBackend()
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				mockMetrics,
+			},
+		}
+		router := httprouter.New()
+		router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, false, tc.inKeys))
+		router.GET("/cache", NewGetHandler(backend, m, false, nil, tc.inKeys, config.Peers{}))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", "/cache", strings.NewReader(`{"puts":[{"type":"xml","value":"<VAST/>"}]}`)))
+
+		var parsed PutResponse
+		if !assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &parsed), tc.desc) || !assert.Len(t, parsed.Responses, 1, tc.desc) {
+			continue
+		}
+		assert.Regexp(t, tc.expectedRegex, parsed.Responses[0].UUID, tc.desc)
+
+		// The generated keys pass the validation of get requests
+		getResults := doMockGet(t, router, parsed.Responses[0].UUID)
+		assert.Equal(t, http.StatusOK, getResults.Code, tc.desc)
+		assert.Equal(t, "<VAST/>", getResults.Body.String(), tc.desc)
+	}
+}
+
+func TestPutKeyCollisions(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		inKeys           config.Keys
+		inPut            string
+		inCollisions     int
+		inStoredValue    string
+		inStoredTTL      time.Duration
+		inMaxTTL         int
+		expectedPuts     int
+		expectedStored   bool
+		expectedResponse string
+		expectedMetrics  []string
+	}{
+		{
+			desc:           "Generated key taken once, stored under a new one",
+			inKeys:         config.Keys{CollisionRetries: 3},
+			inPut:          `{"type":"xml","value":"<VAST/>"}`,
+			inCollisions:   1,
+			expectedPuts:   2,
+			expectedStored: true,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutKeyCollision",
+			},
+		},
+		{
+			desc:             "Every generated key taken, retries exhausted",
+			inKeys:           config.Keys{CollisionRetries: 2},
+			inPut:            `{"type":"xml","value":"<VAST/>"}`,
+			inCollisions:     3,
+			expectedPuts:     3,
+			expectedResponse: `{"responses":[{"uuid":"","error":"RECORD_EXISTS"}]}`,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutKeyCollision",
+				"RecordPutRetriesExhausted",
+			},
+		},
+		{
+			desc:             "Retries disabled",
+			inPut:            `{"type":"xml","value":"<VAST/>"}`,
+			inCollisions:     1,
+			expectedPuts:     1,
+			expectedResponse: `{"responses":[{"uuid":"","error":"RECORD_EXISTS"}]}`,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutKeyCollision",
+				"RecordPutRetriesExhausted",
+			},
+		},
+		{
+			desc:             "Custom keys aren't replaced",
+			inKeys:           config.Keys{CollisionRetries: 3},
+			inPut:            `{"type":"xml","value":"<VAST/>","key":"custom-key"}`,
+			inCollisions:     1,
+			expectedPuts:     1,
+			expectedResponse: `{"responses":[{"uuid":"","error":"RECORD_EXISTS"}]}`,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutKeyProvided",
+			},
+		},
+		{
+			desc:           "Content keys holding the same value aren't replaced",
+			inKeys:         config.Keys{Dedup: true, CollisionRetries: 3},
+			inPut:          `{"type":"xml","value":"<VAST/>"}`,
+			inCollisions:   1,
+			inStoredValue:  "xml<VAST/>",
+			expectedPuts:   1,
+			expectedStored: true,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutDedupHit",
+			},
+		},
+		{
+			desc:           "Content keys holding another value are replaced",
+			inKeys:         config.Keys{Dedup: true, CollisionRetries: 3},
+			inPut:          `{"type":"xml","value":"<VAST/>"}`,
+			inCollisions:   1,
+			inStoredValue:  "xml<VAST><Ad>Not the value that was put</Ad></VAST>",
+			expectedPuts:   2,
+			expectedStored: true,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutKeyCollision",
+			},
+		},
+		{
+			desc:           "Content keys holding the same value for long enough aren't replaced",
+			inKeys:         config.Keys{Dedup: true, CollisionRetries: 3},
+			inPut:          `{"type":"xml","value":"<VAST/>","ttlseconds":60}`,
+			inCollisions:   1,
+			inStoredValue:  "xml<VAST/>",
+			inStoredTTL:    60 * time.Second,
+			expectedPuts:   1,
+			expectedStored: true,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutDedupHit",
+			},
+		},
+		{
+			desc:           "Content keys holding the same value expiring sooner than requested are replaced",
+			inKeys:         config.Keys{Dedup: true, CollisionRetries: 3},
+			inPut:          `{"type":"xml","value":"<VAST/>","ttlseconds":3600}`,
+			inCollisions:   1,
+			inStoredValue:  "xml<VAST/>",
+			inStoredTTL:    time.Second,
+			expectedPuts:   2,
+			expectedStored: true,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutKeyCollision",
+			},
+		},
+		{
+			desc:           "Content keys holding the same value expiring sooner than the maximum time-to-live are replaced, when no time-to-live is requested",
+			inKeys:         config.Keys{Dedup: true, CollisionRetries: 3},
+			inPut:          `{"type":"xml","value":"<VAST/>","ttlseconds":0}`,
+			inCollisions:   1,
+			inStoredValue:  "xml<VAST/>",
+			inStoredTTL:    time.Second,
+			inMaxTTL:       3600,
+			expectedPuts:   2,
+			expectedStored: true,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutKeyCollision",
+			},
+		},
+		{
+			desc:           "Content keys holding the same value for the maximum time-to-live aren't replaced, when no time-to-live is requested",
+			inKeys:         config.Keys{Dedup: true, CollisionRetries: 3},
+			inPut:          `{"type":"xml","value":"<VAST/>","ttlseconds":0}`,
+			inCollisions:   1,
+			inStoredValue:  "xml<VAST/>",
+			inStoredTTL:    3600 * time.Second,
+			inMaxTTL:       3600,
+			expectedPuts:   1,
+			expectedStored: true,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutDedupHit",
+			},
+		},
+		{
+			desc:           "Content keys holding the same value for the maximum time-to-live aren't replaced, when a longer one is requested",
+			inKeys:         config.Keys{Dedup: true, CollisionRetries: 3},
+			inPut:          `{"type":"xml","value":"<VAST/>","ttlseconds":7200}`,
+			inCollisions:   1,
+			inStoredValue:  "xml<VAST/>",
+			inStoredTTL:    3600 * time.Second,
+			inMaxTTL:       3600,
+			expectedPuts:   1,
+			expectedStored: true,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutDedupHit",
+			},
+		},
+		{
+			desc:             "Content keys holding another value are replaced, retries exhausted",
+			inKeys:           config.Keys{Dedup: true},
+			inPut:            `{"type":"xml","value":"<VAST/>"}`,
+			inCollisions:     2,
+			inStoredValue:    "xml<VAST><Ad>Not the value that was put</Ad></VAST>",
+			expectedPuts:     2,
+			expectedResponse: `{"responses":[{"uuid":"","error":"RECORD_EXISTS"}]}`,
+			expectedMetrics: []string{
+				"RecordPutTotal",
+				"RecordPutDuration",
+				"RecordPutKeyCollision",
+				"RecordPutRetriesExhausted",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var keys []string
+		backend := &mockBackend{}
+		recordKey := func(args mock.Arguments) { keys = append(keys, args.String(1)) }
+		if tc.inCollisions > 0 {
+			backend.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(recordKey).Return(utils.NewPBCError(utils.RECORD_EXISTS)).Times(tc.inCollisions)
+		}
+		backend.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(recordKey).Return(nil)
+		backend.On("Get", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			if tc.inStoredTTL > 0 {
+				utils.RecordValueExpiration(args.Get(0).(context.Context), time.Now().Add(tc.inStoredTTL))
+			}
+		}).Return(tc.inStoredValue, nil)
+
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				mockMetrics,
+			},
+		}
+		var putBackend backends.Backend = backend
+		if tc.inMaxTTL > 0 {
+			putBackend = decorators.LimitTTLs(backend, tc.inMaxTTL)
+		}
+		router := httprouter.New()
+		router.POST("/cache", NewPutHandler(putBackend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, tc.inKeys))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", "/cache", strings.NewReader(`{"puts":[`+tc.inPut+`]}`)))
+
+		assert.Equal(t, http.StatusOK, recorder.Code, tc.desc)
+		backend.AssertNumberOfCalls(t, "Put", tc.expectedPuts)
+		if tc.expectedStored {
+			// The value is stored under the last key it was put with, which the response holds
+			assert.Equal(t, `{"responses":[{"uuid":"`+keys[len(keys)-1]+`"}]}`, recorder.Body.String(), tc.desc)
+		} else {
+			assert.Equal(t, tc.expectedResponse, recorder.Body.String(), tc.desc)
+		}
+		if len(keys) == 2 {
+			assert.NotEqual(t, keys[0], keys[1], tc.desc)
+		}
+		metricstest.AssertMetrics(t, tc.expectedMetrics, mockMetrics)
+	}
+}
+
 func TestBadPayloadSizePutError(t *testing.T) {
 	// Stored value size_limit
 	sizeLimit := 3
@@ -859,10 +1276,10 @@ func TestBadPayloadSizePutError(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
 
 	putResponse := doPut(t, router, reqBody)
 
@@ -893,13 +1310,13 @@ func TestInternalPutClientError(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 	// Use mock client that will return an error
 	backendWithMetrics := decorators.LogMetrics(newErrorReturningBackend(), m)
 
-	router.POST("/cache", NewPutHandler(backendWithMetrics, m, 10, true))
+	router.POST("/cache", NewPutHandler(backendWithMetrics, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
 
 	// Run test
 	putResponse := doPut(t, router, reqBody)
@@ -969,11 +1386,11 @@ func TestEmptyPutRequests(t *testing.T) {
 		mockMetrics := metricstest.CreateMockMetrics()
 		m := &metrics.Metrics{
 			MetricEngines: []metrics.CacheMetrics{
-				&mockMetrics,
+				mockMetrics,
 			},
 		}
 		router := httprouter.New()
-		router.POST("/cache", NewPutHandler(backend, m, 10, true))
+		router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
 		rr := httptest.NewRecorder()
 
 		// Create request everytime
@@ -1013,10 +1430,10 @@ func TestPutClientDeadlineExceeded(t *testing.T) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
 
 	putResponse := doPut(t, router, reqBody)
 
@@ -1054,7 +1471,39 @@ func TestParseRequest(t *testing.T) {
 				r, _ := http.NewRequest("POST", "http://fakeurl.com", bytes.NewBuffer([]byte(`malformed`)))
 				return r
 			},
-			testOut{nil, utils.NewPBCError(utils.PUT_BAD_REQUEST, "malformed")},
+			testOut{nil, utils.NewPBCError(utils.PUT_BAD_REQUEST, "Invalid request body: invalid character 'm' looking for beginning of value")},
+		},
+		{
+			"puts field isn't an array",
+			func() *http.Request {
+				r, _ := http.NewRequest("POST", "http://fakeurl.com", bytes.NewBuffer([]byte(`{"puts":{"type":"json"}}`)))
+				return r
+			},
+			testOut{nil, utils.NewPBCError(utils.PUT_BAD_REQUEST, "Invalid request body: puts must be an array, found {")},
+		},
+		{
+			"data after the request object",
+			func() *http.Request {
+				r, _ := http.NewRequest("POST", "http://fakeurl.com", bytes.NewBuffer([]byte(`{"puts":[]} {}`)))
+				return r
+			},
+			testOut{nil, utils.NewPBCError(utils.PUT_BAD_REQUEST, "Invalid request body: unexpected data after the request object")},
+		},
+		{
+			"unknown fields are ignored and the case of puts doesn't matter",
+			func() *http.Request {
+				requestBody := []byte(`{"unknown":{"puts":[1,2]},"PUTS":[{"type":"json","value":5,"ttlseconds":60,"key":"k"}]}`)
+				r, _ := http.NewRequest("POST", "http://fakeurl.com", bytes.NewBuffer(requestBody))
+				return r
+			},
+			testOut{
+				&putRequest{
+					Puts: []putObject{
+						{Type: "json", TTLSeconds: 60, Value: json.RawMessage(`5`), Key: "k"},
+					},
+				},
+				nil,
+			},
 		},
 		{
 			"valid request body. Expect no error",
@@ -1081,6 +1530,15 @@ func TestParseRequest(t *testing.T) {
 			},
 			testOut{nil, utils.NewPBCError(utils.PUT_MAX_NUM_VALUES, "More keys than allowed: 1")},
 		},
+		{
+			"decoding stops at the first element past the max number of values, the rest of the body isn't read",
+			func() *http.Request {
+				requestBody := []byte(`{"puts":[{"type":"xml","value":"XmlValue"}, {"type":"json","value":5}, malformed`)
+				r, _ := http.NewRequest("POST", "http://fakeurl.com", bytes.NewBuffer(requestBody))
+				return r
+			},
+			testOut{nil, utils.NewPBCError(utils.PUT_MAX_NUM_VALUES, "More keys than allowed: 1")},
+		},
 	}
 	for _, tc := range testCases {
 		// set test
@@ -1205,7 +1663,7 @@ func TestClassifyBackendError(t *testing.T) {
 			"Bad payload size error",
 			&backendDecorators.BadPayloadSize{Limit: 1, Size: 2},
 			testOutput{
-				utils.NewPBCError(utils.BAD_PAYLOAD_SIZE, "POST /cache element 0 exceeded max size: Payload size 2 exceeded max 1"),
+				utils.WrapPBCError(utils.BAD_PAYLOAD_SIZE, &backendDecorators.BadPayloadSize{Limit: 1, Size: 2}, "POST /cache element 0 exceeded max size: Payload size 2 exceeded max 1"),
 				http.StatusBadRequest,
 			},
 		},
@@ -1213,7 +1671,7 @@ func TestClassifyBackendError(t *testing.T) {
 			"DeadlineExceeded error",
 			context.DeadlineExceeded,
 			testOutput{
-				utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+				utils.WrapPBCError(utils.PUT_DEADLINE_EXCEEDED, context.DeadlineExceeded),
 				utils.HTTPDependencyTimeout,
 			},
 		},
@@ -1221,7 +1679,7 @@ func TestClassifyBackendError(t *testing.T) {
 			"Backend client error",
 			errors.New("Server memory error"),
 			testOutput{
-				utils.NewPBCError(utils.PUT_INTERNAL_SERVER, "Server memory error"),
+				utils.WrapPBCError(utils.PUT_INTERNAL_SERVER, errors.New("Server memory error"), "Server memory error"),
 				http.StatusInternalServerError,
 			},
 		},
@@ -1308,12 +1766,12 @@ func benchmarkPutHandler(b *testing.B, testCase string) {
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
-			&mockMetrics,
+			mockMetrics,
 		},
 	}
 
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
-	router.GET("/cache", NewGetHandler(backend, m, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, true, config.Keys{}))
+	router.GET("/cache", NewGetHandler(backend, m, true, nil, config.Keys{}, config.Peers{}))
 
 	rr := httptest.NewRecorder()
 
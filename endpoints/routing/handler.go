@@ -11,48 +11,97 @@ import (
 	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/endpoints"
 	"github.com/prebid/prebid-cache/metrics"
+	localprometheus "github.com/prebid/prebid-cache/metrics/prometheus"
+	"github.com/prebid/prebid-cache/tracing"
+	"github.com/prebid/prebid-cache/utils"
 	"github.com/prebid/prebid-cache/version"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
+	log "github.com/sirupsen/logrus"
 )
 
-func NewAdminHandler(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics) http.Handler {
+func NewAdminHandler(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics, readiness *endpoints.Readiness, tracer *tracing.Tracer) http.Handler {
 	router := httprouter.New()
-	addReadRoutes(cfg, dataStore, appMetrics, router)
+	addReadRoutes(cfg, dataStore, appMetrics, readiness, router)
 	addWriteRoutes(cfg, dataStore, appMetrics, router)
-	return router
+	if cfg.Metrics.Prometheus.Enabled && cfg.Metrics.Prometheus.AdminEndpoint {
+		addMetricsRoute(cfg.Metrics.Prometheus, appMetrics, router)
+	}
+	return handleRequests(handleErrorFormat(router, cfg.Routes.ErrorFormat), cfg.Log.AccessLog, tracer)
 }
 
-func NewPublicHandler(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics) http.Handler {
+func NewPublicHandler(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics, readiness *endpoints.Readiness, tracer *tracing.Tracer) http.Handler {
 	router := httprouter.New()
-	addReadRoutes(cfg, dataStore, appMetrics, router)
+	addReadRoutes(cfg, dataStore, appMetrics, readiness, router)
 	if cfg.Routes.AllowPublicWrite {
 		addWriteRoutes(cfg, dataStore, appMetrics, router)
 	}
 
-	handler := handleCors(router)
-	handler = handleRateLimiting(handler, cfg.RateLimiting)
-	return handler
+	handler := handleCors(handleErrorFormat(router, cfg.Routes.ErrorFormat), cfg.Routes.CORS)
+	handler = handleRateLimiting(handler, cfg.RateLimiting, newTrustedProxies(cfg.ClientIP))
+	return handleRequests(handler, cfg.Log.AccessLog, tracer)
 }
 
-func addReadRoutes(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics, router *httprouter.Router) {
+func addReadRoutes(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics, readiness *endpoints.Readiness, router *httprouter.Router) {
 	router.GET("/", endpoints.NewIndexHandler(cfg.IndexResponse)) // Default route handler
-	router.GET("/status", endpoints.Status)                       // Determines whether the server is ready for more traffic.
-	router.GET("/cache", endpoints.NewGetHandler(dataStore, appMetrics, cfg.RequestLimits.AllowSettingKeys))
+	router.GET("/status", endpoints.NewStatusHandler(readiness))  // Determines whether the server is ready for more traffic.
+	getHandler := endpoints.NewGetHandler(dataStore, appMetrics, cfg.RequestLimits.AllowSettingKeys, cfg.Routes.ResponseEncodings, cfg.Keys, cfg.Peers)
+	router.GET("/cache", getHandler)
+	router.HEAD("/cache", getHandler)
 	router.GET("/version", endpoints.NewVersionEndpoint(version.Ver, version.Rev))
 }
 
 func addWriteRoutes(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics, router *httprouter.Router) {
-	router.POST("/cache", endpoints.NewPutHandler(dataStore, appMetrics, cfg.RequestLimits.MaxNumValues, cfg.RequestLimits.AllowSettingKeys))
+	router.POST("/cache", endpoints.NewPutHandler(dataStore, appMetrics, cfg.RequestLimits.MaxNumValues, cfg.RequestLimits.MaxBodySize, cfg.RequestLimits.MaxDecompressedBodySize, cfg.RequestLimits.AllowSettingKeys, cfg.Keys))
+}
+
+// addMetricsRoute serves the Prometheus metrics on /metrics
+func addMetricsRoute(cfg config.PrometheusMetrics, appMetrics *metrics.Metrics, router *httprouter.Router) {
+	promRegistry, ok := appMetrics.GetEngineRegistry(localprometheus.MetricsPrometheus).(*prometheus.Registry)
+	if !ok {
+		log.Errorf("Prometheus metrics configured, but a Prometheus metrics engine was not found. Cannot serve /metrics on the admin port.")
+		return
+	}
+	router.Handler(http.MethodGet, "/metrics", localprometheus.NewHandler(promRegistry, cfg))
+}
+
+// handleCors applies the configured GET policy to reads and the POST policy to writes. Preflight
+// requests are matched to a policy by the method they ask permission for.
+func handleCors(handler http.Handler, cfg config.CORS) http.Handler {
+	readHandler := cors.New(newCorsOptions(cfg.Get)).Handler(handler)
+	writeHandler := cors.New(newCorsOptions(cfg.Post)).Handler(handler)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.Method
+		if requestedMethod := r.Header.Get("Access-Control-Request-Method"); r.Method == http.MethodOptions && requestedMethod != "" {
+			method = requestedMethod
+		}
+
+		if method == http.MethodPost {
+			writeHandler.ServeHTTP(w, r)
+		} else {
+			readHandler.ServeHTTP(w, r)
+		}
+	})
 }
 
-func handleCors(handler http.Handler) http.Handler {
-	coresCfg := cors.New(cors.Options{AllowCredentials: true, AllowOriginFunc: func(origin string) bool {
-		return true
-	}})
-	return coresCfg.Handler(handler)
+func newCorsOptions(cfg config.CORSPolicy) cors.Options {
+	options := cors.Options{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		MaxAge:           cfg.MaxAgeSeconds,
+		AllowCredentials: cfg.AllowCredentials,
+	}
+	if len(cfg.AllowedOrigins) == 0 {
+		// The cors library allows every origin when the list is empty. An empty list in our config
+		// means cross-origin requests are not allowed at all.
+		options.AllowOriginFunc = func(origin string) bool { return false }
+	}
+	return options
 }
 
-func handleRateLimiting(next http.Handler, cfg config.RateLimiting) http.Handler {
+func handleRateLimiting(next http.Handler, cfg config.RateLimiting, trustedProxies *utils.TrustedProxies) http.Handler {
 	// Sip rate limiter when disabled
 	if !cfg.Enabled {
 		return next
@@ -61,9 +110,32 @@ func handleRateLimiting(next http.Handler, cfg config.RateLimiting) http.Handler
 	limit := tollbooth.NewLimiter(float64(cfg.MaxRequestsPerSecond), &limiter.ExpirableOptions{
 		DefaultExpirationTTL: 1 * time.Hour,
 	})
-	limit.SetIPLookups([]string{"X-Forwarded-For", "X-Real-IP"})
 	limit.SetMessage(`{ "error": "rate limit" }`)
 	limit.SetMessageContentType("application/json")
 
-	return tollbooth.LimitHandler(limit, next)
+	// tollbooth's own IP lookups believe forwarding headers sent by anyone. Key the limiter on the
+	// client address resolved through our trusted proxies instead.
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP := trustedProxies.ClientIP(r)
+		if clientIP == "" {
+			// Requests that don't come from an IP address, such as those of the Unix socket, share a limit
+			clientIP = "unix"
+		}
+
+		if httpError := tollbooth.LimitByKeys(limit, []string{clientIP, r.URL.Path}); httpError != nil {
+			w.Header().Add("Content-Type", limit.GetMessageContentType())
+			w.WriteHeader(httpError.StatusCode)
+			w.Write([]byte(httpError.Message))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newTrustedProxies(cfg config.ClientIP) *utils.TrustedProxies {
+	trustedProxies, err := utils.NewTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid config.client_ip.trusted_proxies: %v", err)
+	}
+	return trustedProxies
 }
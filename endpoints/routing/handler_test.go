@@ -0,0 +1,237 @@
+package routing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/endpoints"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	localprometheus "github.com/prebid/prebid-cache/metrics/prometheus"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleRateLimiting(t *testing.T) {
+	type testRequest struct {
+		remoteAddr     string
+		forwardedFor   string
+		expectedStatus int
+	}
+
+	testCases := []struct {
+		desc     string
+		requests []testRequest
+	}{
+		{
+			desc: "Untrusted client rotates X-Forwarded-For values. Requests are still limited by its own address",
+			requests: []testRequest{
+				{remoteAddr: "203.0.113.5:1000", forwardedFor: "198.51.100.1", expectedStatus: http.StatusOK},
+				{remoteAddr: "203.0.113.5:1001", forwardedFor: "198.51.100.2", expectedStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			desc: "Trusted proxy forwards requests from different clients. Each client gets its own limit",
+			requests: []testRequest{
+				{remoteAddr: "10.0.0.2:1000", forwardedFor: "198.51.100.1", expectedStatus: http.StatusOK},
+				{remoteAddr: "10.0.0.2:1001", forwardedFor: "198.51.100.2", expectedStatus: http.StatusOK},
+				{remoteAddr: "10.0.0.3:1002", forwardedFor: "198.51.100.1", expectedStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			desc: "Client prepends a spoofed hop behind a trusted proxy. Requests are limited by the hop the proxy appended",
+			requests: []testRequest{
+				{remoteAddr: "10.0.0.2:1000", forwardedFor: "192.0.2.1, 198.51.100.1", expectedStatus: http.StatusOK},
+				{remoteAddr: "10.0.0.2:1001", forwardedFor: "192.0.2.2, 198.51.100.1", expectedStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			desc: "Requests without a client address, such as those of the Unix socket, share a limit",
+			requests: []testRequest{
+				{remoteAddr: "", expectedStatus: http.StatusOK},
+				{remoteAddr: "@", expectedStatus: http.StatusTooManyRequests},
+			},
+		},
+	}
+
+	trustedProxies, err := utils.NewTrustedProxies([]string{"10.0.0.0/8"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, tc := range testCases {
+		// One request per second per client, without bursts
+		handler := handleRateLimiting(next, config.RateLimiting{Enabled: true, MaxRequestsPerSecond: 1}, trustedProxies)
+
+		for i, req := range tc.requests {
+			request := httptest.NewRequest("GET", "/cache", nil)
+			request.RemoteAddr = req.remoteAddr
+			request.Header.Set("X-Forwarded-For", req.forwardedFor)
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, req.expectedStatus, recorder.Code, "%s: request %d", tc.desc, i)
+		}
+	}
+}
+
+func TestHandleCors(t *testing.T) {
+	corsConfig := config.CORS{
+		Get: config.CORSPolicy{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET"},
+		},
+		Post: config.CORSPolicy{
+			AllowedOrigins:   []string{"https://*.prebid.org"},
+			AllowedMethods:   []string{"POST"},
+			AllowedHeaders:   []string{"Content-Type"},
+			MaxAgeSeconds:    60,
+			AllowCredentials: true,
+		},
+	}
+
+	testCases := []struct {
+		desc                string
+		method              string
+		origin              string
+		preflightMethod     string
+		expectedAllowOrigin string
+		expectedCredentials string
+		expectedMaxAge      string
+	}{
+		{
+			desc:                "GET from any origin. Wildcard without credentials",
+			method:              "GET",
+			origin:              "https://video-player.com",
+			expectedAllowOrigin: "*",
+		},
+		{
+			desc:                "POST from an allowed subdomain. Origin is echoed along with credentials",
+			method:              "POST",
+			origin:              "https://pbs.prebid.org",
+			expectedAllowOrigin: "https://pbs.prebid.org",
+			expectedCredentials: "true",
+		},
+		{
+			desc:   "POST from an origin outside of the POST policy",
+			method: "POST",
+			origin: "https://video-player.com",
+		},
+		{
+			desc:                "Preflight for a POST from an allowed subdomain uses the POST policy",
+			method:              "OPTIONS",
+			preflightMethod:     "POST",
+			origin:              "https://pbs.prebid.org",
+			expectedAllowOrigin: "https://pbs.prebid.org",
+			expectedCredentials: "true",
+			expectedMaxAge:      "60",
+		},
+		{
+			desc:            "Preflight for a POST from an origin outside of the POST policy",
+			method:          "OPTIONS",
+			preflightMethod: "POST",
+			origin:          "https://video-player.com",
+		},
+		{
+			desc:            "Preflight for a method no policy allows",
+			method:          "OPTIONS",
+			preflightMethod: "DELETE",
+			origin:          "https://pbs.prebid.org",
+		},
+	}
+
+	handler := handleCors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), corsConfig)
+
+	for _, tc := range testCases {
+		request := httptest.NewRequest(tc.method, "/cache", nil)
+		request.Header.Set("Origin", tc.origin)
+		if len(tc.preflightMethod) > 0 {
+			request.Header.Set("Access-Control-Request-Method", tc.preflightMethod)
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, tc.expectedAllowOrigin, recorder.Header().Get("Access-Control-Allow-Origin"), tc.desc)
+		assert.Equal(t, tc.expectedCredentials, recorder.Header().Get("Access-Control-Allow-Credentials"), tc.desc)
+		assert.Equal(t, tc.expectedMaxAge, recorder.Header().Get("Access-Control-Max-Age"), tc.desc)
+	}
+}
+
+func TestNewCorsOptionsWithoutOrigins(t *testing.T) {
+	handler := handleCors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), config.CORS{})
+
+	request := httptest.NewRequest("GET", "/cache", nil)
+	request.Header.Set("Origin", "https://video-player.com")
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"), "An empty list of origins should not allow any origin")
+}
+
+func TestAdminMetricsRoute(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		inAdminEndpoint bool
+		expectedStatus  int
+	}{
+		{
+			desc:            "Metrics served on the admin port",
+			inAdminEndpoint: true,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			desc:            "Metrics served on their own port only",
+			inAdminEndpoint: false,
+			expectedStatus:  http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := config.Configuration{
+			Metrics: config.Metrics{
+				Prometheus: config.PrometheusMetrics{
+					Enabled:       true,
+					Namespace:     "prebid",
+					Subsystem:     "cache",
+					AdminEndpoint: tc.inAdminEndpoint,
+				},
+			},
+		}
+		promMetrics := localprometheus.CreatePrometheusMetrics(cfg.Metrics.Prometheus)
+		promMetrics.RecordBuildInfo("0.1.0", "4b1d3f6", "memory", "snappy")
+		appMetrics := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{promMetrics}}
+		handler := NewAdminHandler(cfg, backends.NewMemoryBackend(), appMetrics, &endpoints.Readiness{}, nil)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+		assert.Equal(t, tc.expectedStatus, recorder.Code, tc.desc)
+		if tc.expectedStatus == http.StatusOK {
+			assert.Contains(t, recorder.Body.String(), `prebid_cache_build_info{backend="memory",compression="snappy",revision="4b1d3f6",version="0.1.0"} 1`, tc.desc)
+		}
+	}
+}
+
+func TestHeadCacheRoute(t *testing.T) {
+	backend := backends.NewMemoryBackend()
+	backend.Put(context.Background(), "b9f3c4a2-1d5e-4f6a-8b7c-9d0e1f2a3b4c", "xml<tag></tag>", 60)
+
+	mockMetrics := metricstest.CreateMockMetrics()
+	appMetrics := &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{mockMetrics}}
+	handler := NewPublicHandler(config.Configuration{}, backend, appMetrics, &endpoints.Readiness{}, nil)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("HEAD", "/cache?uuid=b9f3c4a2-1d5e-4f6a-8b7c-9d0e1f2a3b4c", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+	assert.Empty(t, recorder.Body.String())
+}
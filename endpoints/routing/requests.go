@@ -0,0 +1,155 @@
+package routing
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/tracing"
+	"github.com/prebid/prebid-cache/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// validRequestID limits the request IDs accepted from clients to characters that can't break log lines
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// accessLogger writes the access log as JSON lines, apart from the application log
+var accessLogger = &log.Logger{
+	Out:       os.Stdout,
+	Formatter: &log.JSONFormatter{},
+	Hooks:     make(log.LevelHooks),
+	Level:     log.InfoLevel,
+}
+
+// handleRequests ties every request to an ID and, if enabled, writes it to the access log and
+// records its span
+func handleRequests(next http.Handler, cfg config.AccessLog, tracer *tracing.Tracer) http.Handler {
+	if tracer != nil {
+		next = handleTracing(next, tracer)
+	}
+	if cfg.Enabled {
+		next = handleAccessLog(next)
+	}
+	return handleRequestID(next)
+}
+
+// handleRequestID takes the request ID from the X-Request-ID header or generates one if the client
+// didn't send a valid one. The ID is added to the request context, for the log lines emitted while
+// handling it, and sent back in the response.
+func handleRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(utils.RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			var err error
+			if requestID, err = utils.GenerateRandomID(); err != nil {
+				log.Errorf("Failed to generate a request ID: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set(utils.RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(utils.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// handleErrorFormat asks the endpoints to write their errors as JSON, whatever the Accept header of the
+// request, if the server is configured to
+func handleErrorFormat(next http.Handler, format config.ErrorFormat) http.Handler {
+	if format != config.ErrorFormatJSON {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(utils.WithJSONErrors(r.Context())))
+	})
+}
+
+// handleAccessLog writes a line to the access log once the response has been sent
+func handleAccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		stats := &utils.RequestStats{}
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r.WithContext(utils.WithRequestStats(r.Context(), stats)))
+
+		fields := log.Fields{
+			"method":     r.Method,
+			"route":      r.URL.Path,
+			"status":     recorder.status,
+			"latency_ms": durationMillis(time.Since(start)),
+			"bytes":      recorder.bytes,
+		}
+		if requestID := utils.RequestID(r.Context()); requestID != "" {
+			fields["request_id"] = requestID
+		}
+		if numPuts := stats.NumPuts(); numPuts > 0 {
+			fields["num_puts"] = numPuts
+		}
+		if backendDuration := stats.BackendDuration(); backendDuration > 0 {
+			fields["backend_latency_ms"] = durationMillis(backendDuration)
+		}
+		accessLogger.WithFields(fields).Info("request")
+	})
+}
+
+// handleTracing records a server span for the request, which the spans of its backend calls
+// are children of. If the caller sent a traceparent header, the span joins the caller's trace.
+func handleTracing(next http.Handler, tracer *tracing.Tracer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote, _ := tracing.ParseTraceparent(r.Header.Get(tracing.TraceparentHeader), r.Header.Get(tracing.TracestateHeader))
+		ctx, span := tracer.StartRequestSpan(r.Context(), r.Method+" "+r.URL.Path, remote)
+		defer span.End()
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.target", r.URL.Path)
+		if requestID := utils.RequestID(ctx); requestID != "" {
+			span.SetAttribute("request_id", requestID)
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttribute("http.status_code", recorder.status)
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetError(errors.New(http.StatusText(recorder.status)))
+		}
+	})
+}
+
+// durationMillis converts d to milliseconds, keeping microsecond precision
+func durationMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// responseRecorder remembers the status code and size of the response for the access log and traces
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Flush lets handlers stream responses through the recorder
+func (rec *responseRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
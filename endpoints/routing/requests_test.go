@@ -0,0 +1,248 @@
+package routing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-cache/backends"
+	backendDecorators "github.com/prebid/prebid-cache/backends/decorators"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/endpoints"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/tracing"
+	"github.com/prebid/prebid-cache/tracing/tracingtest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/sirupsen/logrus"
+	testLogrus "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleRequestID(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		inRequestID      string
+		expectedEchoedID bool
+	}{
+		{
+			desc:             "Client sends a valid request ID. It's used for the request",
+			inRequestID:      "4b8e2a0c-7d1f-4d6e-9a3b-5c2f1e0d9a8b",
+			expectedEchoedID: true,
+		},
+		{
+			desc:             "Client doesn't send a request ID. One gets generated",
+			inRequestID:      "",
+			expectedEchoedID: false,
+		},
+		{
+			desc:             "Client sends a request ID with characters that could break log lines. One gets generated",
+			inRequestID:      "abc\"def ghi",
+			expectedEchoedID: false,
+		},
+		{
+			desc:             "Client sends a request ID that is too long. One gets generated",
+			inRequestID:      strings.Repeat("a", 129),
+			expectedEchoedID: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		var contextRequestID string
+		handler := handleRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contextRequestID = utils.RequestID(r.Context())
+		}))
+
+		request := httptest.NewRequest("GET", "/cache", nil)
+		if tc.inRequestID != "" {
+			request.Header.Set(utils.RequestIDHeader, tc.inRequestID)
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		responseRequestID := recorder.Header().Get(utils.RequestIDHeader)
+		assert.NotEmpty(t, responseRequestID, tc.desc)
+		assert.Equal(t, responseRequestID, contextRequestID, tc.desc)
+		if tc.expectedEchoedID {
+			assert.Equal(t, tc.inRequestID, responseRequestID, tc.desc)
+		} else {
+			assert.NotEqual(t, tc.inRequestID, responseRequestID, tc.desc)
+			assert.Regexp(t, validRequestID, responseRequestID, tc.desc)
+		}
+	}
+}
+
+func TestHandleAccessLog(t *testing.T) {
+	hook := testLogrus.NewLocal(accessLogger)
+	defer hook.Reset()
+
+	m := newTestMetrics()
+	backend := backendDecorators.LogMetrics(backends.NewMemoryBackend(), m)
+	router := httprouter.New()
+	router.POST("/cache", endpoints.NewPutHandler(backend, m, 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, false, config.Keys{}))
+
+	handler := handleRequests(router, config.AccessLog{Enabled: true}, nil)
+
+	body := `{"puts":[{"type":"json","value":"one"},{"type":"json","value":"two"}]}`
+	request := httptest.NewRequest("POST", "/cache", strings.NewReader(body))
+	request.Header.Set(utils.RequestIDHeader, "request-1")
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	if !assert.Equal(t, http.StatusOK, recorder.Code) || !assert.Len(t, hook.Entries, 1) {
+		return
+	}
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, "request", entry.Message)
+	assert.Equal(t, "POST", entry.Data["method"])
+	assert.Equal(t, "/cache", entry.Data["route"])
+	assert.Equal(t, http.StatusOK, entry.Data["status"])
+	assert.Equal(t, recorder.Body.Len(), entry.Data["bytes"])
+	assert.Equal(t, "request-1", entry.Data["request_id"])
+	assert.Equal(t, 2, entry.Data["num_puts"])
+	assert.Contains(t, entry.Data, "latency_ms")
+	assert.Contains(t, entry.Data, "backend_latency_ms")
+}
+
+func TestHandleAccessLogDisabled(t *testing.T) {
+	hook := testLogrus.NewLocal(accessLogger)
+	defer hook.Reset()
+
+	handler := handleRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), config.AccessLog{Enabled: false}, nil)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/status", nil))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get(utils.RequestIDHeader))
+	assert.Empty(t, hook.Entries)
+}
+
+func TestResponseRecorderKeepsFirstStatus(t *testing.T) {
+	hook := testLogrus.NewLocal(accessLogger)
+	defer hook.Reset()
+
+	handler := handleAccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/cache?uuid=missing", nil))
+
+	if !assert.Len(t, hook.Entries, 1) {
+		return
+	}
+	assert.Equal(t, http.StatusNotFound, hook.LastEntry().Data["status"])
+	assert.Equal(t, "/cache", hook.LastEntry().Data["route"])
+	assert.NotContains(t, hook.LastEntry().Data, "num_puts")
+	assert.NotContains(t, hook.LastEntry().Data, "backend_latency_ms")
+}
+
+func TestHandleTracing(t *testing.T) {
+	tracer, exporter := tracingtest.NewTracer()
+	backend := backendDecorators.TraceBackend(backends.NewMemoryBackend(), "memory")
+	router := httprouter.New()
+	router.POST("/cache", endpoints.NewPutHandler(backend, newTestMetrics(), 10, utils.REQUEST_MAX_BODY_SIZE_BYTES, utils.REQUEST_MAX_DECOMPRESSED_BYTES, false, config.Keys{}))
+
+	handler := handleRequests(router, config.AccessLog{}, tracer)
+
+	body := `{"puts":[{"type":"json","value":"one"},{"type":"json","value":"two"}]}`
+	request := httptest.NewRequest("POST", "/cache", strings.NewReader(body))
+	request.Header.Set(utils.RequestIDHeader, "request-1")
+	request.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	requestSpan := spans[2]
+	assert.Equal(t, "POST /cache", requestSpan.Name)
+	assert.Equal(t, tracing.SpanKindServer, requestSpan.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", requestSpan.SpanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", requestSpan.ParentSpanID.String())
+	assert.Equal(t, map[string]interface{}{
+		"http.method":      "POST",
+		"http.target":      "/cache",
+		"http.status_code": http.StatusOK,
+		"request_id":       "request-1",
+	}, requestSpan.Attributes)
+	assert.Empty(t, requestSpan.Err)
+
+	for _, backendSpan := range spans[:2] {
+		assert.Equal(t, "backend.put", backendSpan.Name)
+		assert.Equal(t, requestSpan.SpanContext.TraceID, backendSpan.SpanContext.TraceID)
+		assert.Equal(t, requestSpan.SpanContext.SpanID, backendSpan.ParentSpanID)
+	}
+}
+
+func TestHandleTracingServerErrors(t *testing.T) {
+	tracer, exporter := tracingtest.NewTracer()
+	handler := handleRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}), config.AccessLog{}, tracer)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status", nil))
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	assert.Equal(t, "GET /status", spans[0].Name)
+	assert.Equal(t, http.StatusServiceUnavailable, spans[0].Attributes["http.status_code"])
+	assert.Equal(t, "Service Unavailable", spans[0].Err)
+	assert.Equal(t, "0000000000000000", spans[0].ParentSpanID.String(), "A request without a traceparent starts a new trace")
+}
+
+func newTestMetrics() *metrics.Metrics {
+	mockMetrics := metricstest.CreateMockMetrics()
+	return &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			mockMetrics,
+		},
+	}
+}
+
+func TestHandleErrorFormat(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		inFormat           config.ErrorFormat
+		expectedJSONErrors bool
+	}{
+		{
+			desc:               "Text errors, the endpoints decide from the Accept header",
+			inFormat:           config.ErrorFormatText,
+			expectedJSONErrors: false,
+		},
+		{
+			desc:               "JSON errors",
+			inFormat:           config.ErrorFormatJSON,
+			expectedJSONErrors: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		var jsonErrors bool
+		handler := handleErrorFormat(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			jsonErrors = utils.JSONErrors(r.Context())
+		}), tc.inFormat)
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/cache", nil))
+
+		assert.Equal(t, tc.expectedJSONErrors, jsonErrors, tc.desc)
+	}
+}
@@ -2,13 +2,42 @@ package endpoints
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	"github.com/julienschmidt/httprouter"
 )
 
-// Status is the handler function of the "/status" endpoint
+// Readiness tells load balancers, through the "/status" endpoint, whether the server should receive
+// more traffic. A server is ready until it starts shutting down.
+type Readiness struct {
+	shuttingDown int32
+}
+
+// SetShuttingDown makes "/status" report that the server is not ready anymore
+func (r *Readiness) SetShuttingDown() {
+	atomic.StoreInt32(&r.shuttingDown, 1)
+}
+
+// Ready returns false once the server started shutting down
+func (r *Readiness) Ready() bool {
+	return atomic.LoadInt32(&r.shuttingDown) == 0
+}
+
+// Status is the handler function of the "/status" endpoint of a server that is always ready
 func Status(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	// We might want more logic here eventually... but for now, we're ok to serve more traffic as
 	// long as the server responds.
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// NewStatusHandler returns the handler function of the "/status" endpoint. It responds with a 204
+// while the server is ready and with a 503 once it started shutting down.
+func NewStatusHandler(readiness *Readiness) func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if !readiness.Ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		Status(w, r, ps)
+	}
+}
@@ -0,0 +1,24 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusEndpoint(t *testing.T) {
+	readiness := &Readiness{}
+	handler := NewStatusHandler(readiness)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "/status", nil), nil)
+	assert.Equal(t, http.StatusNoContent, recorder.Code, "Ready server")
+
+	readiness.SetShuttingDown()
+
+	recorder = httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "/status", nil), nil)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "Server shutting down")
+}
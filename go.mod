@@ -19,7 +19,8 @@ require (
 	github.com/sirupsen/logrus v1.6.0
 	github.com/spf13/viper v1.11.0
 	github.com/stretchr/testify v1.7.1
-	github.com/vrischmann/go-metrics-influxdb v0.1.1
 	github.com/yuin/gopher-lua v0.0.0-20220504180219-658193537a64 // indirect
+	golang.org/x/net v0.0.0-20220412020605-290c469a71a5
 	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
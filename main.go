@@ -1,31 +1,67 @@
 package main
 
 import (
+	"context"
+	"net/http"
 	"os"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/prebid/prebid-cache/backends"
 	backendConfig "github.com/prebid/prebid-cache/backends/config"
 	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/endpoints"
 	"github.com/prebid/prebid-cache/endpoints/routing"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/server"
+	"github.com/prebid/prebid-cache/tracing"
 )
 
 const configFileName = "config"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == validateConfigCommand {
+		os.Exit(validateConfig(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	log.SetOutput(os.Stdout)
 	cfg := config.NewConfig(configFileName)
 	setLogLevel(cfg.Log.Level)
-	cfg.ValidateAndLog()
+	if err := cfg.ValidateAndLog(); err != nil {
+		log.Fatalf("%s", err.Error())
+	}
 
 	appMetrics := metrics.CreateMetrics(cfg)
-	backend := backendConfig.NewBackend(cfg, appMetrics)
-	publicHandler := routing.NewPublicHandler(cfg, backend, appMetrics)
-	adminHandler := routing.NewAdminHandler(cfg, backend, appMetrics)
+	// The tracer is created once, its settings can only change with a restart
+	tracer, err := tracing.NewTracer(cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	baseBackend := backendConfig.NewBaseBackend(cfg, appMetrics)
+	readiness := &endpoints.Readiness{}
+	reloader := server.NewReloader(cfg, loadConfig, func(cfg config.Configuration) (http.Handler, http.Handler) {
+		// Backend connections are kept across reloads. Only the decorators that enforce the
+		// request limits are rebuilt, and rotated secrets are passed to the backends that support them.
+		if updater, ok := baseBackend.(backends.CredentialsUpdater); ok {
+			updater.UpdateCredentials(cfg.Backend)
+		}
+		backend := backendConfig.DecorateBackend(cfg, baseBackend, appMetrics)
+		return routing.NewPublicHandler(cfg, backend, appMetrics, readiness, tracer), routing.NewAdminHandler(cfg, backend, appMetrics, readiness, tracer)
+	})
 	go appMetrics.Export(cfg)
-	server.Listen(cfg, publicHandler, adminHandler, appMetrics)
+	server.Listen(cfg, reloader, appMetrics, readiness, baseBackend)
+
+	// Export the spans of the requests drained while shutting down
+	shutdownCfg := reloader.Config().Shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownCfg.CloseTimeout())
+	defer cancel()
+	if err := tracer.Shutdown(ctx); err != nil {
+		log.Errorf("Failed to export the remaining spans: %v", err)
+	}
+}
+
+func loadConfig() (config.Configuration, error) {
+	return config.LoadConfig(configFileName)
 }
 
 func setLogLevel(logLevel config.LogLevel) {
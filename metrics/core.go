@@ -6,6 +6,9 @@ import (
 	"github.com/prebid/prebid-cache/config"
 	influx "github.com/prebid/prebid-cache/metrics/influx"
 	prometheus "github.com/prebid/prebid-cache/metrics/prometheus"
+	statsd "github.com/prebid/prebid-cache/metrics/statsd"
+	"github.com/prebid/prebid-cache/version"
+	log "github.com/sirupsen/logrus"
 )
 
 // Metrics provides access to metric engines.
@@ -44,6 +47,36 @@ func (m Metrics) RecordPutKeyProvided() {
 	}
 }
 
+func (m Metrics) RecordPutDedupHit() {
+	for _, me := range m.MetricEngines {
+		me.RecordPutDedupHit()
+	}
+}
+
+func (m Metrics) RecordPutKeyCollision() {
+	for _, me := range m.MetricEngines {
+		me.RecordPutKeyCollision()
+	}
+}
+
+func (m Metrics) RecordPutRetriesExhausted() {
+	for _, me := range m.MetricEngines {
+		me.RecordPutRetriesExhausted()
+	}
+}
+
+func (m Metrics) RecordPutCompressedSize(sizeInBytes float64) {
+	for _, me := range m.MetricEngines {
+		me.RecordPutCompressedSize(sizeInBytes)
+	}
+}
+
+func (m Metrics) RecordPutDecompressedSize(sizeInBytes float64) {
+	for _, me := range m.MetricEngines {
+		me.RecordPutDecompressedSize(sizeInBytes)
+	}
+}
+
 func (m Metrics) RecordGetError() {
 	for _, me := range m.MetricEngines {
 		me.RecordGetError()
@@ -128,6 +161,54 @@ func (m Metrics) RecordGetBackendError() {
 	}
 }
 
+func (m Metrics) RecordGetBackendHit() {
+	for _, me := range m.MetricEngines {
+		me.RecordGetBackendHit()
+	}
+}
+
+func (m Metrics) RecordGetPeerTotal() {
+	for _, me := range m.MetricEngines {
+		me.RecordGetPeerTotal()
+	}
+}
+
+func (m Metrics) RecordGetPeerDuration(duration time.Duration) {
+	for _, me := range m.MetricEngines {
+		me.RecordGetPeerDuration(duration)
+	}
+}
+
+func (m Metrics) RecordGetPeerError() {
+	for _, me := range m.MetricEngines {
+		me.RecordGetPeerError()
+	}
+}
+
+func (m Metrics) RecordGetPeerHit() {
+	for _, me := range m.MetricEngines {
+		me.RecordGetPeerHit()
+	}
+}
+
+func (m Metrics) RecordGetBackendJsonSize(sizeInBytes float64) {
+	for _, me := range m.MetricEngines {
+		me.RecordGetBackendJsonSize(sizeInBytes)
+	}
+}
+
+func (m Metrics) RecordGetBackendXmlSize(sizeInBytes float64) {
+	for _, me := range m.MetricEngines {
+		me.RecordGetBackendXmlSize(sizeInBytes)
+	}
+}
+
+func (m Metrics) RecordGetBackendValueAge(age time.Duration) {
+	for _, me := range m.MetricEngines {
+		me.RecordGetBackendValueAge(age)
+	}
+}
+
 func (m Metrics) RecordKeyNotFoundError() {
 	for _, me := range m.MetricEngines {
 		me.RecordKeyNotFoundError()
@@ -164,9 +245,11 @@ func (m Metrics) RecordAcceptConnectionErrors() {
 	}
 }
 
+// Export starts the publishing services of every engine. Some engines, like Influx and StatsD,
+// block while they publish, so each one runs in its own goroutine.
 func (m Metrics) Export(cfg config.Configuration) {
 	for _, me := range m.MetricEngines {
-		me.Export(cfg.Metrics)
+		go me.Export(cfg.Metrics)
 	}
 }
 
@@ -191,6 +274,11 @@ type CacheMetrics interface {
 	RecordPutTotal()
 	RecordPutDuration(duration time.Duration)
 	RecordPutKeyProvided()
+	RecordPutDedupHit()
+	RecordPutKeyCollision()
+	RecordPutRetriesExhausted()
+	RecordPutCompressedSize(sizeInBytes float64)
+	RecordPutDecompressedSize(sizeInBytes float64)
 	RecordGetError()
 	RecordGetBadRequest()
 	RecordGetTotal()
@@ -205,6 +293,14 @@ type CacheMetrics interface {
 	RecordGetBackendTotal()
 	RecordGetBackendDuration(duration time.Duration)
 	RecordGetBackendError()
+	RecordGetBackendHit()
+	RecordGetBackendJsonSize(sizeInBytes float64)
+	RecordGetBackendXmlSize(sizeInBytes float64)
+	RecordGetBackendValueAge(age time.Duration)
+	RecordGetPeerTotal()
+	RecordGetPeerDuration(duration time.Duration)
+	RecordGetPeerError()
+	RecordGetPeerHit()
 	RecordKeyNotFoundError()
 	RecordMissingKeyError()
 	RecordConnectionOpen()
@@ -214,13 +310,22 @@ type CacheMetrics interface {
 }
 
 func CreateMetrics(cfg config.Configuration) *Metrics {
-	engineList := make([]CacheMetrics, 0, 2)
+	engineList := make([]CacheMetrics, 0, 3)
 
 	if cfg.Metrics.Influx.Enabled {
-		engineList = append(engineList, influx.CreateInfluxMetrics())
+		engineList = append(engineList, influx.CreateInfluxMetrics(cfg.Metrics.Influx))
 	}
 	if cfg.Metrics.Prometheus.Enabled {
-		engineList = append(engineList, prometheus.CreatePrometheusMetrics(cfg.Metrics.Prometheus))
+		promMetrics := prometheus.CreatePrometheusMetrics(cfg.Metrics.Prometheus)
+		promMetrics.RecordBuildInfo(version.Ver, version.Rev, string(cfg.Backend.Type), string(cfg.Compression.Type))
+		engineList = append(engineList, promMetrics)
+	}
+	if cfg.Metrics.StatsD.Enabled {
+		statsdMetrics, err := statsd.CreateStatsDMetrics(cfg.Metrics.StatsD)
+		if err != nil {
+			log.Fatalf("Failed to create the StatsD metrics: %v", err)
+		}
+		engineList = append(engineList, statsdMetrics)
 	}
 	return &Metrics{MetricEngines: engineList}
 }
@@ -7,22 +7,24 @@ import (
 	"github.com/prebid/prebid-cache/config"
 	"github.com/rcrowley/go-metrics"
 	"github.com/sirupsen/logrus"
-	influxdb "github.com/vrischmann/go-metrics-influxdb"
 )
 
-const TenSeconds time.Duration = time.Second * 10
-
 const MetricsInfluxDB = "InfluxDB"
 
 type InfluxMetrics struct {
 	Registry    metrics.Registry
 	Puts        *InfluxMetricsEntry
+	PutsBody    *InfluxMetricsPutBody
 	Gets        *InfluxMetricsEntry
 	PutsBackend *InfluxMetricsEntryByFormat
 	GetsBackend *InfluxMetricsEntry
 	GetsErr     *InfluxMetricsGetErrors
+	GetsValues  *InfluxMetricsGetValues
+	GetsPeer    *InfluxMetricsEntry
 	Connections *InfluxConnectionMetrics
-	MetricsName string
+	// DroppedPoints counts the points that couldn't be written to Influx
+	DroppedPoints metrics.Counter
+	MetricsName   string
 }
 
 type InfluxMetricsEntry struct {
@@ -31,6 +33,10 @@ type InfluxMetricsEntry struct {
 	BadRequest metrics.Meter
 	Request    metrics.Meter
 	Update     metrics.Meter
+	DedupHit   metrics.Meter
+	Collision  metrics.Meter
+	Exhausted  metrics.Meter
+	Hit        metrics.Meter
 }
 
 type InfluxMetricsEntryByFormat struct {
@@ -45,6 +51,12 @@ type InfluxMetricsEntryByFormat struct {
 	RequestTTL     metrics.Timer
 }
 
+// InfluxMetricsPutBody describe the bodies of the compressed put requests
+type InfluxMetricsPutBody struct {
+	CompressedBytes   metrics.Histogram
+	DecompressedBytes metrics.Histogram
+}
+
 type InfluxConnectionMetrics struct {
 	ActiveConnections      metrics.Counter
 	ConnectionCloseErrors  metrics.Meter
@@ -56,6 +68,23 @@ type InfluxMetricsGetErrors struct {
 	MissingKeyErrors  metrics.Meter
 }
 
+// InfluxMetricsGetValues describe the values the backend found
+type InfluxMetricsGetValues struct {
+	Hits           metrics.Meter
+	JsonValueBytes metrics.Histogram
+	XmlValueBytes  metrics.Histogram
+	ValueAge       metrics.Timer
+}
+
+func NewInfluxGetValueMetrics(name string, r metrics.Registry) *InfluxMetricsGetValues {
+	return &InfluxMetricsGetValues{
+		Hits:           metrics.GetOrRegisterMeter(fmt.Sprintf("%s.hit_count", name), r),
+		JsonValueBytes: metrics.GetOrRegisterHistogram(name+".json_value_size_bytes", r, metrics.NewExpDecaySample(1028, 0.015)),
+		XmlValueBytes:  metrics.GetOrRegisterHistogram(name+".xml_value_size_bytes", r, metrics.NewExpDecaySample(1028, 0.015)),
+		ValueAge:       metrics.GetOrRegisterTimer(fmt.Sprintf("%s.value_age", name), r),
+	}
+}
+
 func NewInfluxGetErrorMetrics(name string, r metrics.Registry) *InfluxMetricsGetErrors {
 	return &InfluxMetricsGetErrors{
 		KeyNotFoundErrors: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.key_not_found", name), r),
@@ -74,8 +103,22 @@ func NewInfluxMetricsEntryGet(name string, r metrics.Registry) *InfluxMetricsEnt
 	}
 }
 
+// NewInfluxMetricsEntryGetPeer initializes the metrics of InfluxMetricsEntry that describe the get requests
+// forwarded to peer Prebid Cache clusters, including Hit which will account for the values they found
+func NewInfluxMetricsEntryGetPeer(name string, r metrics.Registry) *InfluxMetricsEntry {
+	return &InfluxMetricsEntry{
+		Duration: metrics.GetOrRegisterTimer(fmt.Sprintf("%s.request_duration", name), r),
+		Errors:   metrics.GetOrRegisterMeter(fmt.Sprintf("%s.error_count", name), r),
+		Request:  metrics.GetOrRegisterMeter(fmt.Sprintf("%s.request_count", name), r),
+		Hit:      metrics.GetOrRegisterMeter(fmt.Sprintf("%s.hit_count", name), r),
+	}
+}
+
 // NewInfluxMetricsEntryEndpointPuts initializes all the metrics of InfluxMetricsEntry including
-// Update which will account for the Put requests that come with their own Key to store the value in.
+// Update which will account for the Put requests that come with their own Key to store the value in,
+// DedupHit which will account for the values that were already stored under their content key, Collision
+// which will account for the generated keys that were already taken, and Exhausted which will account for the
+// values that couldn't be stored because every key generated for them was taken.
 func NewInfluxMetricsEntryEndpointPuts(name string, r metrics.Registry) *InfluxMetricsEntry {
 	return &InfluxMetricsEntry{
 		Duration:   metrics.GetOrRegisterTimer(fmt.Sprintf("%s.request_duration", name), r),
@@ -83,6 +126,9 @@ func NewInfluxMetricsEntryEndpointPuts(name string, r metrics.Registry) *InfluxM
 		BadRequest: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.bad_request_count", name), r),
 		Request:    metrics.GetOrRegisterMeter(fmt.Sprintf("%s.request_count", name), r),
 		Update:     metrics.GetOrRegisterMeter(fmt.Sprintf("%s.updated_key_count", name), r),
+		DedupHit:   metrics.GetOrRegisterMeter(fmt.Sprintf("%s.dedup_hit_count", name), r),
+		Collision:  metrics.GetOrRegisterMeter(fmt.Sprintf("%s.key_collision_count", name), r),
+		Exhausted:  metrics.GetOrRegisterMeter(fmt.Sprintf("%s.collision_retries_exhausted_count", name), r),
 	}
 }
 
@@ -99,6 +145,13 @@ func NewInfluxMetricsEntryBackendPuts(name string, r metrics.Registry) *InfluxMe
 	}
 }
 
+func NewInfluxPutBodyMetrics(name string, r metrics.Registry) *InfluxMetricsPutBody {
+	return &InfluxMetricsPutBody{
+		CompressedBytes:   metrics.GetOrRegisterHistogram(name+".compressed_size_bytes", r, metrics.NewExpDecaySample(1028, 0.015)),
+		DecompressedBytes: metrics.GetOrRegisterHistogram(name+".decompressed_size_bytes", r, metrics.NewExpDecaySample(1028, 0.015)),
+	}
+}
+
 func NewInfluxConnectionMetrics(r metrics.Registry) *InfluxConnectionMetrics {
 	return &InfluxConnectionMetrics{
 		ActiveConnections:      metrics.GetOrRegisterCounter("connections.active_incoming", r),
@@ -107,18 +160,22 @@ func NewInfluxConnectionMetrics(r metrics.Registry) *InfluxConnectionMetrics {
 	}
 }
 
-func CreateInfluxMetrics() *InfluxMetrics {
-	flushTime := TenSeconds
+func CreateInfluxMetrics(cfg config.InfluxMetrics) *InfluxMetrics {
+	flushTime := cfg.FlushInterval()
 	r := metrics.NewPrefixedRegistry("prebidcache.")
 	m := &InfluxMetrics{
-		Registry:    r,
-		Puts:        NewInfluxMetricsEntryEndpointPuts("puts.current_url", r),
-		Gets:        NewInfluxMetricsEntryGet("gets.current_url", r),
-		PutsBackend: NewInfluxMetricsEntryBackendPuts("puts.backend", r),
-		GetsBackend: NewInfluxMetricsEntryGet("gets.backend", r),
-		GetsErr:     NewInfluxGetErrorMetrics("gets.backend_error", r),
-		Connections: NewInfluxConnectionMetrics(r),
-		MetricsName: MetricsInfluxDB,
+		Registry:      r,
+		Puts:          NewInfluxMetricsEntryEndpointPuts("puts.current_url", r),
+		PutsBody:      NewInfluxPutBodyMetrics("puts.current_url", r),
+		Gets:          NewInfluxMetricsEntryGet("gets.current_url", r),
+		PutsBackend:   NewInfluxMetricsEntryBackendPuts("puts.backend", r),
+		GetsBackend:   NewInfluxMetricsEntryGet("gets.backend", r),
+		GetsErr:       NewInfluxGetErrorMetrics("gets.backend_error", r),
+		GetsValues:    NewInfluxGetValueMetrics("gets.backend", r),
+		GetsPeer:      NewInfluxMetricsEntryGetPeer("gets.peer", r),
+		Connections:   NewInfluxConnectionMetrics(r),
+		DroppedPoints: metrics.GetOrRegisterCounter("influx.dropped_points", r),
+		MetricsName:   MetricsInfluxDB,
 	}
 
 	metrics.RegisterDebugGCStats(m.Registry)
@@ -130,19 +187,10 @@ func CreateInfluxMetrics() *InfluxMetrics {
 	return m
 }
 
-// Export begins metric publishing services.
+// Export begins metric publishing services. It blocks, writing the metrics every flush interval.
 func (m InfluxMetrics) Export(cfg config.Metrics) {
 	logrus.Infof("Metrics will be exported to Influx with host=%s, db=%s, username=%s", cfg.Influx.Host, cfg.Influx.Database, cfg.Influx.Username)
-	influxdb.InfluxDB(
-		m.Registry,
-		TenSeconds,
-		cfg.Influx.Host,
-		cfg.Influx.Database,
-		cfg.Influx.Measurement,
-		cfg.Influx.Username,
-		cfg.Influx.Password,
-		cfg.Influx.AlignTimestamps,
-	)
+	newInfluxReporter(m.Registry, m.DroppedPoints, cfg.Influx).run()
 }
 
 func (m *InfluxMetrics) GetEngineRegistry() interface{} {
@@ -173,6 +221,26 @@ func (m *InfluxMetrics) RecordPutKeyProvided() {
 	m.Puts.Update.Mark(1)
 }
 
+func (m *InfluxMetrics) RecordPutDedupHit() {
+	m.Puts.DedupHit.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordPutKeyCollision() {
+	m.Puts.Collision.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordPutRetriesExhausted() {
+	m.Puts.Exhausted.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordPutCompressedSize(sizeInBytes float64) {
+	m.PutsBody.CompressedBytes.Update(int64(sizeInBytes))
+}
+
+func (m *InfluxMetrics) RecordPutDecompressedSize(sizeInBytes float64) {
+	m.PutsBody.DecompressedBytes.Update(int64(sizeInBytes))
+}
+
 func (m *InfluxMetrics) RecordGetError() {
 	m.Gets.Errors.Mark(1)
 }
@@ -229,6 +297,38 @@ func (m *InfluxMetrics) RecordGetBackendError() {
 	m.GetsBackend.Errors.Mark(1)
 }
 
+func (m *InfluxMetrics) RecordGetBackendHit() {
+	m.GetsValues.Hits.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordGetBackendJsonSize(sizeInBytes float64) {
+	m.GetsValues.JsonValueBytes.Update(int64(sizeInBytes))
+}
+
+func (m *InfluxMetrics) RecordGetBackendXmlSize(sizeInBytes float64) {
+	m.GetsValues.XmlValueBytes.Update(int64(sizeInBytes))
+}
+
+func (m *InfluxMetrics) RecordGetBackendValueAge(age time.Duration) {
+	m.GetsValues.ValueAge.Update(age)
+}
+
+func (m *InfluxMetrics) RecordGetPeerTotal() {
+	m.GetsPeer.Request.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordGetPeerDuration(duration time.Duration) {
+	m.GetsPeer.Duration.Update(duration)
+}
+
+func (m *InfluxMetrics) RecordGetPeerError() {
+	m.GetsPeer.Errors.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordGetPeerHit() {
+	m.GetsPeer.Hit.Mark(1)
+}
+
 func (m *InfluxMetrics) RecordKeyNotFoundError() {
 	m.GetsErr.KeyNotFoundErrors.Mark(1)
 }
@@ -5,12 +5,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prebid/prebid-cache/config"
 	"github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestRegisteredInfluxMetrics(t *testing.T) {
-	m := CreateInfluxMetrics()
+	m := CreateInfluxMetrics(config.InfluxMetrics{FlushIntervalMillis: 10000})
 
 	testCases := []struct {
 		metricName, expectedMetricObject string
@@ -21,6 +22,9 @@ func TestRegisteredInfluxMetrics(t *testing.T) {
 		{"puts.current_url.bad_request_count", "Meter"},
 		{"puts.current_url.request_count", "Meter"},
 		{"puts.current_url.updated_key_count", "Meter"},
+		{"puts.current_url.dedup_hit_count", "Meter"},
+		{"puts.current_url.key_collision_count", "Meter"},
+		{"puts.current_url.collision_retries_exhausted_count", "Meter"},
 
 		// Gets:
 		{"gets.current_url.request_duration", "Timer"},
@@ -28,6 +32,10 @@ func TestRegisteredInfluxMetrics(t *testing.T) {
 		{"gets.current_url.bad_request_count", "Meter"},
 		{"gets.current_url.request_count", "Meter"},
 
+		// Puts Body:
+		{"puts.current_url.compressed_size_bytes", "Histogram"},
+		{"puts.current_url.decompressed_size_bytes", "Histogram"},
+
 		// Puts Backend:
 		{"puts.backend.request_duration", "Timer"},
 		{"puts.backend.error_count", "Meter"},
@@ -44,6 +52,18 @@ func TestRegisteredInfluxMetrics(t *testing.T) {
 		{"gets.backend.bad_request_count", "Meter"},
 		{"gets.backend.request_count", "Meter"},
 
+		// Gets Backend Values:
+		{"gets.backend.hit_count", "Meter"},
+		{"gets.backend.json_value_size_bytes", "Histogram"},
+		{"gets.backend.xml_value_size_bytes", "Histogram"},
+		{"gets.backend.value_age", "Timer"},
+
+		// Gets Peer:
+		{"gets.peer.request_duration", "Timer"},
+		{"gets.peer.error_count", "Meter"},
+		{"gets.peer.request_count", "Meter"},
+		{"gets.peer.hit_count", "Meter"},
+
 		// Gets Backend Errors:
 		{"gets.backend_error.key_not_found", "Meter"},
 		{"gets.backend_error.missing_key", "Meter"},
@@ -52,6 +72,9 @@ func TestRegisteredInfluxMetrics(t *testing.T) {
 		{"connections.active_incoming", "Counter"},
 		{"connections.accept_errors", "Meter"},
 		{"connections.close_errors", "Meter"},
+
+		// Influx:
+		{"influx.dropped_points", "Counter"},
 	}
 
 	for _, test := range testCases {
@@ -76,7 +99,7 @@ func TestRegisteredInfluxMetrics(t *testing.T) {
 func TestAllRecorders(t *testing.T) {
 	var fiveSeconds time.Duration = time.Second * 5
 
-	m := CreateInfluxMetrics()
+	m := CreateInfluxMetrics(config.InfluxMetrics{FlushIntervalMillis: 10000})
 
 	type testCase struct {
 		description    string
@@ -116,6 +139,36 @@ func TestAllRecorders(t *testing.T) {
 					runTest:        func(im *InfluxMetrics) { im.RecordPutKeyProvided() },
 					metricToAssert: m.Puts.Update,
 				},
+				{
+					description:    "record a put element whose value was already stored under its content key",
+					runTest:        func(im *InfluxMetrics) { im.RecordPutDedupHit() },
+					metricToAssert: m.Puts.DedupHit,
+				},
+				{
+					description:    "record a put element whose generated key was already taken",
+					runTest:        func(im *InfluxMetrics) { im.RecordPutKeyCollision() },
+					metricToAssert: m.Puts.Collision,
+				},
+				{
+					description:    "record a put element that ran out of keys to retry with",
+					runTest:        func(im *InfluxMetrics) { im.RecordPutRetriesExhausted() },
+					metricToAssert: m.Puts.Exhausted,
+				},
+			},
+		},
+		{
+			"m.PutsBody",
+			[]testCase{
+				{
+					description:    "record the size of a compressed put request body with RecordPutCompressedSize",
+					runTest:        func(im *InfluxMetrics) { im.RecordPutCompressedSize(float64(1)) },
+					metricToAssert: m.PutsBody.CompressedBytes,
+				},
+				{
+					description:    "record the decompressed size of a put request body with RecordPutDecompressedSize",
+					runTest:        func(im *InfluxMetrics) { im.RecordPutDecompressedSize(float64(1)) },
+					metricToAssert: m.PutsBody.DecompressedBytes,
+				},
 			},
 		},
 		{
@@ -203,6 +256,56 @@ func TestAllRecorders(t *testing.T) {
 				},
 			},
 		},
+		{
+			"m.GetsPeer",
+			[]testCase{
+				{
+					description:    "Five second RecordGetPeerDuration",
+					runTest:        func(im *InfluxMetrics) { im.RecordGetPeerDuration(fiveSeconds) },
+					metricToAssert: m.GetsPeer.Duration,
+				},
+				{
+					description:    "record a failed get request to a peer with RecordGetPeerError",
+					runTest:        func(im *InfluxMetrics) { im.RecordGetPeerError() },
+					metricToAssert: m.GetsPeer.Errors,
+				},
+				{
+					description:    "record a get request forwarded to a peer with RecordGetPeerTotal",
+					runTest:        func(im *InfluxMetrics) { im.RecordGetPeerTotal() },
+					metricToAssert: m.GetsPeer.Request,
+				},
+				{
+					description:    "record a value found by a peer with RecordGetPeerHit",
+					runTest:        func(im *InfluxMetrics) { im.RecordGetPeerHit() },
+					metricToAssert: m.GetsPeer.Hit,
+				},
+			},
+		},
+		{
+			"m.GetsValues",
+			[]testCase{
+				{
+					description:    "record a value found by the backend with RecordGetBackendHit",
+					runTest:        func(im *InfluxMetrics) { im.RecordGetBackendHit() },
+					metricToAssert: m.GetsValues.Hits,
+				},
+				{
+					description:    "record the size of a JSON value found by the backend with RecordGetBackendJsonSize",
+					runTest:        func(im *InfluxMetrics) { im.RecordGetBackendJsonSize(float64(1)) },
+					metricToAssert: m.GetsValues.JsonValueBytes,
+				},
+				{
+					description:    "record the size of an XML value found by the backend with RecordGetBackendXmlSize",
+					runTest:        func(im *InfluxMetrics) { im.RecordGetBackendXmlSize(float64(1)) },
+					metricToAssert: m.GetsValues.XmlValueBytes,
+				},
+				{
+					description:    "record the age of a value found by the backend with RecordGetBackendValueAge",
+					runTest:        func(im *InfluxMetrics) { im.RecordGetBackendValueAge(fiveSeconds) },
+					metricToAssert: m.GetsValues.ValueAge,
+				},
+			},
+		},
 		{
 			"m.GetsBackErr",
 			[]testCase{
@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/rcrowley/go-metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// influxReporter writes the metrics of a registry to the Influx HTTP write API in the line protocol,
+// with the fields and bucket tags go-metrics-influxdb used to write. The points that can't be written
+// are kept in a bounded buffer and written again along with the next ones.
+type influxReporter struct {
+	registry     metrics.Registry
+	client       *http.Client
+	writeURL     string
+	username     string
+	password     string
+	measurement  string
+	tags         map[string]string
+	interval     time.Duration
+	align        bool
+	bufferSize   int
+	maxRetries   int
+	retryBackoff time.Duration
+	dropped      metrics.Counter
+
+	buffer []string
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+func newInfluxReporter(registry metrics.Registry, dropped metrics.Counter, cfg config.InfluxMetrics) *influxReporter {
+	return &influxReporter{
+		registry:     registry,
+		client:       &http.Client{Timeout: cfg.FlushInterval()},
+		writeURL:     strings.TrimSuffix(cfg.Host, "/") + "/write?" + url.Values{"db": []string{cfg.Database}}.Encode(),
+		username:     cfg.Username,
+		password:     cfg.Password,
+		measurement:  cfg.Measurement,
+		tags:         cfg.ExpandedTags(),
+		interval:     cfg.FlushInterval(),
+		align:        cfg.AlignTimestamps,
+		bufferSize:   cfg.BufferSize,
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff(),
+		dropped:      dropped,
+		now:          time.Now,
+		sleep:        time.Sleep,
+	}
+}
+
+// run flushes the metrics every interval. It never returns.
+func (r *influxReporter) run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.flush()
+	}
+}
+
+// flush adds the current value of the metrics to the buffer and writes it, retrying up to
+// maxRetries times. The points are kept in the buffer if every attempt fails, unless Influx
+// rejected them: writing them again would fail the same way.
+func (r *influxReporter) flush() {
+	r.buffer = append(r.buffer, r.points()...)
+	if overflow := len(r.buffer) - r.bufferSize; overflow > 0 {
+		r.drop(overflow, "the buffer is full")
+		r.buffer = append(r.buffer[:0], r.buffer[overflow:]...)
+	}
+	if len(r.buffer) == 0 {
+		return
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := r.write(r.buffer)
+		if err == nil {
+			r.buffer = r.buffer[:0]
+			return
+		}
+		if !retry {
+			r.drop(len(r.buffer), err.Error())
+			r.buffer = r.buffer[:0]
+			return
+		}
+		if attempt >= r.maxRetries {
+			logrus.Warnf("Failed to write %d points to Influx, they will be written with the next ones: %v", len(r.buffer), err)
+			return
+		}
+		r.sleep(r.retryBackoff)
+	}
+}
+
+func (r *influxReporter) drop(points int, reason string) {
+	r.dropped.Inc(int64(points))
+	logrus.Warnf("Dropped %d points of metrics for Influx: %s", points, reason)
+}
+
+// write sends the lines to Influx. It returns whether a failed write is worth retrying.
+func (r *influxReporter) write(lines []string) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, r.writeURL, strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("Influx responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	// Influx answers 4xx when the points are malformed or conflict with the type of the stored fields
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return retry, err
+}
+
+// points returns a line per field of every metric in the registry
+func (r *influxReporter) points() []string {
+	now := r.now()
+	if r.align {
+		now = now.Truncate(r.interval)
+	}
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+
+	var lines []string
+	addFloats := func(field string, values map[string]float64) {
+		for bucket, value := range values {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			lines = append(lines, r.line(field, strconv.FormatFloat(value, 'f', -1, 64), bucket, timestamp))
+		}
+	}
+
+	r.registry.Each(func(name string, i interface{}) {
+		switch metric := i.(type) {
+		case metrics.Counter:
+			lines = append(lines, r.line(name+".count", strconv.FormatInt(metric.Snapshot().Count(), 10)+"i", "", timestamp))
+		case metrics.Gauge:
+			lines = append(lines, r.line(name+".gauge", strconv.FormatInt(metric.Snapshot().Value(), 10)+"i", "", timestamp))
+		case metrics.GaugeFloat64:
+			addFloats(name+".gauge", map[string]float64{"": metric.Snapshot().Value()})
+		case metrics.Histogram:
+			ms := metric.Snapshot()
+			ps := ms.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999})
+			addFloats(name+".histogram", map[string]float64{
+				"count":    float64(ms.Count()),
+				"max":      float64(ms.Max()),
+				"mean":     ms.Mean(),
+				"min":      float64(ms.Min()),
+				"stddev":   ms.StdDev(),
+				"variance": ms.Variance(),
+				"p50":      ps[0],
+				"p75":      ps[1],
+				"p95":      ps[2],
+				"p99":      ps[3],
+				"p999":     ps[4],
+				"p9999":    ps[5],
+			})
+		case metrics.Meter:
+			ms := metric.Snapshot()
+			addFloats(name+".meter", map[string]float64{
+				"count": float64(ms.Count()),
+				"m1":    ms.Rate1(),
+				"m5":    ms.Rate5(),
+				"m15":   ms.Rate15(),
+				"mean":  ms.RateMean(),
+			})
+		case metrics.Timer:
+			ms := metric.Snapshot()
+			ps := ms.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999})
+			addFloats(name+".timer", map[string]float64{
+				"count":    float64(ms.Count()),
+				"max":      float64(ms.Max()),
+				"mean":     ms.Mean(),
+				"min":      float64(ms.Min()),
+				"stddev":   ms.StdDev(),
+				"variance": ms.Variance(),
+				"p50":      ps[0],
+				"p75":      ps[1],
+				"p95":      ps[2],
+				"p99":      ps[3],
+				"p999":     ps[4],
+				"p9999":    ps[5],
+				"m1":       ms.Rate1(),
+				"m5":       ms.Rate5(),
+				"m15":      ms.Rate15(),
+				"meanrate": ms.RateMean(),
+			})
+		}
+	})
+	return lines
+}
+
+// line formats a point as "<measurement>,<tags> <field>=<value> <timestamp>". The bucket, if any,
+// is added to the static tags.
+func (r *influxReporter) line(field, value, bucket, timestamp string) string {
+	tags := r.tags
+	if bucket != "" {
+		tags = make(map[string]string, len(r.tags)+1)
+		for key, tagValue := range r.tags {
+			tags[key] = tagValue
+		}
+		tags["bucket"] = bucket
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var line strings.Builder
+	line.WriteString(measurementEscaper.Replace(r.measurement))
+	for _, key := range keys {
+		if tags[key] == "" {
+			// Influx doesn't accept empty tag values
+			continue
+		}
+		line.WriteString(",")
+		line.WriteString(keyEscaper.Replace(key))
+		line.WriteString("=")
+		line.WriteString(keyEscaper.Replace(tags[key]))
+	}
+	line.WriteString(" ")
+	line.WriteString(keyEscaper.Replace(field))
+	line.WriteString("=")
+	line.WriteString(value)
+	line.WriteString(" ")
+	line.WriteString(timestamp)
+	return line.String()
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
@@ -0,0 +1,193 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// influxStandIn records the writes sent to its /write route and answers them with the given statuses,
+// then with 204 once they're used up
+type influxStandIn struct {
+	mu       sync.Mutex
+	statuses []int
+	writes   []string
+	queries  []string
+	auths    []string
+}
+
+func (s *influxStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	s.writes = append(s.writes, string(body))
+	s.queries = append(s.queries, r.URL.Path+"?"+r.URL.RawQuery)
+	username, password, _ := r.BasicAuth()
+	s.auths = append(s.auths, username+":"+password)
+
+	status := http.StatusNoContent
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newTestReporter(t *testing.T, standIn *influxStandIn, cfg config.InfluxMetrics) (*influxReporter, metrics.Registry) {
+	t.Helper()
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	cfg.Host = server.URL
+	cfg.Database = "prebid"
+	cfg.Measurement = "cache"
+	cfg.FlushIntervalMillis = 10000
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = 100
+	}
+
+	registry := metrics.NewRegistry()
+	reporter := newInfluxReporter(registry, metrics.NewCounter(), cfg)
+	reporter.now = func() time.Time { return time.Unix(1651659072, 0) }
+	reporter.sleep = func(time.Duration) {}
+	return reporter, registry
+}
+
+func TestInfluxReporterWrite(t *testing.T) {
+	standIn := &influxStandIn{}
+	reporter, registry := newTestReporter(t, standIn, config.InfluxMetrics{
+		Username: "user",
+		Password: "secret",
+		Tags:     map[string]string{"region": "us east", "host": "cache-1"},
+	})
+	metrics.GetOrRegisterCounter("connections.active_incoming", registry).Inc(3)
+	metrics.GetOrRegisterMeter("puts.request_count", registry).Mark(2)
+
+	reporter.flush()
+
+	if !assert.Len(t, standIn.writes, 1) {
+		return
+	}
+	assert.Equal(t, "/write?db=prebid", standIn.queries[0])
+	assert.Equal(t, "user:secret", standIn.auths[0])
+	lines := strings.Split(standIn.writes[0], "\n")
+	assert.Len(t, lines, 6, "A line for the counter and one for each bucket of the meter")
+	assert.Contains(t, lines, `cache,host=cache-1,region=us\ east connections.active_incoming.count=3i 1651659072000000000`)
+	assert.Contains(t, lines, `cache,bucket=count,host=cache-1,region=us\ east puts.request_count.meter=2 1651659072000000000`)
+	assert.Empty(t, reporter.buffer)
+}
+
+func TestInfluxReporterAlignTimestamps(t *testing.T) {
+	standIn := &influxStandIn{}
+	reporter, registry := newTestReporter(t, standIn, config.InfluxMetrics{AlignTimestamps: true})
+	reporter.now = func() time.Time { return time.Unix(1651659077, 0) }
+	metrics.GetOrRegisterCounter("connections.active_incoming", registry).Inc(1)
+
+	reporter.flush()
+
+	assert.Equal(t, []string{"cache connections.active_incoming.count=1i 1651659070000000000"}, standIn.writes)
+}
+
+func TestInfluxReporterRetries(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		inStatuses        []int
+		inMaxRetries      int
+		expectedWrites    int
+		expectedBuffered  int
+		expectedDropped   int64
+		expectedNextWrite int
+	}{
+		{
+			desc:              "Influx recovers before the retries run out",
+			inStatuses:        []int{http.StatusServiceUnavailable, http.StatusInternalServerError},
+			inMaxRetries:      2,
+			expectedWrites:    3,
+			expectedNextWrite: 1,
+		},
+		{
+			desc:              "Influx is down for longer than the retries, the points are written with the next ones",
+			inStatuses:        []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			inMaxRetries:      1,
+			expectedWrites:    2,
+			expectedBuffered:  1,
+			expectedNextWrite: 2,
+		},
+		{
+			desc:              "Influx rejects the points, they aren't retried",
+			inStatuses:        []int{http.StatusBadRequest},
+			inMaxRetries:      2,
+			expectedWrites:    1,
+			expectedDropped:   1,
+			expectedNextWrite: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		standIn := &influxStandIn{statuses: tc.inStatuses}
+		reporter, registry := newTestReporter(t, standIn, config.InfluxMetrics{MaxRetries: tc.inMaxRetries})
+		metrics.GetOrRegisterCounter("connections.active_incoming", registry).Inc(1)
+
+		reporter.flush()
+
+		assert.Len(t, standIn.writes, tc.expectedWrites, tc.desc)
+		assert.Len(t, reporter.buffer, tc.expectedBuffered, tc.desc)
+		assert.Equal(t, tc.expectedDropped, reporter.dropped.Count(), tc.desc)
+
+		reporter.flush()
+
+		lastWrite := standIn.writes[len(standIn.writes)-1]
+		assert.Len(t, strings.Split(lastWrite, "\n"), tc.expectedNextWrite, tc.desc)
+		assert.Empty(t, reporter.buffer, tc.desc)
+	}
+}
+
+func TestInfluxReporterBufferSize(t *testing.T) {
+	standIn := &influxStandIn{statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable}}
+	reporter, registry := newTestReporter(t, standIn, config.InfluxMetrics{BufferSize: 2})
+	counter := metrics.GetOrRegisterCounter("connections.active_incoming", registry)
+
+	for i := 1; i <= 3; i++ {
+		counter.Inc(1)
+		reporter.flush()
+	}
+
+	assert.Equal(t, int64(1), reporter.dropped.Count(), "The oldest point is dropped once the buffer is full")
+	assert.Equal(t, []string{
+		"cache connections.active_incoming.count=2i 1651659072000000000",
+		"cache connections.active_incoming.count=3i 1651659072000000000",
+	}, reporter.buffer)
+
+	counter.Inc(1)
+	reporter.flush()
+
+	assert.Equal(t, int64(2), reporter.dropped.Count())
+	assert.Equal(t, strings.Join([]string{
+		"cache connections.active_incoming.count=3i 1651659072000000000",
+		"cache connections.active_incoming.count=4i 1651659072000000000",
+	}, "\n"), standIn.writes[len(standIn.writes)-1], "The buffered points are written once Influx is back")
+	assert.Empty(t, reporter.buffer)
+}
+
+func TestInfluxReporterUnreachable(t *testing.T) {
+	reporter := newInfluxReporter(metrics.NewRegistry(), metrics.NewCounter(), config.InfluxMetrics{
+		Host:                "http://127.0.0.1:1",
+		Database:            "prebid",
+		Measurement:         "cache",
+		FlushIntervalMillis: 1000,
+		BufferSize:          10,
+	})
+	metrics.GetOrRegisterCounter("connections.active_incoming", reporter.registry).Inc(1)
+
+	reporter.flush()
+
+	assert.Len(t, reporter.buffer, 1, "The points are kept when Influx can't be reached")
+	assert.Equal(t, int64(0), reporter.dropped.Count())
+}
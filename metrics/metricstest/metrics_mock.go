@@ -8,7 +8,7 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-func AssertMetrics(t *testing.T, expectedMetrics []string, actualMetrics MockMetrics) {
+func AssertMetrics(t *testing.T, expectedMetrics []string, actualMetrics *MockMetrics) {
 	t.Helper()
 
 	// All the names of our metric interface methods
@@ -19,10 +19,18 @@ func AssertMetrics(t *testing.T, expectedMetrics []string, actualMetrics MockMet
 		"RecordConnectionOpen":         {},
 		"RecordGetBackendDuration":     {},
 		"RecordGetBackendError":        {},
+		"RecordGetBackendHit":          {},
+		"RecordGetBackendJsonSize":     {},
+		"RecordGetBackendValueAge":     {},
+		"RecordGetBackendXmlSize":      {},
 		"RecordGetBackendTotal":        {},
 		"RecordGetBadRequest":          {},
 		"RecordGetDuration":            {},
 		"RecordGetError":               {},
+		"RecordGetPeerDuration":        {},
+		"RecordGetPeerError":           {},
+		"RecordGetPeerHit":             {},
+		"RecordGetPeerTotal":           {},
 		"RecordGetTotal":               {},
 		"RecordKeyNotFoundError":       {},
 		"RecordMissingKeyError":        {},
@@ -34,9 +42,14 @@ func AssertMetrics(t *testing.T, expectedMetrics []string, actualMetrics MockMet
 		"RecordPutBackendTTLSeconds":   {},
 		"RecordPutBackendXml":          {},
 		"RecordPutBadRequest":          {},
+		"RecordPutCompressedSize":      {},
+		"RecordPutDecompressedSize":    {},
+		"RecordPutDedupHit":            {},
 		"RecordPutDuration":            {},
 		"RecordPutError":               {},
+		"RecordPutKeyCollision":        {},
 		"RecordPutKeyProvided":         {},
+		"RecordPutRetriesExhausted":    {},
 		"RecordPutTotal":               {},
 	}
 
@@ -68,10 +81,18 @@ type MetricsRecorded struct {
 	// Get metrics
 	RecordGetBackendDuration float64 `json:"RecordGetBackendDuration"`
 	RecordGetBackendError    int64   `json:"RecordGetBackendError"`
+	RecordGetBackendHit      int64   `json:"RecordGetBackendHit"`
+	RecordGetBackendJsonSize float64 `json:"RecordGetBackendJsonSize"`
+	RecordGetBackendValueAge float64 `json:"RecordGetBackendValueAge"`
+	RecordGetBackendXmlSize  float64 `json:"RecordGetBackendXmlSize"`
 	RecordGetBackendTotal    int64   `json:"RecordGetBackendTotal"`
 	RecordGetBadRequest      int64   `json:"RecordGetBadRequest"`
 	RecordGetDuration        float64 `json:"RecordGetDuration"`
 	RecordGetError           int64   `json:"RecordGetError"`
+	RecordGetPeerDuration    float64 `json:"RecordGetPeerDuration"`
+	RecordGetPeerError       int64   `json:"RecordGetPeerError"`
+	RecordGetPeerHit         int64   `json:"RecordGetPeerHit"`
+	RecordGetPeerTotal       int64   `json:"RecordGetPeerTotal"`
 	RecordGetTotal           int64   `json:"RecordGetTotal"`
 
 	// Put metrics
@@ -85,14 +106,19 @@ type MetricsRecorded struct {
 	RecordPutBackendTTLSeconds float64 `json:"RecordPutBackendTTLSeconds"`
 	RecordPutBackendXml        int64   `json:"RecordPutBackendXml"`
 	RecordPutBadRequest        int64   `json:"RecordPutBadRequest"`
+	RecordPutCompressedSize    float64 `json:"RecordPutCompressedSize"`
+	RecordPutDecompressedSize  float64 `json:"RecordPutDecompressedSize"`
+	RecordPutDedupHit          int64   `json:"RecordPutDedupHit"`
 	RecordPutDuration          float64 `json:"RecordPutDuration"`
 	RecordPutError             int64   `json:"RecordPutError"`
+	RecordPutKeyCollision      int64   `json:"RecordPutKeyCollision"`
 	RecordPutKeyProvided       int64   `json:"RecordPutKeyProvided"`
+	RecordPutRetriesExhausted  int64   `json:"RecordPutRetriesExhausted"`
 	RecordPutTotal             int64   `json:"RecordPutTotal"`
 }
 
-func CreateMockMetrics() MockMetrics {
-	mockMetrics := MockMetrics{}
+func CreateMockMetrics() *MockMetrics {
+	mockMetrics := &MockMetrics{}
 
 	mockMetrics.On("RecordAcceptConnectionErrors")
 	mockMetrics.On("RecordCloseConnectionErrors")
@@ -100,10 +126,18 @@ func CreateMockMetrics() MockMetrics {
 	mockMetrics.On("RecordConnectionOpen")
 	mockMetrics.On("RecordGetBackendDuration", mock.Anything)
 	mockMetrics.On("RecordGetBackendError")
+	mockMetrics.On("RecordGetBackendHit")
+	mockMetrics.On("RecordGetBackendJsonSize", mock.Anything)
+	mockMetrics.On("RecordGetBackendValueAge", mock.Anything)
+	mockMetrics.On("RecordGetBackendXmlSize", mock.Anything)
 	mockMetrics.On("RecordGetBackendTotal")
 	mockMetrics.On("RecordGetBadRequest")
 	mockMetrics.On("RecordGetDuration", mock.Anything)
 	mockMetrics.On("RecordGetError")
+	mockMetrics.On("RecordGetPeerDuration", mock.Anything)
+	mockMetrics.On("RecordGetPeerError")
+	mockMetrics.On("RecordGetPeerHit")
+	mockMetrics.On("RecordGetPeerTotal")
 	mockMetrics.On("RecordGetTotal")
 	mockMetrics.On("RecordKeyNotFoundError")
 	mockMetrics.On("RecordMissingKeyError")
@@ -115,9 +149,14 @@ func CreateMockMetrics() MockMetrics {
 	mockMetrics.On("RecordPutBackendTTLSeconds", mock.Anything)
 	mockMetrics.On("RecordPutBackendXml")
 	mockMetrics.On("RecordPutBadRequest")
+	mockMetrics.On("RecordPutCompressedSize", mock.Anything)
+	mockMetrics.On("RecordPutDecompressedSize", mock.Anything)
+	mockMetrics.On("RecordPutDedupHit")
 	mockMetrics.On("RecordPutDuration", mock.Anything)
 	mockMetrics.On("RecordPutError")
+	mockMetrics.On("RecordPutKeyCollision")
 	mockMetrics.On("RecordPutKeyProvided")
+	mockMetrics.On("RecordPutRetriesExhausted")
 	mockMetrics.On("RecordPutTotal")
 
 	return mockMetrics
@@ -154,6 +193,26 @@ func (m *MockMetrics) RecordPutKeyProvided() {
 	m.Called()
 	return
 }
+func (m *MockMetrics) RecordPutDedupHit() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordPutKeyCollision() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordPutRetriesExhausted() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordPutCompressedSize(sizeInBytes float64) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordPutDecompressedSize(sizeInBytes float64) {
+	m.Called()
+	return
+}
 func (m *MockMetrics) RecordGetError() {
 	m.Called()
 	return
@@ -210,6 +269,38 @@ func (m *MockMetrics) RecordGetBackendError() {
 	m.Called()
 	return
 }
+func (m *MockMetrics) RecordGetBackendHit() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordGetBackendJsonSize(sizeInBytes float64) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordGetBackendXmlSize(sizeInBytes float64) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordGetBackendValueAge(age time.Duration) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordGetPeerTotal() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordGetPeerDuration(duration time.Duration) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordGetPeerError() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordGetPeerHit() {
+	m.Called()
+	return
+}
 func (m *MockMetrics) RecordKeyNotFoundError() {
 	m.Called()
 	return
@@ -5,12 +5,14 @@ import (
 )
 
 func preloadLabelValues(m *PrometheusMetrics) {
-	preloadLabelValuesForCounter(m.Puts.RequestStatus, map[string][]string{StatusKey: {ErrorVal, BadRequestVal, TotalsVal, CustomKey}})
+	preloadLabelValuesForCounter(m.Puts.RequestStatus, map[string][]string{StatusKey: {ErrorVal, BadRequestVal, TotalsVal, CustomKey, DedupHitVal, CollisionVal, ExhaustedVal}})
 	preloadLabelValuesForCounter(m.Gets.RequestStatus, map[string][]string{StatusKey: {ErrorVal, BadRequestVal, TotalsVal}})
 	preloadLabelValuesForCounter(m.PutsBackend.PutBackendRequests, map[string][]string{FormatKey: {XmlVal, JsonVal, InvFormatVal, ErrorVal}})
-	preloadLabelValuesForCounter(m.GetsBackend.RequestStatus, map[string][]string{StatusKey: {ErrorVal, BadRequestVal, TotalsVal}})
+	preloadLabelValuesForCounter(m.GetsBackend.RequestStatus, map[string][]string{StatusKey: {ErrorVal, BadRequestVal, TotalsVal, HitVal}})
 	preloadLabelValuesForCounter(m.GetsBackend.ErrorsByType, map[string][]string{TypeKey: {KeyNotFoundVal, MissingKeyVal}})
+	preloadLabelValuesForCounter(m.GetsPeer.RequestStatus, map[string][]string{StatusKey: {ErrorVal, TotalsVal, HitVal}})
 	preloadLabelValuesForCounter(m.Connections.ConnectionsErrors, map[string][]string{ConnErrorKey: {CloseVal, AcceptVal}})
+	preloadLabelValuesForHistogram(m.GetsValues.ValueSize, map[string][]string{FormatKey: {JsonVal, XmlVal}})
 }
 
 func preloadLabelValuesForCounter(counter *prometheus.CounterVec, labelsWithValues map[string][]string) {
@@ -19,6 +21,12 @@ func preloadLabelValuesForCounter(counter *prometheus.CounterVec, labelsWithValu
 	})
 }
 
+func preloadLabelValuesForHistogram(histogram *prometheus.HistogramVec, labelsWithValues map[string][]string) {
+	registerLabelPermutations(labelsWithValues, func(labels prometheus.Labels) {
+		histogram.With(labels)
+	})
+}
+
 func registerLabelPermutations(labelsWithValues map[string][]string, register func(prometheus.Labels)) {
 	if len(labelsWithValues) == 0 {
 		return
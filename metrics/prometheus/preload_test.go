@@ -3,6 +3,7 @@ package metrics
 import (
 	"testing"
 
+	"github.com/prebid/prebid-cache/config"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -62,3 +63,27 @@ func TestRegisterLabelPermutations(t *testing.T) {
 		assert.ElementsMatch(t, test.expectedLabels, resultLabels)
 	}
 }
+
+func TestPreloadLabelValues(t *testing.T) {
+	m := CreatePrometheusMetrics(config.PrometheusMetrics{})
+
+	families, err := m.Registry.Gather()
+	if !assert.NoError(t, err) {
+		return
+	}
+	putStatuses := []string{}
+	for _, family := range families {
+		if family.GetName() != PutRequestMet {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == StatusKey {
+					putStatuses = append(putStatuses, label.GetValue())
+				}
+			}
+		}
+	}
+
+	assert.ElementsMatch(t, []string{ErrorVal, BadRequestVal, TotalsVal, CustomKey, DedupHitVal, CollisionVal, ExhaustedVal}, putStatuses, "Every status of the puts is exported before it's first recorded")
+}
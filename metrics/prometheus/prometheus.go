@@ -2,18 +2,27 @@ package metrics
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/utils"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
 )
 
 const (
 	// Label keys
-	StatusKey    string = "status"
-	FormatKey    string = "format"
-	ConnErrorKey string = "connection_error"
-	TypeKey      string = "type"
+	StatusKey      string = "status"
+	FormatKey      string = "format"
+	ConnErrorKey   string = "connection_error"
+	TypeKey        string = "type"
+	VersionKey     string = "version"
+	RevisionKey    string = "revision"
+	BackendKey     string = "backend"
+	CompressionKey string = "compression"
 
 	// Label values
 	TotalsVal      string = "total"
@@ -24,13 +33,20 @@ const (
 	JsonVal        string = "json"
 	XmlVal         string = "xml"
 	CustomKey      string = "custom_key"
+	DedupHitVal    string = "dedup_hit"
+	CollisionVal   string = "key_collision"
+	ExhaustedVal   string = "collision_retries_exhausted"
 	InvFormatVal   string = "invalid_format"
 	CloseVal       string = "close"
 	AcceptVal      string = "accept"
+	NotSetVal      string = "not-set"
+	HitVal         string = "hit"
 
 	// Metric names
 	PutRequestMet  string = "puts_request"
 	PutReqDurMet   string = "puts_request_duration"
+	PutCompSizeMet string = "puts_request_compressed_size_bytes"
+	PutBodySizeMet string = "puts_request_decompressed_size_bytes"
 	GetRequestMet  string = "gets_request"
 	GetReqDurMet   string = "gets_request_duration"
 	PutBackendMet  string = "puts_backend"
@@ -40,8 +56,13 @@ const (
 	GetBackendMet  string = "gets_backend"
 	GetBackendErr  string = "gets_backend_error"
 	GetBackDurMet  string = "gets_backend_duration"
+	GetBackSizeMet string = "gets_backend_value_size_bytes"
+	GetValueAgeMet string = "gets_backend_value_age_seconds"
+	GetPeerMet     string = "gets_peer"
+	GetPeerDurMet  string = "gets_peer_duration"
 	ConnOpenedMet  string = "connection_opened"
 	ConnClosedMet  string = "connection_closed"
+	BuildInfoMet   string = "build_info"
 
 	MetricsPrometheus = "Prometheus"
 )
@@ -49,10 +70,14 @@ const (
 type PrometheusMetrics struct {
 	Registry    *prometheus.Registry
 	Puts        *PrometheusRequestStatusMetric
+	PutsBody    *PrometheusPutBodyMetrics
 	Gets        *PrometheusRequestStatusMetric
 	PutsBackend *PrometheusRequestStatusMetricByFormat
 	GetsBackend *PrometheusRequestStatusMetric
+	GetsValues  *PrometheusGetValueMetrics
+	GetsPeer    *PrometheusRequestStatusMetric
 	Connections *PrometheusConnectionMetrics
+	BuildInfo   *prometheus.GaugeVec
 	MetricsName string
 }
 
@@ -69,6 +94,18 @@ type PrometheusRequestStatusMetricByFormat struct {
 	RequestTTLDuration prometheus.Histogram
 }
 
+// PrometheusGetValueMetrics describe the values the backend found
+type PrometheusGetValueMetrics struct {
+	ValueSize *prometheus.HistogramVec
+	ValueAge  prometheus.Histogram
+}
+
+// PrometheusPutBodyMetrics describe the bodies of the compressed put requests
+type PrometheusPutBodyMetrics struct {
+	CompressedSize   prometheus.Histogram
+	DecompressedSize prometheus.Histogram
+}
+
 type PrometheusConnectionMetrics struct {
 	ConnectionsErrors *prometheus.CounterVec
 	ConnectionsClosed prometheus.Counter
@@ -76,10 +113,10 @@ type PrometheusConnectionMetrics struct {
 }
 
 func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
-	timeBuckets := []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1}
-	// TTL seconds buckets for 1 second, half a minute as well as one, ten, fifteen, thirty minutes and 1, 2, and 3 and 10 hours
-	ttlBuckets := []float64{0.001, 1, 30, 60, 600, 900, 1800, 3600, 7200, 10800, 36000}
-	requestSizeBuckets := []float64{0, 4096, 8192, 16384, 32768, 65536, 131072, 262144, 524288, 1048576}
+	timeBuckets := bucketsOrDefault(cfg.Buckets.Duration, utils.PROMETHEUS_DURATION_BUCKETS)
+	ttlBuckets := bucketsOrDefault(cfg.Buckets.TTL, utils.PROMETHEUS_TTL_BUCKETS)
+	requestSizeBuckets := bucketsOrDefault(cfg.Buckets.Size, utils.PROMETHEUS_SIZE_BUCKETS)
+	ageBuckets := bucketsOrDefault(cfg.Buckets.Age, utils.PROMETHEUS_AGE_BUCKETS)
 	registry := prometheus.NewRegistry()
 	promMetrics := &PrometheusMetrics{
 		Registry: registry,
@@ -95,6 +132,18 @@ func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
 				[]string{StatusKey},
 			),
 		},
+		PutsBody: &PrometheusPutBodyMetrics{
+			CompressedSize: newHistogram(cfg, registry,
+				PutCompSizeMet,
+				"Size in bytes of the compressed bodies of put requests, as received.",
+				requestSizeBuckets,
+			),
+			DecompressedSize: newHistogram(cfg, registry,
+				PutBodySizeMet,
+				"Size in bytes of the compressed bodies of put requests once decompressed.",
+				requestSizeBuckets,
+			),
+		},
 		Gets: &PrometheusRequestStatusMetric{
 			Duration: newHistogram(cfg, registry,
 				GetReqDurMet,
@@ -146,6 +195,31 @@ func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
 				[]string{TypeKey},
 			),
 		},
+		GetsValues: &PrometheusGetValueMetrics{
+			ValueSize: newHistogramVecWithLabels(cfg, registry,
+				GetBackSizeMet,
+				"Size in bytes of the values found by backend get requests, labeled by format.",
+				[]string{FormatKey},
+				requestSizeBuckets,
+			),
+			ValueAge: newHistogram(cfg, registry,
+				GetValueAgeMet,
+				"Time in seconds elapsed between the put of a value and its retrieval. Only values stored with metadata are accounted.",
+				ageBuckets,
+			),
+		},
+		GetsPeer: &PrometheusRequestStatusMetric{
+			Duration: newHistogram(cfg, registry,
+				GetPeerDurMet,
+				"Duration in seconds peer Prebid Cache clusters take to answer the get requests forwarded to them.",
+				timeBuckets,
+			),
+			RequestStatus: newCounterVecWithLabels(cfg, registry,
+				GetPeerMet,
+				"Count of get requests forwarded to peer Prebid Cache clusters labeled by status.",
+				[]string{StatusKey},
+			),
+		},
 		Connections: &PrometheusConnectionMetrics{
 			ConnectionsClosed: newSingleCounter(cfg, registry, ConnClosedMet, "Count the number of closed connections"),
 			ConnectionsOpened: newSingleCounter(cfg, registry, ConnOpenedMet, "Count the number of open connections"),
@@ -155,6 +229,11 @@ func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
 				[]string{ConnErrorKey},
 			),
 		},
+		BuildInfo: newGaugeVecWithLabels(cfg, registry,
+			BuildInfoMet,
+			"A metric with a constant '1' value labeled by the version and revision Prebid Cache was built from, and the backend and compression it runs with.",
+			[]string{VersionKey, RevisionKey, BackendKey, CompressionKey},
+		),
 		MetricsName: MetricsPrometheus,
 	}
 
@@ -169,14 +248,27 @@ func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
 		collectorNamespace += fmt.Sprintf("%s", cfg.Subsystem)
 	}
 
-	promMetrics.Registry.MustRegister(
-		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{Namespace: collectorNamespace}),
-	)
+	if cfg.ProcessCollector {
+		promMetrics.Registry.MustRegister(
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: collectorNamespace}),
+		)
+	}
+	if cfg.GoCollector {
+		promMetrics.Registry.MustRegister(collectors.NewGoCollector())
+	}
 
 	preloadLabelValues(promMetrics)
 	return promMetrics
 }
 
+// bucketsOrDefault returns the configured buckets, or the default ones if none were configured
+func bucketsOrDefault(configured []float64, defaults []float64) []float64 {
+	if len(configured) == 0 {
+		return defaults
+	}
+	return configured
+}
+
 func newCounterVecWithLabels(cfg config.PrometheusMetrics, registry *prometheus.Registry, name string, help string, labels []string) *prometheus.CounterVec {
 	opts := prometheus.CounterOpts{
 		Namespace: cfg.Namespace,
@@ -201,6 +293,18 @@ func newSingleCounter(cfg config.PrometheusMetrics, registry *prometheus.Registr
 	return counter
 }
 
+func newGaugeVecWithLabels(cfg config.PrometheusMetrics, registry *prometheus.Registry, name string, help string, labels []string) *prometheus.GaugeVec {
+	opts := prometheus.GaugeOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      name,
+		Help:      help,
+	}
+	gaugeVec := prometheus.NewGaugeVec(opts, labels)
+	registry.MustRegister(gaugeVec)
+	return gaugeVec
+}
+
 func newHistogram(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, buckets []float64) prometheus.Histogram {
 	opts := prometheus.HistogramOpts{
 		Namespace: cfg.Namespace,
@@ -214,9 +318,53 @@ func newHistogram(cfg config.PrometheusMetrics, registry *prometheus.Registry, n
 	return histogram
 }
 
+func newHistogramVecWithLabels(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
+	opts := prometheus.HistogramOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}
+	histogramVec := prometheus.NewHistogramVec(opts, labels)
+	registry.MustRegister(histogramVec)
+	return histogramVec
+}
+
 func (m PrometheusMetrics) Export(cfg config.Metrics) {
 }
 
+// NewHandler serves the metrics gathered by registry in the Prometheus exposition format
+func NewHandler(registry *prometheus.Registry, cfg config.PrometheusMetrics) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorLog:            loggerForPrometheus{},
+		MaxRequestsInFlight: 5,
+		Timeout:             cfg.Timeout(),
+	})
+}
+
+type loggerForPrometheus struct{}
+
+func (loggerForPrometheus) Println(v ...interface{}) {
+	log.Warning(v...)
+}
+
+// RecordBuildInfo sets the build info gauge. Unlike the other metrics, it's recorded once, at startup.
+func (m *PrometheusMetrics) RecordBuildInfo(version, revision, backend, compression string) {
+	if version == "" {
+		version = NotSetVal
+	}
+	if revision == "" {
+		revision = NotSetVal
+	}
+	m.BuildInfo.With(prometheus.Labels{
+		VersionKey:     version,
+		RevisionKey:    revision,
+		BackendKey:     backend,
+		CompressionKey: compression,
+	}).Set(1)
+}
+
 func (m *PrometheusMetrics) GetMetricsEngineName() string {
 	return m.MetricsName
 }
@@ -245,6 +393,26 @@ func (m *PrometheusMetrics) RecordPutKeyProvided() {
 	m.Puts.RequestStatus.With(prometheus.Labels{StatusKey: CustomKey}).Inc()
 }
 
+func (m *PrometheusMetrics) RecordPutDedupHit() {
+	m.Puts.RequestStatus.With(prometheus.Labels{StatusKey: DedupHitVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordPutKeyCollision() {
+	m.Puts.RequestStatus.With(prometheus.Labels{StatusKey: CollisionVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordPutRetriesExhausted() {
+	m.Puts.RequestStatus.With(prometheus.Labels{StatusKey: ExhaustedVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordPutCompressedSize(sizeInBytes float64) {
+	m.PutsBody.CompressedSize.Observe(sizeInBytes)
+}
+
+func (m *PrometheusMetrics) RecordPutDecompressedSize(sizeInBytes float64) {
+	m.PutsBody.DecompressedSize.Observe(sizeInBytes)
+}
+
 func (m *PrometheusMetrics) RecordGetError() {
 	m.Gets.RequestStatus.With(prometheus.Labels{StatusKey: ErrorVal}).Inc()
 }
@@ -301,6 +469,38 @@ func (m *PrometheusMetrics) RecordGetBackendError() {
 	m.GetsBackend.RequestStatus.With(prometheus.Labels{StatusKey: ErrorVal}).Inc()
 }
 
+func (m *PrometheusMetrics) RecordGetBackendHit() {
+	m.GetsBackend.RequestStatus.With(prometheus.Labels{StatusKey: HitVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordGetBackendJsonSize(sizeInBytes float64) {
+	m.GetsValues.ValueSize.With(prometheus.Labels{FormatKey: JsonVal}).Observe(sizeInBytes)
+}
+
+func (m *PrometheusMetrics) RecordGetBackendXmlSize(sizeInBytes float64) {
+	m.GetsValues.ValueSize.With(prometheus.Labels{FormatKey: XmlVal}).Observe(sizeInBytes)
+}
+
+func (m *PrometheusMetrics) RecordGetBackendValueAge(age time.Duration) {
+	m.GetsValues.ValueAge.Observe(age.Seconds())
+}
+
+func (m *PrometheusMetrics) RecordGetPeerTotal() {
+	m.GetsPeer.RequestStatus.With(prometheus.Labels{StatusKey: TotalsVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordGetPeerDuration(duration time.Duration) {
+	m.GetsPeer.Duration.Observe(duration.Seconds())
+}
+
+func (m *PrometheusMetrics) RecordGetPeerError() {
+	m.GetsPeer.RequestStatus.With(prometheus.Labels{StatusKey: ErrorVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordGetPeerHit() {
+	m.GetsPeer.RequestStatus.With(prometheus.Labels{StatusKey: HitVal}).Inc()
+}
+
 func (m *PrometheusMetrics) RecordGetBackendBadRequest() {
 	m.GetsBackend.RequestStatus.With(prometheus.Labels{StatusKey: BadRequestVal}).Inc()
 }
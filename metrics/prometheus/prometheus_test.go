@@ -77,6 +77,9 @@ func TestPrometheusRequestStatusMetric(t *testing.T) {
 		expRequestErrors float64
 		expBadRequests   float64
 		expCustomKeyReqs float64
+		expDedupHits     float64
+		expCollisions    float64
+		expExhausted     float64
 		testCase         func(pm *PrometheusMetrics)
 	}
 
@@ -114,6 +117,24 @@ func TestPrometheusRequestStatusMetric(t *testing.T) {
 				expDuration:      10,
 				expRequestTotals: 1, expRequestErrors: 1, expBadRequests: 1, expCustomKeyReqs: 1,
 			},
+			{
+				description:      "Count put element already stored under its content key",
+				testCase:         func(pm *PrometheusMetrics) { pm.RecordPutDedupHit() },
+				expDuration:      10,
+				expRequestTotals: 1, expRequestErrors: 1, expBadRequests: 1, expCustomKeyReqs: 1, expDedupHits: 1,
+			},
+			{
+				description:      "Count put element whose generated key was already taken",
+				testCase:         func(pm *PrometheusMetrics) { pm.RecordPutKeyCollision() },
+				expDuration:      10,
+				expRequestTotals: 1, expRequestErrors: 1, expBadRequests: 1, expCustomKeyReqs: 1, expDedupHits: 1, expCollisions: 1,
+			},
+			{
+				description:      "Count put element that ran out of keys to retry with",
+				testCase:         func(pm *PrometheusMetrics) { pm.RecordPutRetriesExhausted() },
+				expDuration:      10,
+				expRequestTotals: 1, expRequestErrors: 1, expBadRequests: 1, expCustomKeyReqs: 1, expDedupHits: 1, expCollisions: 1, expExhausted: 1,
+			},
 		},
 		m.Gets: {
 			{
@@ -181,6 +202,10 @@ func TestPrometheusRequestStatusMetric(t *testing.T) {
 			assertCounterVecValue(t, test.description, prometheusMetric.RequestStatus, test.expRequestTotals, prometheus.Labels{StatusKey: TotalsVal})
 			assertCounterVecValue(t, test.description, prometheusMetric.RequestStatus, test.expRequestErrors, prometheus.Labels{StatusKey: ErrorVal})
 			assertCounterVecValue(t, test.description, prometheusMetric.RequestStatus, test.expBadRequests, prometheus.Labels{StatusKey: BadRequestVal})
+			assertCounterVecValue(t, test.description, prometheusMetric.RequestStatus, test.expCustomKeyReqs, prometheus.Labels{StatusKey: CustomKey})
+			assertCounterVecValue(t, test.description, prometheusMetric.RequestStatus, test.expDedupHits, prometheus.Labels{StatusKey: DedupHitVal})
+			assertCounterVecValue(t, test.description, prometheusMetric.RequestStatus, test.expCollisions, prometheus.Labels{StatusKey: CollisionVal})
+			assertCounterVecValue(t, test.description, prometheusMetric.RequestStatus, test.expExhausted, prometheus.Labels{StatusKey: ExhaustedVal})
 		}
 	}
 }
@@ -217,6 +242,48 @@ func TestGetsBackendErrorsByType(t *testing.T) {
 	}
 }
 
+func TestGetPeerMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordGetPeerTotal()
+	m.RecordGetPeerTotal()
+	m.RecordGetPeerTotal()
+	m.RecordGetPeerDuration(TenSeconds)
+	m.RecordGetPeerError()
+	m.RecordGetPeerHit()
+
+	assertHistogram(t, "Duration", m.GetsPeer.Duration, 1, 10)
+	assertCounterVecValue(t, "Totals", m.GetsPeer.RequestStatus, 3, prometheus.Labels{StatusKey: TotalsVal})
+	assertCounterVecValue(t, "Errors", m.GetsPeer.RequestStatus, 1, prometheus.Labels{StatusKey: ErrorVal})
+	assertCounterVecValue(t, "Hits", m.GetsPeer.RequestStatus, 1, prometheus.Labels{StatusKey: HitVal})
+}
+
+func TestPutBodyMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordPutCompressedSize(300)
+	m.RecordPutDecompressedSize(1000)
+	m.RecordPutDecompressedSize(2000)
+
+	assertHistogram(t, "Compressed sizes", m.PutsBody.CompressedSize, 1, 300)
+	assertHistogram(t, "Decompressed sizes", m.PutsBody.DecompressedSize, 2, 3000)
+}
+
+func TestGetBackendValueMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordGetBackendHit()
+	m.RecordGetBackendJsonSize(100)
+	m.RecordGetBackendXmlSize(200)
+	m.RecordGetBackendXmlSize(300)
+	m.RecordGetBackendValueAge(90 * time.Second)
+
+	assertCounterVecValue(t, "Hits", m.GetsBackend.RequestStatus, 1, prometheus.Labels{StatusKey: HitVal})
+	assertHistogram(t, "JSON value sizes", m.GetsValues.ValueSize.With(prometheus.Labels{FormatKey: JsonVal}).(prometheus.Histogram), 1, 100)
+	assertHistogram(t, "XML value sizes", m.GetsValues.ValueSize.With(prometheus.Labels{FormatKey: XmlVal}).(prometheus.Histogram), 2, 500)
+	assertHistogram(t, "Value age", m.GetsValues.ValueAge, 1, 90)
+}
+
 func TestPutBackendMetrics(t *testing.T) {
 	m := createPrometheusMetricsForTesting()
 
@@ -404,3 +471,152 @@ func TestMetricCountGatekeeping(t *testing.T) {
 	// needing to increase this number.
 	assert.True(t, actualCardinalityCount <= expectedCardinalityCount, "General Cardinality doesn't match")
 }
+
+func TestRecordBuildInfo(t *testing.T) {
+	testCases := []struct {
+		description    string
+		inVersion      string
+		inRevision     string
+		expectedLabels prometheus.Labels
+	}{
+		{
+			description:    "Version and revision set at build time",
+			inVersion:      "0.1.0",
+			inRevision:     "4b1d3f6",
+			expectedLabels: prometheus.Labels{VersionKey: "0.1.0", RevisionKey: "4b1d3f6", BackendKey: "redis", CompressionKey: "snappy"},
+		},
+		{
+			description:    "Version and revision not set",
+			expectedLabels: prometheus.Labels{VersionKey: NotSetVal, RevisionKey: NotSetVal, BackendKey: "redis", CompressionKey: "snappy"},
+		},
+	}
+
+	for _, test := range testCases {
+		m := createPrometheusMetricsForTesting()
+
+		m.RecordBuildInfo(test.inVersion, test.inRevision, "redis", "snappy")
+
+		gauge := dto.Metric{}
+		m.BuildInfo.With(test.expectedLabels).Write(&gauge)
+		assert.Equal(t, float64(1), gauge.GetGauge().GetValue(), test.description)
+	}
+}
+
+func TestCollectors(t *testing.T) {
+	testCases := []struct {
+		description        string
+		inProcessCollector bool
+		inGoCollector      bool
+		expectedProcess    bool
+		expectedGo         bool
+	}{
+		{
+			description: "No collectors",
+		},
+		{
+			description:        "Process collector only",
+			inProcessCollector: true,
+			expectedProcess:    true,
+		},
+		{
+			description:        "Process and Go runtime collectors",
+			inProcessCollector: true,
+			inGoCollector:      true,
+			expectedProcess:    true,
+			expectedGo:         true,
+		},
+	}
+
+	for _, test := range testCases {
+		m := CreatePrometheusMetrics(config.PrometheusMetrics{
+			Namespace:        "prebid",
+			Subsystem:        "cache",
+			ProcessCollector: test.inProcessCollector,
+			GoCollector:      test.inGoCollector,
+		})
+
+		metricFamilies, err := m.Registry.Gather()
+		assert.NoError(t, err, test.description)
+
+		names := make(map[string]bool, len(metricFamilies))
+		for _, metricFamily := range metricFamilies {
+			names[metricFamily.GetName()] = true
+		}
+		assert.Equal(t, test.expectedProcess, names["prebid_cache_process_start_time_seconds"], test.description)
+		assert.Equal(t, test.expectedGo, names["go_goroutines"], test.description)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	upperBounds := func(histogram prometheus.Histogram) []float64 {
+		m := dto.Metric{}
+		histogram.Write(&m)
+		bounds := make([]float64, 0, len(m.GetHistogram().GetBucket()))
+		for _, bucket := range m.GetHistogram().GetBucket() {
+			bounds = append(bounds, bucket.GetUpperBound())
+		}
+		return bounds
+	}
+
+	testCases := []struct {
+		description      string
+		inBuckets        config.PrometheusBuckets
+		expectedDuration []float64
+		expectedSize     []float64
+		expectedTTL      []float64
+		expectedAge      []float64
+	}{
+		{
+			description:      "No buckets configured, the default ones are used",
+			expectedDuration: []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1},
+			expectedSize:     []float64{0, 4096, 8192, 16384, 32768, 65536, 131072, 262144, 524288, 1048576},
+			expectedTTL:      []float64{0.001, 1, 30, 60, 600, 900, 1800, 3600, 7200, 10800, 36000},
+			expectedAge:      []float64{1, 5, 30, 60, 300, 600, 900, 1800, 3600, 7200, 10800, 36000},
+		},
+		{
+			description: "Configured buckets",
+			inBuckets: config.PrometheusBuckets{
+				Duration: []float64{0.0005, 0.001, 0.002, 0.005},
+				Size:     []float64{1024, 16384},
+				TTL:      []float64{60, 3600},
+				Age:      []float64{30},
+			},
+			expectedDuration: []float64{0.0005, 0.001, 0.002, 0.005},
+			expectedSize:     []float64{1024, 16384},
+			expectedTTL:      []float64{60, 3600},
+			expectedAge:      []float64{30},
+		},
+	}
+
+	for _, test := range testCases {
+		m := CreatePrometheusMetrics(config.PrometheusMetrics{
+			Namespace: "prebid",
+			Subsystem: "cache",
+			Buckets:   test.inBuckets,
+		})
+
+		assert.Equal(t, test.expectedDuration, upperBounds(m.Puts.Duration), test.description)
+		assert.Equal(t, test.expectedDuration, upperBounds(m.Gets.Duration), test.description)
+		assert.Equal(t, test.expectedDuration, upperBounds(m.PutsBackend.Duration), test.description)
+		assert.Equal(t, test.expectedDuration, upperBounds(m.GetsBackend.Duration), test.description)
+		assert.Equal(t, test.expectedDuration, upperBounds(m.GetsPeer.Duration), test.description)
+		assert.Equal(t, test.expectedSize, upperBounds(m.PutsBackend.RequestLength), test.description)
+		assert.Equal(t, test.expectedTTL, upperBounds(m.PutsBackend.RequestTTLDuration), test.description)
+		assert.Equal(t, test.expectedAge, upperBounds(m.GetsValues.ValueAge), test.description)
+		for _, format := range []string{JsonVal, XmlVal} {
+			valueSize := m.GetsValues.ValueSize.With(prometheus.Labels{FormatKey: format}).(prometheus.Histogram)
+			assert.Equal(t, test.expectedSize, upperBounds(valueSize), test.description)
+		}
+
+		// Every label combination is still initialized
+		metricFamilies, err := m.Registry.Gather()
+		assert.NoError(t, err, test.description)
+		valueSizeSeries := 0
+		for _, metricFamily := range metricFamilies {
+			if metricFamily.GetName() == "prebid_cache_"+GetBackSizeMet {
+				valueSizeSeries = len(metricFamily.GetMetric())
+			}
+		}
+		assert.Equal(t, 2, valueSizeSeries, test.description)
+	}
+}
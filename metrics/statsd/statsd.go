@@ -0,0 +1,378 @@
+package metrics
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Tag keys, the same as the Prometheus label keys
+	StatusKey    string = "status"
+	FormatKey    string = "format"
+	ConnErrorKey string = "connection_error"
+	TypeKey      string = "type"
+
+	// Tag values
+	TotalsVal      string = "total"
+	ErrorVal       string = "error"
+	KeyNotFoundVal string = "key_not_found"
+	MissingKeyVal  string = "missing_key"
+	BadRequestVal  string = "bad_request"
+	JsonVal        string = "json"
+	XmlVal         string = "xml"
+	CustomKey      string = "custom_key"
+	DedupHitVal    string = "dedup_hit"
+	CollisionVal   string = "key_collision"
+	ExhaustedVal   string = "collision_retries_exhausted"
+	InvFormatVal   string = "invalid_format"
+	CloseVal       string = "close"
+	AcceptVal      string = "accept"
+	HitVal         string = "hit"
+
+	// Metric names, the same as the Prometheus metric names
+	PutRequestMet  string = "puts_request"
+	PutReqDurMet   string = "puts_request_duration"
+	PutCompSizeMet string = "puts_request_compressed_size_bytes"
+	PutBodySizeMet string = "puts_request_decompressed_size_bytes"
+	GetRequestMet  string = "gets_request"
+	GetReqDurMet   string = "gets_request_duration"
+	PutBackendMet  string = "puts_backend"
+	PutBackDurMet  string = "puts_backend_duration"
+	PutBackSizeMet string = "puts_backend_request_size_bytes"
+	PutTTLSeconds  string = "puts_backend_request_ttl"
+	GetBackendMet  string = "gets_backend"
+	GetBackendErr  string = "gets_backend_error"
+	GetBackDurMet  string = "gets_backend_duration"
+	GetBackSizeMet string = "gets_backend_value_size_bytes"
+	GetValueAgeMet string = "gets_backend_value_age_seconds"
+	GetPeerMet     string = "gets_peer"
+	GetPeerDurMet  string = "gets_peer_duration"
+	ConnOpenedMet  string = "connection_opened"
+	ConnClosedMet  string = "connection_closed"
+	ConnErrorMet   string = "connection_error"
+
+	MetricsStatsD = "StatsD"
+)
+
+// StatsDMetrics sends every metric over UDP in the StatsD line format. Counts are sent as
+// counters and durations as timers in milliseconds. Sizes, TTLs and ages are sent as histograms
+// with DogStatsD and as timers otherwise, because plain StatsD has no histogram type.
+//
+// With DogStatsD, the status, format and error type of a metric are sent as tags. Plain
+// StatsD has no tags, so they are appended to the metric name instead.
+type StatsDMetrics struct {
+	MetricsName string
+
+	conn           net.Conn
+	prefix         string
+	sampleRate     float64
+	dogStatsD      bool
+	tags           string
+	flushInterval  time.Duration
+	maxPacketBytes int
+
+	mu     sync.Mutex
+	buffer []byte
+	// random returns a number in [0, 1) that decides whether a sampled metric is sent
+	random func() float64
+}
+
+// CreateStatsDMetrics connects to the StatsD server described in cfg. Being UDP, the
+// connection succeeds even if nothing listens on the other end.
+func CreateStatsDMetrics(cfg config.StatsDMetrics) (*StatsDMetrics, error) {
+	conn, err := net.Dial("udp", net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := cfg.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+
+	return &StatsDMetrics{
+		MetricsName:    MetricsStatsD,
+		conn:           conn,
+		prefix:         prefix,
+		sampleRate:     cfg.SampleRate,
+		dogStatsD:      cfg.DogStatsD,
+		tags:           strings.Join(cfg.Tags, ","),
+		flushInterval:  cfg.FlushInterval(),
+		maxPacketBytes: cfg.MaxPacketBytes,
+		buffer:         make([]byte, 0, cfg.MaxPacketBytes),
+		random:         rand.Float64,
+	}, nil
+}
+
+// Export sends the buffered metrics every flush interval. It blocks, like the Influx engine does.
+func (m *StatsDMetrics) Export(cfg config.Metrics) {
+	log.Infof("Metrics will be exported to StatsD with host=%s, port=%d, prefix=%s", cfg.StatsD.Host, cfg.StatsD.Port, cfg.StatsD.Prefix)
+	if m.flushInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(m.flushInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.Flush()
+	}
+}
+
+// Flush sends the buffered metrics right away
+func (m *StatsDMetrics) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushLocked()
+}
+
+func (m *StatsDMetrics) GetEngineRegistry() interface{} {
+	return nil
+}
+
+func (m *StatsDMetrics) GetMetricsEngineName() string {
+	return m.MetricsName
+}
+
+func (m *StatsDMetrics) RecordPutError() {
+	m.count(PutRequestMet, StatusKey, ErrorVal)
+}
+
+func (m *StatsDMetrics) RecordPutBadRequest() {
+	m.count(PutRequestMet, StatusKey, BadRequestVal)
+}
+
+func (m *StatsDMetrics) RecordPutTotal() {
+	m.count(PutRequestMet, StatusKey, TotalsVal)
+}
+
+func (m *StatsDMetrics) RecordPutDuration(duration time.Duration) {
+	m.timing(PutReqDurMet, duration)
+}
+
+func (m *StatsDMetrics) RecordPutKeyProvided() {
+	m.count(PutRequestMet, StatusKey, CustomKey)
+}
+
+func (m *StatsDMetrics) RecordPutDedupHit() {
+	m.count(PutRequestMet, StatusKey, DedupHitVal)
+}
+
+func (m *StatsDMetrics) RecordPutKeyCollision() {
+	m.count(PutRequestMet, StatusKey, CollisionVal)
+}
+
+func (m *StatsDMetrics) RecordPutRetriesExhausted() {
+	m.count(PutRequestMet, StatusKey, ExhaustedVal)
+}
+
+func (m *StatsDMetrics) RecordPutCompressedSize(sizeInBytes float64) {
+	m.histogram(PutCompSizeMet, "", "", sizeInBytes)
+}
+
+func (m *StatsDMetrics) RecordPutDecompressedSize(sizeInBytes float64) {
+	m.histogram(PutBodySizeMet, "", "", sizeInBytes)
+}
+
+func (m *StatsDMetrics) RecordGetError() {
+	m.count(GetRequestMet, StatusKey, ErrorVal)
+}
+
+func (m *StatsDMetrics) RecordGetBadRequest() {
+	m.count(GetRequestMet, StatusKey, BadRequestVal)
+}
+
+func (m *StatsDMetrics) RecordGetTotal() {
+	m.count(GetRequestMet, StatusKey, TotalsVal)
+}
+
+func (m *StatsDMetrics) RecordGetDuration(duration time.Duration) {
+	m.timing(GetReqDurMet, duration)
+}
+
+func (m *StatsDMetrics) RecordPutBackendXml() {
+	m.count(PutBackendMet, FormatKey, XmlVal)
+}
+
+func (m *StatsDMetrics) RecordPutBackendJson() {
+	m.count(PutBackendMet, FormatKey, JsonVal)
+}
+
+func (m *StatsDMetrics) RecordPutBackendInvalid() {
+	m.count(PutBackendMet, FormatKey, InvFormatVal)
+}
+
+func (m *StatsDMetrics) RecordPutBackendDuration(duration time.Duration) {
+	m.timing(PutBackDurMet, duration)
+}
+
+func (m *StatsDMetrics) RecordPutBackendTTLSeconds(duration time.Duration) {
+	m.histogram(PutTTLSeconds, "", "", duration.Seconds())
+}
+
+func (m *StatsDMetrics) RecordPutBackendError() {
+	m.count(PutBackendMet, FormatKey, ErrorVal)
+}
+
+func (m *StatsDMetrics) RecordPutBackendSize(sizeInBytes float64) {
+	m.histogram(PutBackSizeMet, "", "", sizeInBytes)
+}
+
+func (m *StatsDMetrics) RecordGetBackendTotal() {
+	m.count(GetBackendMet, StatusKey, TotalsVal)
+}
+
+func (m *StatsDMetrics) RecordGetBackendDuration(duration time.Duration) {
+	m.timing(GetBackDurMet, duration)
+}
+
+func (m *StatsDMetrics) RecordGetBackendError() {
+	m.count(GetBackendMet, StatusKey, ErrorVal)
+}
+
+func (m *StatsDMetrics) RecordGetBackendHit() {
+	m.count(GetBackendMet, StatusKey, HitVal)
+}
+
+func (m *StatsDMetrics) RecordGetBackendJsonSize(sizeInBytes float64) {
+	m.histogram(GetBackSizeMet, FormatKey, JsonVal, sizeInBytes)
+}
+
+func (m *StatsDMetrics) RecordGetBackendXmlSize(sizeInBytes float64) {
+	m.histogram(GetBackSizeMet, FormatKey, XmlVal, sizeInBytes)
+}
+
+func (m *StatsDMetrics) RecordGetBackendValueAge(age time.Duration) {
+	m.histogram(GetValueAgeMet, "", "", age.Seconds())
+}
+
+func (m *StatsDMetrics) RecordGetPeerTotal() {
+	m.count(GetPeerMet, StatusKey, TotalsVal)
+}
+
+func (m *StatsDMetrics) RecordGetPeerDuration(duration time.Duration) {
+	m.timing(GetPeerDurMet, duration)
+}
+
+func (m *StatsDMetrics) RecordGetPeerError() {
+	m.count(GetPeerMet, StatusKey, ErrorVal)
+}
+
+func (m *StatsDMetrics) RecordGetPeerHit() {
+	m.count(GetPeerMet, StatusKey, HitVal)
+}
+
+func (m *StatsDMetrics) RecordKeyNotFoundError() {
+	m.count(GetBackendErr, TypeKey, KeyNotFoundVal)
+}
+
+func (m *StatsDMetrics) RecordMissingKeyError() {
+	m.count(GetBackendErr, TypeKey, MissingKeyVal)
+}
+
+func (m *StatsDMetrics) RecordConnectionOpen() {
+	m.count(ConnOpenedMet, "", "")
+}
+
+func (m *StatsDMetrics) RecordConnectionClosed() {
+	m.count(ConnClosedMet, "", "")
+}
+
+func (m *StatsDMetrics) RecordCloseConnectionErrors() {
+	m.count(ConnErrorMet, ConnErrorKey, CloseVal)
+}
+
+func (m *StatsDMetrics) RecordAcceptConnectionErrors() {
+	m.count(ConnErrorMet, ConnErrorKey, AcceptVal)
+}
+
+func (m *StatsDMetrics) count(name, tagKey, tagValue string) {
+	m.send(name, tagKey, tagValue, "1", "c")
+}
+
+func (m *StatsDMetrics) timing(name string, duration time.Duration) {
+	m.send(name, "", "", formatFloat(float64(duration)/float64(time.Millisecond)), "ms")
+}
+
+func (m *StatsDMetrics) histogram(name, tagKey, tagValue string, value float64) {
+	metricType := "ms"
+	if m.dogStatsD {
+		metricType = "h"
+	}
+	m.send(name, tagKey, tagValue, formatFloat(value), metricType)
+}
+
+// send formats a metric as "<prefix><name>:<value>|<type>|@<sample rate>|#<tags>" and buffers it,
+// or writes it right away if buffering is disabled
+func (m *StatsDMetrics) send(name, tagKey, tagValue, value, metricType string) {
+	if m.sampleRate < 1 && m.random() >= m.sampleRate {
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString(m.prefix)
+	line.WriteString(name)
+	if tagKey != "" && !m.dogStatsD {
+		line.WriteString(".")
+		line.WriteString(tagValue)
+	}
+	line.WriteString(":")
+	line.WriteString(value)
+	line.WriteString("|")
+	line.WriteString(metricType)
+	if m.sampleRate < 1 {
+		line.WriteString("|@")
+		line.WriteString(formatFloat(m.sampleRate))
+	}
+	if m.dogStatsD && (tagKey != "" || m.tags != "") {
+		line.WriteString("|#")
+		if tagKey != "" {
+			line.WriteString(tagKey)
+			line.WriteString(":")
+			line.WriteString(tagValue)
+			if m.tags != "" {
+				line.WriteString(",")
+			}
+		}
+		line.WriteString(m.tags)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.flushInterval <= 0 {
+		m.write([]byte(line.String()))
+		return
+	}
+	if len(m.buffer) > 0 && len(m.buffer)+1+line.Len() > m.maxPacketBytes {
+		m.flushLocked()
+	}
+	if len(m.buffer) > 0 {
+		m.buffer = append(m.buffer, '\n')
+	}
+	m.buffer = append(m.buffer, line.String()...)
+}
+
+func (m *StatsDMetrics) flushLocked() {
+	if len(m.buffer) == 0 {
+		return
+	}
+	m.write(m.buffer)
+	m.buffer = m.buffer[:0]
+}
+
+// write sends a packet. Failures are only logged at debug level: metrics must never get in the
+// way of the requests, and a missing StatsD server would otherwise flood the logs.
+func (m *StatsDMetrics) write(packet []byte) {
+	if _, err := m.conn.Write(packet); err != nil {
+		log.Debugf("Failed to send metrics to StatsD: %v", err)
+	}
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,258 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// listen starts a local UDP server and returns the config of a StatsD engine that sends to it
+func listen(t *testing.T) (*net.UDPConn, config.StatsDMetrics) {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen on UDP: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	cfg := config.StatsDMetrics{
+		Enabled:        true,
+		Host:           "127.0.0.1",
+		Port:           conn.LocalAddr().(*net.UDPAddr).Port,
+		Prefix:         "prebid_cache",
+		SampleRate:     1,
+		MaxPacketBytes: 1432,
+	}
+	return conn, cfg
+}
+
+// readPackets returns the packets the server received until none arrives for a little while
+func readPackets(conn *net.UDPConn) []string {
+	var packets []string
+	buf := make([]byte, 65536)
+	for {
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, err := conn.Read(buf)
+		if err != nil {
+			return packets
+		}
+		packets = append(packets, string(buf[:n]))
+	}
+}
+
+func recordAll(m *StatsDMetrics) {
+	m.RecordPutError()
+	m.RecordPutBadRequest()
+	m.RecordPutTotal()
+	m.RecordPutDuration(1500 * time.Microsecond)
+	m.RecordPutKeyProvided()
+	m.RecordPutDedupHit()
+	m.RecordPutKeyCollision()
+	m.RecordPutRetriesExhausted()
+	m.RecordPutCompressedSize(300)
+	m.RecordPutDecompressedSize(1000)
+	m.RecordGetError()
+	m.RecordGetBadRequest()
+	m.RecordGetTotal()
+	m.RecordGetDuration(2 * time.Millisecond)
+	m.RecordPutBackendXml()
+	m.RecordPutBackendJson()
+	m.RecordPutBackendInvalid()
+	m.RecordPutBackendDuration(3 * time.Millisecond)
+	m.RecordPutBackendTTLSeconds(time.Minute)
+	m.RecordPutBackendError()
+	m.RecordPutBackendSize(4096)
+	m.RecordGetBackendTotal()
+	m.RecordGetBackendDuration(4 * time.Millisecond)
+	m.RecordGetBackendError()
+	m.RecordGetBackendHit()
+	m.RecordGetBackendJsonSize(100)
+	m.RecordGetBackendXmlSize(200)
+	m.RecordGetBackendValueAge(90 * time.Second)
+	m.RecordGetPeerTotal()
+	m.RecordGetPeerDuration(5 * time.Millisecond)
+	m.RecordGetPeerError()
+	m.RecordGetPeerHit()
+	m.RecordKeyNotFoundError()
+	m.RecordMissingKeyError()
+	m.RecordConnectionOpen()
+	m.RecordConnectionClosed()
+	m.RecordCloseConnectionErrors()
+	m.RecordAcceptConnectionErrors()
+}
+
+func TestStatsDMetrics(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		inDogStatsD   bool
+		inTags        []string
+		expectedLines []string
+	}{
+		{
+			desc: "Plain StatsD appends the labels to the metric names",
+			expectedLines: []string{
+				"prebid_cache.puts_request.error:1|c",
+				"prebid_cache.puts_request.bad_request:1|c",
+				"prebid_cache.puts_request.total:1|c",
+				"prebid_cache.puts_request_duration:1.5|ms",
+				"prebid_cache.puts_request.custom_key:1|c",
+				"prebid_cache.puts_request.dedup_hit:1|c",
+				"prebid_cache.puts_request.key_collision:1|c",
+				"prebid_cache.puts_request.collision_retries_exhausted:1|c",
+				"prebid_cache.puts_request_compressed_size_bytes:300|ms",
+				"prebid_cache.puts_request_decompressed_size_bytes:1000|ms",
+				"prebid_cache.gets_request.error:1|c",
+				"prebid_cache.gets_request.bad_request:1|c",
+				"prebid_cache.gets_request.total:1|c",
+				"prebid_cache.gets_request_duration:2|ms",
+				"prebid_cache.puts_backend.xml:1|c",
+				"prebid_cache.puts_backend.json:1|c",
+				"prebid_cache.puts_backend.invalid_format:1|c",
+				"prebid_cache.puts_backend_duration:3|ms",
+				"prebid_cache.puts_backend_request_ttl:60|ms",
+				"prebid_cache.puts_backend.error:1|c",
+				"prebid_cache.puts_backend_request_size_bytes:4096|ms",
+				"prebid_cache.gets_backend.total:1|c",
+				"prebid_cache.gets_backend_duration:4|ms",
+				"prebid_cache.gets_backend.error:1|c",
+				"prebid_cache.gets_backend.hit:1|c",
+				"prebid_cache.gets_backend_value_size_bytes.json:100|ms",
+				"prebid_cache.gets_backend_value_size_bytes.xml:200|ms",
+				"prebid_cache.gets_backend_value_age_seconds:90|ms",
+				"prebid_cache.gets_peer.total:1|c",
+				"prebid_cache.gets_peer_duration:5|ms",
+				"prebid_cache.gets_peer.error:1|c",
+				"prebid_cache.gets_peer.hit:1|c",
+				"prebid_cache.gets_backend_error.key_not_found:1|c",
+				"prebid_cache.gets_backend_error.missing_key:1|c",
+				"prebid_cache.connection_opened:1|c",
+				"prebid_cache.connection_closed:1|c",
+				"prebid_cache.connection_error.close:1|c",
+				"prebid_cache.connection_error.accept:1|c",
+			},
+		},
+		{
+			desc:        "DogStatsD sends the labels and the configured tags as tags",
+			inDogStatsD: true,
+			inTags:      []string{"env:prod", "region:us-east"},
+			expectedLines: []string{
+				"prebid_cache.puts_request:1|c|#status:error,env:prod,region:us-east",
+				"prebid_cache.puts_request:1|c|#status:bad_request,env:prod,region:us-east",
+				"prebid_cache.puts_request:1|c|#status:total,env:prod,region:us-east",
+				"prebid_cache.puts_request_duration:1.5|ms|#env:prod,region:us-east",
+				"prebid_cache.puts_request:1|c|#status:custom_key,env:prod,region:us-east",
+				"prebid_cache.puts_request:1|c|#status:dedup_hit,env:prod,region:us-east",
+				"prebid_cache.puts_request:1|c|#status:key_collision,env:prod,region:us-east",
+				"prebid_cache.puts_request:1|c|#status:collision_retries_exhausted,env:prod,region:us-east",
+				"prebid_cache.puts_request_compressed_size_bytes:300|h|#env:prod,region:us-east",
+				"prebid_cache.puts_request_decompressed_size_bytes:1000|h|#env:prod,region:us-east",
+				"prebid_cache.gets_request:1|c|#status:error,env:prod,region:us-east",
+				"prebid_cache.gets_request:1|c|#status:bad_request,env:prod,region:us-east",
+				"prebid_cache.gets_request:1|c|#status:total,env:prod,region:us-east",
+				"prebid_cache.gets_request_duration:2|ms|#env:prod,region:us-east",
+				"prebid_cache.puts_backend:1|c|#format:xml,env:prod,region:us-east",
+				"prebid_cache.puts_backend:1|c|#format:json,env:prod,region:us-east",
+				"prebid_cache.puts_backend:1|c|#format:invalid_format,env:prod,region:us-east",
+				"prebid_cache.puts_backend_duration:3|ms|#env:prod,region:us-east",
+				"prebid_cache.puts_backend_request_ttl:60|h|#env:prod,region:us-east",
+				"prebid_cache.puts_backend:1|c|#format:error,env:prod,region:us-east",
+				"prebid_cache.puts_backend_request_size_bytes:4096|h|#env:prod,region:us-east",
+				"prebid_cache.gets_backend:1|c|#status:total,env:prod,region:us-east",
+				"prebid_cache.gets_backend_duration:4|ms|#env:prod,region:us-east",
+				"prebid_cache.gets_backend:1|c|#status:error,env:prod,region:us-east",
+				"prebid_cache.gets_backend:1|c|#status:hit,env:prod,region:us-east",
+				"prebid_cache.gets_backend_value_size_bytes:100|h|#format:json,env:prod,region:us-east",
+				"prebid_cache.gets_backend_value_size_bytes:200|h|#format:xml,env:prod,region:us-east",
+				"prebid_cache.gets_backend_value_age_seconds:90|h|#env:prod,region:us-east",
+				"prebid_cache.gets_peer:1|c|#status:total,env:prod,region:us-east",
+				"prebid_cache.gets_peer_duration:5|ms|#env:prod,region:us-east",
+				"prebid_cache.gets_peer:1|c|#status:error,env:prod,region:us-east",
+				"prebid_cache.gets_peer:1|c|#status:hit,env:prod,region:us-east",
+				"prebid_cache.gets_backend_error:1|c|#type:key_not_found,env:prod,region:us-east",
+				"prebid_cache.gets_backend_error:1|c|#type:missing_key,env:prod,region:us-east",
+				"prebid_cache.connection_opened:1|c|#env:prod,region:us-east",
+				"prebid_cache.connection_closed:1|c|#env:prod,region:us-east",
+				"prebid_cache.connection_error:1|c|#connection_error:close,env:prod,region:us-east",
+				"prebid_cache.connection_error:1|c|#connection_error:accept,env:prod,region:us-east",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		server, cfg := listen(t)
+		cfg.DogStatsD = tc.inDogStatsD
+		cfg.Tags = tc.inTags
+		m, err := CreateStatsDMetrics(cfg)
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+
+		recordAll(m)
+
+		assert.Equal(t, tc.expectedLines, readPackets(server), tc.desc)
+	}
+}
+
+func TestStatsDSampling(t *testing.T) {
+	server, cfg := listen(t)
+	cfg.SampleRate = 0.25
+	m, err := CreateStatsDMetrics(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	draws := []float64{0.1, 0.5, 0.2}
+	m.random = func() float64 {
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	}
+
+	m.RecordPutTotal()
+	m.RecordPutTotal()
+	m.RecordPutDuration(time.Millisecond)
+
+	assert.Equal(t, []string{
+		"prebid_cache.puts_request.total:1|c|@0.25",
+		"prebid_cache.puts_request_duration:1|ms|@0.25",
+	}, readPackets(server))
+}
+
+func TestStatsDBuffering(t *testing.T) {
+	server, cfg := listen(t)
+	cfg.FlushIntervalMillis = 60000
+	cfg.MaxPacketBytes = 80
+	m, err := CreateStatsDMetrics(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	m.RecordPutTotal()
+	m.RecordGetTotal()
+	assert.Empty(t, readPackets(server), "Metrics are buffered until the packet is full")
+
+	// "prebid_cache.connection_opened:1|c" doesn't fit in an 80 byte packet along with the two lines above
+	m.RecordConnectionOpen()
+	m.Flush()
+
+	assert.Equal(t, []string{
+		"prebid_cache.puts_request.total:1|c\nprebid_cache.gets_request.total:1|c",
+		"prebid_cache.connection_opened:1|c",
+	}, readPackets(server))
+}
+
+func TestStatsDExport(t *testing.T) {
+	server, cfg := listen(t)
+	cfg.FlushIntervalMillis = 10
+	m, err := CreateStatsDMetrics(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	go m.Export(config.Metrics{StatsD: cfg})
+
+	m.RecordPutTotal()
+
+	assert.Equal(t, []string{"prebid_cache.puts_request.total:1|c"}, readPackets(server), "The buffer is flushed every flush interval")
+}
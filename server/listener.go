@@ -2,6 +2,7 @@ package server
 
 import (
 	"net"
+	"sync"
 	"time"
 
 	"github.com/prebid/prebid-cache/metrics"
@@ -51,8 +52,11 @@ func (ln *monitorableListener) Accept() (c net.Conn, err error) {
 // We should revisit this after Go 1.11. See also:
 // - https://github.com/golang/go/issues/23378
 // - https://github.com/golang/go/issues/23459
+//
+// A period of 0 disables the keep-alive probes.
 type tcpKeepAliveListener struct {
 	*net.TCPListener
+	period time.Duration
 }
 
 func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
@@ -60,7 +64,81 @@ func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ln.period <= 0 {
+		tc.SetKeepAlive(false)
+		return tc, nil
+	}
 	tc.SetKeepAlive(true)
-	tc.SetKeepAlivePeriod(3 * time.Minute)
+	tc.SetKeepAlivePeriod(ln.period)
 	return tc, nil
 }
+
+// connectionLimit caps the number of connections a server keeps open across all of its listeners.
+// A nil connectionLimit doesn't limit anything.
+type connectionLimit struct {
+	sem chan struct{}
+}
+
+// newConnectionLimit returns a limit of max connections, or nil if max isn't positive
+func newConnectionLimit(max int) *connectionLimit {
+	if max <= 0 {
+		return nil
+	}
+	return &connectionLimit{sem: make(chan struct{}, max)}
+}
+
+// limit makes the connections ln accepts wait for a connection of any listener sharing the limit
+// to close before they're served, once the limit is reached. Unlike netutil.LimitListener, which
+// only counts the connections of a single listener, a listener waiting for new connections doesn't
+// hold on to a slot, since that could keep the other listeners from serving any.
+func (l *connectionLimit) limit(ln net.Listener) net.Listener {
+	if l == nil {
+		return ln
+	}
+	return &limitListener{Listener: ln, sem: l.sem, done: make(chan struct{})}
+}
+
+type limitListener struct {
+	net.Listener
+	sem       chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (ln *limitListener) Accept() (net.Conn, error) {
+	c, err := ln.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case ln.sem <- struct{}{}:
+		return &limitListenerConn{Conn: c, release: ln.release}, nil
+	case <-ln.done:
+		c.Close()
+		return nil, net.ErrClosed
+	}
+}
+
+func (ln *limitListener) release() {
+	<-ln.sem
+}
+
+func (ln *limitListener) Close() error {
+	err := ln.Listener.Close()
+	ln.closeOnce.Do(func() { close(ln.done) })
+	return err
+}
+
+// limitListenerConn frees its connection slot the first time it's closed
+type limitListenerConn struct {
+	net.Conn
+	releaseOnce sync.Once
+	release     func()
+}
+
+func (c *limitListenerConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
@@ -62,7 +62,7 @@ func TestConnections(t *testing.T) {
 		mockMetrics := metricstest.CreateMockMetrics()
 		m := &metrics.Metrics{
 			MetricEngines: []metrics.CacheMetrics{
-				&mockMetrics,
+				mockMetrics,
 			},
 		}
 
@@ -4,10 +4,10 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/prebid/prebid-cache/config"
+	localprometheus "github.com/prebid/prebid-cache/metrics/prometheus"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -16,17 +16,7 @@ func newPrometheusServer(cfg *config.Configuration, promRegistry *prometheus.Reg
 		log.Errorf("Prometheus metrics configured, but a Prometheus metrics engine was not found. Cannot set up a Prometheus listener.")
 	}
 	return &http.Server{
-		Addr: ":" + strconv.Itoa(cfg.Metrics.Prometheus.Port),
-		Handler: promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{
-			ErrorLog:            loggerForPrometheus{},
-			MaxRequestsInFlight: 5,
-			Timeout:             cfg.Metrics.Prometheus.Timeout(),
-		}),
+		Addr:    ":" + strconv.Itoa(cfg.Metrics.Prometheus.Port),
+		Handler: localprometheus.NewHandler(promRegistry, cfg.Metrics.Prometheus),
 	}
 }
-
-type loggerForPrometheus struct{}
-
-func (loggerForPrometheus) Println(v ...interface{}) {
-	log.Warning(v...)
-}
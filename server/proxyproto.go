@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-cache/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// proxyProtocolV1Prefix starts every version 1 (human-readable) PROXY protocol header
+var proxyProtocolV1Prefix = []byte("PROXY ")
+
+// proxyProtocolV2Signature starts every version 2 (binary) PROXY protocol header
+var proxyProtocolV2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+const (
+	// A version 1 header can't be longer than 107 bytes, CRLF included
+	proxyProtocolV1MaxLength = 107
+	// Fixed part of a version 2 header: signature, version and command, family and length
+	proxyProtocolV2HeaderLength = 16
+)
+
+// proxyProtocolListener reads the PROXY protocol header sent by trusted load balancers so
+// the connection's RemoteAddr() reflects the address of the actual client. Connections that
+// come from peers outside of the trusted networks are left untouched.
+type proxyProtocolListener struct {
+	net.Listener
+	trusted       *utils.TrustedProxies
+	headerTimeout time.Duration
+}
+
+func (ln *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := ln.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	peer, _ := conn.RemoteAddr().(*net.TCPAddr)
+	if peer == nil || !ln.trusted.Contains(peer.IP) {
+		return conn, nil
+	}
+
+	return &proxyProtocolConn{
+		Conn:          conn,
+		reader:        bufio.NewReader(conn),
+		headerTimeout: ln.headerTimeout,
+	}, nil
+}
+
+// proxyProtocolConn parses the PROXY protocol header the first time the connection is read from
+// or its addresses are requested. The header is optional: if the trusted peer didn't send one,
+// the connection is served with its original addresses.
+type proxyProtocolConn struct {
+	net.Conn
+	reader        *bufio.Reader
+	headerTimeout time.Duration
+
+	once       sync.Once
+	headerErr  error
+	remoteAddr net.Addr
+	localAddr  net.Addr
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.headerErr != nil {
+		return 0, c.headerErr
+	}
+	return c.reader.Read(b)
+}
+
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyProtocolConn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.localAddr != nil {
+		return c.localAddr
+	}
+	return c.Conn.LocalAddr()
+}
+
+func (c *proxyProtocolConn) readHeader() {
+	if c.headerTimeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.headerTimeout))
+		defer c.Conn.SetReadDeadline(time.Time{})
+	}
+
+	c.remoteAddr, c.localAddr, c.headerErr = parseProxyProtocolHeader(c.reader)
+	if c.headerErr != nil {
+		log.Errorf("Error reading PROXY protocol header from %s: %v", c.Conn.RemoteAddr(), c.headerErr)
+	}
+}
+
+// parseProxyProtocolHeader consumes a version 1 or version 2 PROXY protocol header from the reader,
+// if any. It returns nil addresses when no header was sent or when the header doesn't carry the
+// addresses of the original connection, as it happens with health checks sent by the proxy itself.
+func parseProxyProtocolHeader(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	if prefix, err := r.Peek(len(proxyProtocolV2Signature)); err == nil && bytes.Equal(prefix, proxyProtocolV2Signature) {
+		return parseProxyProtocolV2(r)
+	}
+	prefix, err := r.Peek(len(proxyProtocolV1Prefix))
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if bytes.Equal(prefix, proxyProtocolV1Prefix) {
+		return parseProxyProtocolV1(r)
+	}
+	return nil, nil, nil
+}
+
+func parseProxyProtocolV1(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	var line []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, fmt.Errorf("incomplete PROXY protocol v1 header: %v", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+		if len(line) >= proxyProtocolV1MaxLength {
+			return nil, nil, errors.New("PROXY protocol v1 header is too long")
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, errors.New("PROXY protocol v1 header must end with CRLF")
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("malformed PROXY protocol v1 header %q", strings.TrimSpace(string(line)))
+	}
+
+	srcIP, dstIP := net.ParseIP(fields[2]), net.ParseIP(fields[3])
+	srcPort, srcErr := strconv.ParseUint(fields[4], 10, 16)
+	dstPort, dstErr := strconv.ParseUint(fields[5], 10, 16)
+	if srcIP == nil || dstIP == nil || srcErr != nil || dstErr != nil {
+		return nil, nil, fmt.Errorf("malformed PROXY protocol v1 addresses %q", strings.TrimSpace(string(line)))
+	}
+
+	return &net.TCPAddr{IP: srcIP, Port: int(srcPort)}, &net.TCPAddr{IP: dstIP, Port: int(dstPort)}, nil
+}
+
+func parseProxyProtocolV2(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	header := make([]byte, proxyProtocolV2HeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, fmt.Errorf("incomplete PROXY protocol v2 header: %v", err)
+	}
+
+	version, command := header[12]>>4, header[12]&0x0F
+	family := header[13] >> 4
+	length := binary.BigEndian.Uint16(header[14:16])
+
+	if version != 2 {
+		return nil, nil, fmt.Errorf("unsupported PROXY protocol version %d", version)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, fmt.Errorf("incomplete PROXY protocol v2 addresses: %v", err)
+	}
+
+	switch command {
+	case 0x0: // LOCAL: the proxy opened the connection itself
+		return nil, nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, nil, fmt.Errorf("unsupported PROXY protocol v2 command %d", command)
+	}
+
+	switch family {
+	case 0x1: // AF_INET
+		if len(payload) < 12 {
+			return nil, nil, errors.New("PROXY protocol v2 IPv4 addresses are truncated")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))},
+			&net.TCPAddr{IP: net.IP(payload[4:8]), Port: int(binary.BigEndian.Uint16(payload[10:12]))}, nil
+	case 0x2: // AF_INET6
+		if len(payload) < 36 {
+			return nil, nil, errors.New("PROXY protocol v2 IPv6 addresses are truncated")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))},
+			&net.TCPAddr{IP: net.IP(payload[16:32]), Port: int(binary.BigEndian.Uint16(payload[34:36]))}, nil
+	default:
+		// AF_UNSPEC or AF_UNIX: the addresses of the original connection are not useful to us
+		return nil, nil, nil
+	}
+}
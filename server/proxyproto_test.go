@@ -0,0 +1,192 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProxyProtocolHeader(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		in                 []byte
+		expectedRemoteAddr string
+		expectedLocalAddr  string
+		expectedError      bool
+		expectedRemainder  string
+	}{
+		{
+			desc:              "No header. Leave the stream untouched",
+			in:                []byte("GET /status HTTP/1.1\r\n\r\n"),
+			expectedRemainder: "GET /status HTTP/1.1\r\n\r\n",
+		},
+		{
+			desc:              "Empty stream",
+			in:                []byte{},
+			expectedRemainder: "",
+		},
+		{
+			desc:               "Version 1 TCP4",
+			in:                 []byte("PROXY TCP4 198.51.100.1 10.0.0.1 56324 2424\r\nGET / HTTP/1.1\r\n\r\n"),
+			expectedRemoteAddr: "198.51.100.1:56324",
+			expectedLocalAddr:  "10.0.0.1:2424",
+			expectedRemainder:  "GET / HTTP/1.1\r\n\r\n",
+		},
+		{
+			desc:               "Version 1 TCP6",
+			in:                 []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 2424\r\nGET"),
+			expectedRemoteAddr: "[2001:db8::1]:56324",
+			expectedLocalAddr:  "[2001:db8::2]:2424",
+			expectedRemainder:  "GET",
+		},
+		{
+			desc:              "Version 1 UNKNOWN keeps the original addresses",
+			in:                []byte("PROXY UNKNOWN\r\nGET"),
+			expectedRemainder: "GET",
+		},
+		{
+			desc:          "Version 1 with bad addresses",
+			in:            []byte("PROXY TCP4 198.51.100.300 10.0.0.1 56324 2424\r\n"),
+			expectedError: true,
+		},
+		{
+			desc:          "Version 1 without CRLF",
+			in:            []byte("PROXY TCP4 198.51.100.1 10.0.0.1 56324 2424\n"),
+			expectedError: true,
+		},
+		{
+			desc:          "Version 1 too long",
+			in:            []byte("PROXY TCP4 " + strings.Repeat("1", 120) + "\r\n"),
+			expectedError: true,
+		},
+		{
+			desc:               "Version 2 IPv4",
+			in:                 append(proxyProtocolV2Header(0x21, 0x11, []byte{198, 51, 100, 1, 10, 0, 0, 1, 0xDC, 0x04, 0x09, 0x78}), []byte("GET")...),
+			expectedRemoteAddr: "198.51.100.1:56324",
+			expectedLocalAddr:  "10.0.0.1:2424",
+			expectedRemainder:  "GET",
+		},
+		{
+			desc: "Version 2 IPv6",
+			in: append(proxyProtocolV2Header(0x21, 0x21, append(append(
+				net.ParseIP("2001:db8::1").To16(),
+				net.ParseIP("2001:db8::2").To16()...),
+				0xDC, 0x04, 0x09, 0x78)), []byte("GET")...),
+			expectedRemoteAddr: "[2001:db8::1]:56324",
+			expectedLocalAddr:  "[2001:db8::2]:2424",
+			expectedRemainder:  "GET",
+		},
+		{
+			desc:              "Version 2 LOCAL command keeps the original addresses",
+			in:                append(proxyProtocolV2Header(0x20, 0x00, nil), []byte("GET")...),
+			expectedRemainder: "GET",
+		},
+		{
+			desc:          "Version 2 truncated addresses",
+			in:            proxyProtocolV2Header(0x21, 0x11, []byte{198, 51, 100, 1}),
+			expectedError: true,
+		},
+		{
+			desc:          "Unsupported version",
+			in:            proxyProtocolV2Header(0x31, 0x11, []byte{198, 51, 100, 1, 10, 0, 0, 1, 0xDC, 0x04, 0x09, 0x78}),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		reader := bufio.NewReader(bytes.NewReader(tc.in))
+
+		remoteAddr, localAddr, err := parseProxyProtocolHeader(reader)
+		if tc.expectedError {
+			assert.Error(t, err, tc.desc)
+			continue
+		}
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+
+		if len(tc.expectedRemoteAddr) > 0 {
+			assert.Equal(t, tc.expectedRemoteAddr, remoteAddr.String(), tc.desc)
+			assert.Equal(t, tc.expectedLocalAddr, localAddr.String(), tc.desc)
+		} else {
+			assert.Nil(t, remoteAddr, tc.desc)
+			assert.Nil(t, localAddr, tc.desc)
+		}
+
+		remainder, _ := ioutil.ReadAll(reader)
+		assert.Equal(t, tc.expectedRemainder, string(remainder), tc.desc)
+	}
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		trusted            []string
+		payload            string
+		expectedRemoteHost string
+		expectedPayload    string
+	}{
+		{
+			desc:               "Trusted peer. The PROXY header sets the remote address",
+			trusted:            []string{"127.0.0.0/8"},
+			payload:            "PROXY TCP4 198.51.100.1 10.0.0.1 56324 2424\r\nhello",
+			expectedRemoteHost: "198.51.100.1",
+			expectedPayload:    "hello",
+		},
+		{
+			desc:               "Untrusted peer. The PROXY header is not interpreted",
+			trusted:            []string{"10.0.0.0/8"},
+			payload:            "PROXY TCP4 198.51.100.1 10.0.0.1 56324 2424\r\nhello",
+			expectedRemoteHost: "127.0.0.1",
+			expectedPayload:    "PROXY TCP4 198.51.100.1 10.0.0.1 56324 2424\r\nhello",
+		},
+	}
+
+	for _, tc := range testCases {
+		trusted, err := utils.NewTrustedProxies(tc.trusted)
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+
+		tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+		ln := &proxyProtocolListener{Listener: tcpListener, trusted: trusted, headerTimeout: time.Second}
+
+		go func(payload string) {
+			client, err := net.Dial("tcp", tcpListener.Addr().String())
+			if err != nil {
+				return
+			}
+			client.Write([]byte(payload))
+			client.Close()
+		}(tc.payload)
+
+		conn, err := ln.Accept()
+		if assert.NoError(t, err, tc.desc) {
+			host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+			assert.Equal(t, tc.expectedRemoteHost, host, tc.desc)
+
+			payload, _ := ioutil.ReadAll(conn)
+			assert.Equal(t, tc.expectedPayload, string(payload), tc.desc)
+			conn.Close()
+		}
+		ln.Close()
+	}
+}
+
+func proxyProtocolV2Header(versionCommand, family byte, addresses []byte) []byte {
+	header := append([]byte{}, proxyProtocolV2Signature...)
+	header = append(header, versionCommand, family, 0, 0)
+	binary.BigEndian.PutUint16(header[14:16], uint16(len(addresses)))
+	return append(header, addresses...)
+}
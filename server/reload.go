@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/prebid/prebid-cache/config"
+)
+
+// LoadConfigFunc reads the configuration from its sources again
+type LoadConfigFunc func() (config.Configuration, error)
+
+// BuildHandlersFunc creates the handlers of the main and admin servers out of a configuration
+type BuildHandlersFunc func(cfg config.Configuration) (publicHandler http.Handler, adminHandler http.Handler)
+
+// Reloader serves requests with handlers built from the latest valid configuration. Reloading
+// builds a new pair of handlers and swaps them atomically, so in-flight requests finish with the
+// handlers they started with and the backend connections, along with the contents of the memory
+// backend, survive.
+type Reloader struct {
+	load  LoadConfigFunc
+	build BuildHandlersFunc
+
+	// mu serializes reloads. Requests never take it.
+	mu       sync.Mutex
+	current  config.Configuration
+	handlers atomic.Value
+}
+
+type serverHandlers struct {
+	public http.Handler
+	admin  http.Handler
+}
+
+// NewReloader builds the initial handlers out of cfg, which must have been validated already
+func NewReloader(cfg config.Configuration, load LoadConfigFunc, build BuildHandlersFunc) *Reloader {
+	r := &Reloader{
+		load:    load,
+		build:   build,
+		current: cfg,
+	}
+	publicHandler, adminHandler := build(cfg)
+	r.handlers.Store(serverHandlers{public: publicHandler, admin: adminHandler})
+	return r
+}
+
+// PublicHandler returns a handler that always forwards to the current main server handler
+func (r *Reloader) PublicHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.handlers.Load().(serverHandlers).public.ServeHTTP(w, req)
+	})
+}
+
+// AdminHandler returns a handler that always forwards to the current admin server handler
+func (r *Reloader) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.handlers.Load().(serverHandlers).admin.ServeHTTP(w, req)
+	})
+}
+
+// Config returns the configuration the current handlers were built with
+func (r *Reloader) Config() config.Configuration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload reads and validates the configuration again. If it's valid and only settings that are
+// safe to change at runtime were modified, the new log level and handlers take effect. Otherwise,
+// the running configuration is kept and the reason is returned.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := r.load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.ValidateAndLog(); err != nil {
+		return err
+	}
+	if err := config.CheckReloadable(r.current, cfg); err != nil {
+		return err
+	}
+	level, err := log.ParseLevel(string(cfg.Log.Level))
+	if err != nil {
+		return err
+	}
+
+	publicHandler, adminHandler := r.build(cfg)
+	r.handlers.Store(serverHandlers{public: publicHandler, admin: adminHandler})
+	log.SetLevel(level)
+	r.current = cfg
+	return nil
+}
+
+// reloadAfterSignals reloads the configuration every time a signal comes in, until the channel is closed
+func reloadAfterSignals(reloader *Reloader, signals <-chan os.Signal) {
+	for sig := range signals {
+		log.Infof("Reloading configuration because of signal: %s", sig.String())
+		if err := reloader.Reload(); err != nil {
+			log.Errorf("Configuration was not reloaded, Prebid Cache keeps running with the previous one: %v", err)
+			continue
+		}
+		log.Info("Configuration reloaded")
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReload(t *testing.T) {
+	defer logrus.SetLevel(logrus.GetLevel())
+
+	testCases := []struct {
+		description           string
+		update                func(cfg *config.Configuration)
+		loadErr               error
+		expectedError         string
+		expectedIndexResponse string
+		expectedLogLevel      logrus.Level
+	}{
+		{
+			description:           "Runtime-safe changes take effect",
+			update:                func(cfg *config.Configuration) { cfg.IndexResponse = "reloaded"; cfg.Log.Level = config.Debug },
+			expectedIndexResponse: "reloaded",
+			expectedLogLevel:      logrus.DebugLevel,
+		},
+		{
+			description:           "Config file can't be loaded. Keep the running configuration",
+			loadErr:               errors.New("Configuration file could not be read: bad yaml"),
+			expectedError:         "Configuration file could not be read: bad yaml",
+			expectedIndexResponse: "initial",
+			expectedLogLevel:      logrus.InfoLevel,
+		},
+		{
+			description:           "Invalid change. Keep the running configuration",
+			update:                func(cfg *config.Configuration) { cfg.IndexResponse = "reloaded"; cfg.RequestLimits.MaxSize = -1 },
+			expectedError:         "invalid config.request_limits.max_size_bytes: -1. Value cannot be negative.",
+			expectedIndexResponse: "initial",
+			expectedLogLevel:      logrus.InfoLevel,
+		},
+		{
+			description:           "Change that requires a restart. Keep the running configuration",
+			update:                func(cfg *config.Configuration) { cfg.IndexResponse = "reloaded"; cfg.Port = 9000 },
+			expectedError:         "config.port cannot be changed without restarting Prebid Cache",
+			expectedIndexResponse: "initial",
+			expectedLogLevel:      logrus.InfoLevel,
+		},
+	}
+
+	for _, tc := range testCases {
+		logrus.SetLevel(logrus.InfoLevel)
+		initial := newReloadTestConfig("initial")
+		updated := newReloadTestConfig("initial")
+		if tc.update != nil {
+			tc.update(&updated)
+		}
+
+		load := func() (config.Configuration, error) {
+			return updated, tc.loadErr
+		}
+		build := func(cfg config.Configuration) (http.Handler, http.Handler) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(cfg.IndexResponse))
+			})
+			return h, h
+		}
+		reloader := NewReloader(initial, load, build)
+
+		err := reloader.Reload()
+		if len(tc.expectedError) > 0 {
+			assert.EqualError(t, err, tc.expectedError, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+
+		for _, handler := range []http.Handler{reloader.PublicHandler(), reloader.AdminHandler()} {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+			assert.Equal(t, tc.expectedIndexResponse, recorder.Body.String(), tc.description)
+		}
+		assert.Equal(t, tc.expectedLogLevel, logrus.GetLevel(), tc.description)
+	}
+}
+
+func TestReloadAfterSignals(t *testing.T) {
+	reloaded := make(chan struct{}, 2)
+	load := func() (config.Configuration, error) {
+		reloaded <- struct{}{}
+		return newReloadTestConfig("reloaded"), nil
+	}
+	build := func(cfg config.Configuration) (http.Handler, http.Handler) {
+		return http.NotFoundHandler(), http.NotFoundHandler()
+	}
+	reloader := NewReloader(newReloadTestConfig("initial"), load, build)
+
+	signals := make(chan os.Signal, 2)
+	signals <- syscall.SIGHUP
+	signals <- syscall.SIGHUP
+	close(signals)
+
+	reloadAfterSignals(reloader, signals)
+	assert.Len(t, reloaded, 2, "Every signal should trigger a reload")
+}
+
+func newReloadTestConfig(indexResponse string) config.Configuration {
+	return config.Configuration{
+		Port:          2424,
+		AdminPort:     2525,
+		IndexResponse: indexResponse,
+		Log:           config.Log{Level: config.Info},
+		Backend:       config.Backend{Type: config.BackendMemory},
+		Compression:   config.Compression{Type: config.CompressionSnappy},
+		RequestLimits: config.RequestLimits{MaxBodySize: 1024, MaxDecompressedBodySize: 1024},
+		Keys:          config.Keys{Format: config.IDFormatUUIDv4, CollisionRetries: 3},
+		Routes:        config.Routes{ErrorFormat: config.ErrorFormatText},
+		Peers:         config.Peers{Scheme: "https", TimeoutMillis: 200, MaxResponseSizeBytes: 1024},
+		Metrics:       config.Metrics{Type: config.MetricsNone},
+		Shutdown:      config.Shutdown{DrainTimeoutMillis: 10000, CloseTimeoutMillis: 5000},
+	}
+}
@@ -1,102 +1,207 @@
 package server
 
 import (
-	"context"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"syscall"
-	"time"
 
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
+	"github.com/prebid/prebid-cache/backends"
 	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/endpoints"
 	"github.com/prebid/prebid-cache/metrics"
 	localprometheus "github.com/prebid/prebid-cache/metrics/prometheus"
+	"github.com/prebid/prebid-cache/utils"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// Listen serves requests and blocks forever, until OS signals shut down the process.
-func Listen(cfg config.Configuration, publicHandler http.Handler, adminHandler http.Handler, metrics *metrics.Metrics) {
-	stopSignals := make(chan os.Signal)
+// Listen serves requests and blocks until SIGTERM or SIGINT shut down the process gracefully. SIGHUP
+// reloads the configuration through the reloader.
+func Listen(cfg config.Configuration, reloader *Reloader, metrics *metrics.Metrics, readiness *endpoints.Readiness, backend backends.Backend) {
+	stopSignals := make(chan os.Signal, 1)
 	signal.Notify(stopSignals, syscall.SIGTERM, syscall.SIGINT)
 
-	stopAdmin := make(chan os.Signal)
-	stopMain := make(chan os.Signal)
-	stopPrometheus := make(chan os.Signal)
-	done := make(chan struct{})
-
-	// Rig up each server so that it listens on a channel for signals. These use different channels for each server
-	// because a shared channel would only alert one consumer (whichever one happens to read it first).
-	//
-	// After a server has finished shutting down, it should send a signal in through the "done" channel.
-	mainServer := newMainServer(cfg, publicHandler)
-	adminServer := newAdminServer(cfg, adminHandler)
-	go shutdownAfterSignals(mainServer, stopMain, done)
-	go shutdownAfterSignals(adminServer, stopAdmin, done)
-
-	// Attach the servers to the sockets
-	mainListener, err := newListener(mainServer.Addr, metrics)
-	if err != nil {
-		log.Errorf("Error listening for TCP connections on %s: %v", mainServer.Addr, err)
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	go reloadAfterSignals(reloader, reloadSignals)
+
+	mainServer := newMainServer(cfg, reloader.PublicHandler())
+	adminServer := newAdminServer(cfg, reloader.AdminHandler())
+	servers := []*http.Server{mainServer, adminServer}
+
+	// Requests received over h2c connections are tracked separately because the servers stop
+	// tracking those connections once they are upgraded to HTTP/2
+	var h2cRequests sync.WaitGroup
+	if err := enableH2C(mainServer, cfg.Server.Main, &h2cRequests); err != nil {
+		log.Errorf("Error setting up h2c on %s: %v", mainServer.Addr, err)
 		return
 	}
-	adminListener, err := newListener(adminServer.Addr, nil)
-	if err != nil {
-		log.Errorf("Error listening for TCP connections on %s: %v", adminServer.Addr, err)
+	if err := enableH2C(adminServer, cfg.Server.Admin, &h2cRequests); err != nil {
+		log.Errorf("Error setting up h2c on %s: %v", adminServer.Addr, err)
+		return
+	}
+
+	if err := enableTLS(mainServer, "Main", cfg.Server.Main.TLS); err != nil {
+		log.Errorf("Error setting up TLS on %s: %v", mainServer.Addr, err)
+		return
+	}
+	if err := enableTLS(adminServer, "Admin", cfg.Server.Admin.TLS); err != nil {
+		log.Errorf("Error setting up TLS on %s: %v", adminServer.Addr, err)
 		return
 	}
-	go runServer(mainServer, "Main", mainListener)
-	go runServer(adminServer, "Admin", adminListener)
 
-	// Then block the thread. When the OS sends a shutdown signal, alert each of the servers.
-	// Once they're finished shutting down (the "done" channel gets pinged for each server),
-	// this funciton can return.
-	if cfg.Metrics.Prometheus.Enabled {
+	// Attach the servers to the sockets. The connections of all the listeners of a server count
+	// towards its max_connections.
+	var mainListeners, adminListeners []net.Listener
+	mainLimit := newConnectionLimit(cfg.Server.Main.MaxConnections)
+	adminLimit := newConnectionLimit(cfg.Server.Admin.MaxConnections)
+	if !cfg.Server.Main.UnixSocket.DisableTCP {
+		mainListener, err := newListener(mainServer.Addr, cfg.Server.Main, mainLimit, metrics)
+		if err != nil {
+			log.Errorf("Error listening for TCP connections on %s: %v", mainServer.Addr, err)
+			return
+		}
+		if cfg.ClientIP.ProxyProtocol.Enabled {
+			if mainListener, err = withProxyProtocol(mainListener, cfg.ClientIP); err != nil {
+				log.Errorf("Error setting up the PROXY protocol on %s: %v", mainServer.Addr, err)
+				return
+			}
+		}
+		mainListeners = append(mainListeners, mainListener)
+	}
+	if cfg.Server.Main.UnixSocket.Path != "" {
+		mainSocket, err := newUnixListener(cfg.Server.Main, mainLimit, metrics)
+		if err != nil {
+			log.Errorf("Error listening for connections on %s: %v", cfg.Server.Main.UnixSocket.Path, err)
+			return
+		}
+		mainListeners = append(mainListeners, mainSocket)
+	}
+	if !cfg.Server.Admin.UnixSocket.DisableTCP {
+		adminListener, err := newListener(adminServer.Addr, cfg.Server.Admin, adminLimit, nil)
+		if err != nil {
+			log.Errorf("Error listening for TCP connections on %s: %v", adminServer.Addr, err)
+			return
+		}
+		adminListeners = append(adminListeners, adminListener)
+	}
+	if cfg.Server.Admin.UnixSocket.Path != "" {
+		adminSocket, err := newUnixListener(cfg.Server.Admin, adminLimit, nil)
+		if err != nil {
+			log.Errorf("Error listening for connections on %s: %v", cfg.Server.Admin.UnixSocket.Path, err)
+			return
+		}
+		adminListeners = append(adminListeners, adminSocket)
+	}
+	for _, listener := range mainListeners {
+		go runServer(mainServer, "Main", listener)
+	}
+	for _, listener := range adminListeners {
+		go runServer(adminServer, "Admin", listener)
+	}
+
+	// The metrics may be served on the admin port instead of a dedicated one
+	if cfg.Metrics.Prometheus.Enabled && cfg.Metrics.Prometheus.Port != 0 {
 		promRegistry := metrics.GetEngineRegistry(localprometheus.MetricsPrometheus).(*prometheus.Registry)
 
 		prometheusServer := newPrometheusServer(&cfg, promRegistry)
-		go shutdownAfterSignals(prometheusServer, stopPrometheus, done)
-		prometheusListener, err := newListener(prometheusServer.Addr, nil)
+		prometheusListener, err := newListener(prometheusServer.Addr, config.Server{KeepAlivePeriodSeconds: utils.TCP_KEEP_ALIVE_PERIOD_SECONDS}, nil, nil)
 		if err != nil {
 			log.Errorf("Error listening for TCP connections on %s: %v for prometheus server", adminServer.Addr, err)
 			return
 		}
 		go runServer(prometheusServer, "Prometheus", prometheusListener)
-
-		wait(stopSignals, done, stopMain, stopAdmin, stopPrometheus)
-	} else {
-		wait(stopSignals, done, stopMain, stopAdmin)
+		servers = append(servers, prometheusServer)
 	}
-	return
+
+	// Then block the thread until the OS sends a shutdown signal. The shutdown settings may have
+	// been reloaded since startup.
+	sig := <-stopSignals
+	log.Infof("Shutting down because of signal: %s", sig.String())
+	shutdown(reloader.Config().Shutdown, readiness, servers, &h2cRequests, backend)
 }
 
 func newAdminServer(cfg config.Configuration, handler http.Handler) *http.Server {
-	return &http.Server{
-		Addr:    ":" + strconv.Itoa(cfg.AdminPort),
-		Handler: handler,
-	}
+	return newServer(cfg.AdminPort, cfg.Server.Admin, handler)
 }
 
 func newMainServer(cfg config.Configuration, handler http.Handler) *http.Server {
+	return newServer(cfg.Port, cfg.Server.Main, handler)
+}
+
+func newServer(port int, cfg config.Server, handler http.Handler) *http.Server {
 	return &http.Server{
-		Addr:         ":" + strconv.Itoa(cfg.Port),
-		Handler:      handler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		Addr:              ":" + strconv.Itoa(port),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout(),
+		ReadTimeout:       cfg.ReadTimeout(),
+		WriteTimeout:      cfg.WriteTimeout(),
+		IdleTimeout:       cfg.IdleTimeout(),
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
 	}
 }
 
+// enableH2C lets the server accept HTTP/2 requests without TLS, if h2c is enabled in its
+// configuration. The server's read and write timeouts don't apply to HTTP/2 connections, but the
+// idle timeout does. Every request served over HTTP/2 is added to inFlight until it's done.
+func enableH2C(server *http.Server, cfg config.Server, inFlight *sync.WaitGroup) error {
+	if !cfg.H2C {
+		return nil
+	}
+	h2Server := &http2.Server{IdleTimeout: cfg.IdleTimeout()}
+	// Makes server.Shutdown send GOAWAY frames on the HTTP/2 connections
+	if err := http2.ConfigureServer(server, h2Server); err != nil {
+		return err
+	}
+	// ConfigureServer prepares a TLS configuration too, which h2c servers don't use
+	server.TLSConfig = nil
+	handler := server.Handler
+	server.Handler = h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 {
+			inFlight.Add(1)
+			defer inFlight.Done()
+		}
+		handler.ServeHTTP(w, r)
+	}), h2Server)
+	return nil
+}
+
+// enableTLS makes the server accept HTTPS connections only, if TLS is enabled in its configuration,
+// and keeps its certificate up to date with the files
+func enableTLS(server *http.Server, name string, cfg config.TLS) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	loader, err := newTLSLoader(cfg)
+	if err != nil {
+		return err
+	}
+	server.TLSConfig = loader.serverConfig()
+	go loader.watch(name)
+	return nil
+}
+
 func runServer(server *http.Server, name string, listener net.Listener) {
-	log.Infof("%s server starting on: %s", name, server.Addr)
-	err := server.Serve(listener)
+	log.Infof("%s server starting on: %s", name, listenerAddress(server, listener))
+	var err error
+	if server.TLSConfig != nil {
+		// The certificates come from server.TLSConfig
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
+	}
 	log.Errorf("%s server quit with error: %v", name, err)
 }
 
-func newListener(address string, metrics *metrics.Metrics) (net.Listener, error) {
+func newListener(address string, cfg config.Server, limit *connectionLimit, metrics *metrics.Metrics) (net.Listener, error) {
 	ln, err := net.Listen("tcp", address)
 	if err != nil {
 		return nil, fmt.Errorf("Error listening for TCP connections on %s: %v", address, err)
@@ -104,44 +209,81 @@ func newListener(address string, metrics *metrics.Metrics) (net.Listener, error)
 
 	// This cast is in Go's core libs as Server.ListenAndServe(), so it _should_ be safe, but just in case it changes in a future version...
 	if casted, ok := ln.(*net.TCPListener); ok {
-		ln = &tcpKeepAliveListener{casted}
+		ln = &tcpKeepAliveListener{casted, cfg.KeepAlivePeriod()}
 	} else {
 		log.Warning("net.Listen(\"tcp\", \"addr\") didn't return a TCPListener as it did in Go 1.9. Things will probably work fine... but this should be investigated.")
 	}
 
-	if metrics != nil {
-		ln = &monitorableListener{ln, metrics}
-	}
-
-	return ln, nil
+	return limitAndMonitor(ln, limit, metrics), nil
 }
 
-func wait(inbound <-chan os.Signal, done <-chan struct{}, outbound ...chan<- os.Signal) {
-	sig := <-inbound
+// newUnixListener listens for connections on the Unix domain socket of the server. A socket file
+// left behind by a previous process that didn't shut down cleanly is replaced, but a socket in use
+// by a running process, or any other kind of file, makes this fail.
+func newUnixListener(cfg config.Server, limit *connectionLimit, metrics *metrics.Metrics) (net.Listener, error) {
+	path := cfg.UnixSocket.Path
+	mode, err := cfg.UnixSocket.FileMode()
+	if err != nil {
+		return nil, err
+	}
 
-	for i := 0; i < len(outbound); i++ {
-		go sendSignal(outbound[i], sig)
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s already exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is already in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove the stale socket %s: %v", path, err)
+		}
 	}
 
-	for i := 0; i < len(outbound); i++ {
-		<-done
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
 	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set the permissions of %s: %v", path, err)
+	}
+
+	return limitAndMonitor(ln, limit, metrics), nil
 }
 
-func shutdownAfterSignals(server *http.Server, stopper <-chan os.Signal, done chan<- struct{}) {
-	sig := <-stopper
+// limitAndMonitor counts the connections the listener keeps open towards the limit, if any, and
+// tracks them in the metrics, if any
+func limitAndMonitor(ln net.Listener, limit *connectionLimit, metrics *metrics.Metrics) net.Listener {
+	ln = limit.limit(ln)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	if metrics != nil {
+		ln = &monitorableListener{ln, metrics}
+	}
+
+	return ln
+}
 
-	var s struct{}
-	log.Infof("Stopping %s because of signal: %s", server.Addr, sig.String())
-	if err := server.Shutdown(ctx); err != nil {
-		log.Errorf("Failed to shutdown %s: %v", server.Addr, err)
+// listenerAddress describes where the server listens: the port for TCP listeners or the socket path
+func listenerAddress(server *http.Server, listener net.Listener) string {
+	if listener.Addr().Network() == "unix" {
+		return listener.Addr().String()
 	}
-	done <- s
+	return server.Addr
 }
 
-func sendSignal(to chan<- os.Signal, sig os.Signal) {
-	to <- sig
+// withProxyProtocol wraps the listener so connections coming from trusted proxies get their
+// client address from the PROXY protocol header
+func withProxyProtocol(ln net.Listener, cfg config.ClientIP) (net.Listener, error) {
+	trusted, err := utils.NewTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("PROXY protocol headers will be accepted from %v", cfg.TrustedProxies)
+
+	return &proxyProtocolListener{
+		Listener:      ln,
+		trusted:       trusted,
+		headerTimeout: cfg.ProxyProtocol.HeaderTimeout(),
+	}, nil
 }
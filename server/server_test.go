@@ -1,11 +1,22 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
 )
 
 func TestNewAdminServer(t *testing.T) {
@@ -30,52 +41,286 @@ func TestNewMainServer(t *testing.T) {
 	}
 }
 
-func TestServerShutdown(t *testing.T) {
-	server := &http.Server{}
-	ln := &mockListener{}
+func handler(w http.ResponseWriter, req *http.Request) {
 
-	stopper := make(chan os.Signal)
-	done := make(chan struct{})
-	go shutdownAfterSignals(server, stopper, done)
-	go server.Serve(ln)
+}
 
-	stopper <- os.Interrupt
-	<-done
+func TestNewServerSettings(t *testing.T) {
+	cfg := config.Configuration{
+		Port: 8000,
+		Server: config.Servers{
+			Main: config.Server{
+				ReadHeaderTimeoutMillis: 1000,
+				ReadTimeoutMillis:       2000,
+				WriteTimeoutMillis:      3000,
+				IdleTimeoutMillis:       4000,
+				MaxHeaderBytes:          8192,
+			},
+		},
+	}
+	server := newMainServer(cfg, http.HandlerFunc(handler))
 
-	// If the test didn't hang, then we know server.Shutdown really _did_ return, and shutdownAfterSignals
-	// passed the message along as expected.
+	assert.Equal(t, time.Second, server.ReadHeaderTimeout)
+	assert.Equal(t, 2*time.Second, server.ReadTimeout)
+	assert.Equal(t, 3*time.Second, server.WriteTimeout)
+	assert.Equal(t, 4*time.Second, server.IdleTimeout)
+	assert.Equal(t, 8192, server.MaxHeaderBytes)
 }
 
-func TestWait(t *testing.T) {
-	inbound := make(chan os.Signal)
-	chan1 := make(chan os.Signal)
-	chan2 := make(chan os.Signal)
-	chan3 := make(chan os.Signal)
-	done := make(chan struct{})
+func TestH2C(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		cfg           config.Server
+		expectedProto string
+	}{
+		{
+			desc:          "h2c disabled",
+			cfg:           config.Server{},
+			expectedProto: "HTTP/1.1",
+		},
+		{
+			desc:          "h2c enabled",
+			cfg:           config.Server{H2C: true},
+			expectedProto: "HTTP/2.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+		var h2cRequests sync.WaitGroup
+		server := newServer(0, tc.cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Proto))
+		}))
+		assert.NoError(t, enableH2C(server, tc.cfg, &h2cRequests), tc.desc)
+		assert.Nil(t, server.TLSConfig, tc.desc)
+		go server.Serve(listener)
 
-	go forwardSignal(t, done, chan1)
-	go forwardSignal(t, done, chan2)
-	go forwardSignal(t, done, chan3)
+		// HTTP/1.1 clients are still served
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if assert.NoError(t, err, tc.desc) {
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			assert.Equal(t, "HTTP/1.1", string(body), tc.desc)
+		}
 
-	go func(chan os.Signal) {
-		inbound <- os.Interrupt
-	}(inbound)
+		// Clients with prior knowledge of HTTP/2
+		resp, err = newH2CClient().Get("http://" + listener.Addr().String())
+		if tc.expectedProto == "HTTP/2.0" && assert.NoError(t, err, tc.desc) {
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			assert.Equal(t, tc.expectedProto, string(body), tc.desc)
+		} else if tc.expectedProto != "HTTP/2.0" {
+			assert.Error(t, err, tc.desc)
+		}
 
-	wait(inbound, done, chan1, chan2, chan3)
-	// If this doesn't hang, then wait() is sending and receiving messages as expected.
+		server.Close()
+	}
 }
 
-func handler(w http.ResponseWriter, req *http.Request) {
+func TestListenerMaxConnections(t *testing.T) {
+	ln, err := newListener("127.0.0.1:0", config.Server{}, newConnectionLimit(1), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	first, err := net.Dial("tcp", ln.Addr().String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer first.Close()
+	second, err := net.Dial("tcp", ln.Addr().String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer second.Close()
+
+	firstAccepted := <-accepted
+	select {
+	case <-accepted:
+		assert.Fail(t, "The second connection should wait until the first one is closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	firstAccepted.Close()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(time.Second):
+		assert.Fail(t, "The second connection should be accepted once the first one is closed")
+	}
+}
+
+func TestConnectionLimitSharedByListeners(t *testing.T) {
+	limit := newConnectionLimit(1)
+	tcpListener, err := newListener("127.0.0.1:0", config.Server{}, limit, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tcpListener.Close()
+	path := filepath.Join(t.TempDir(), "prebid-cache.sock")
+	unixListener, err := newUnixListener(config.Server{UnixSocket: config.UnixSocket{Path: path, Mode: "0660"}}, limit, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer unixListener.Close()
+
+	accepted := make(chan net.Conn, 2)
+	for _, ln := range []net.Listener{tcpListener, unixListener} {
+		go func(ln net.Listener) {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				accepted <- conn
+			}
+		}(ln)
+	}
+
+	tcpConn, err := net.Dial("tcp", tcpListener.Addr().String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tcpConn.Close()
+	tcpAccepted := <-accepted
+
+	unixConn, err := net.Dial("unix", path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer unixConn.Close()
+	select {
+	case <-accepted:
+		assert.Fail(t, "The socket connection should wait until the TCP one is closed")
+	case <-time.After(50 * time.Millisecond):
+	}
 
+	tcpAccepted.Close()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(time.Second):
+		assert.Fail(t, "The socket connection should be accepted once the TCP one is closed")
+	}
+}
+
+func newH2CClient() *http.Client {
+	return &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
 }
 
-// forwardSignal is basically a working mock for shutdownAfterSignals().
-// It is used to test wait() effectively
-func forwardSignal(t *testing.T, outbound chan<- struct{}, inbound <-chan os.Signal) {
-	var s struct{}
-	sig := <-inbound
-	if sig != os.Interrupt {
-		t.Errorf("Unexpected signal: %s", sig.String())
+func TestUnixListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prebid-cache.sock")
+	cfg := config.Server{UnixSocket: config.UnixSocket{Path: path, Mode: "0620"}}
+
+	ln, err := newUnixListener(cfg, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0620), info.Mode().Perm(), "Socket file permissions")
 	}
-	outbound <- s
+
+	// Requests are served over the socket
+	server := newServer(0, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	go runServer(server, "Test", ln)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+	resp, err := client.Get("http://prebid-cache/status")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+
+	_, err = newUnixListener(cfg, nil, nil)
+	assert.EqualError(t, err, path+" is already in use by another process")
+
+	client.CloseIdleConnections()
+	server.Close()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "The socket file should be removed once the server is closed")
+}
+
+func TestUnixListenerMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prebid-cache.sock")
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			mockMetrics,
+		},
+	}
+
+	ln, err := newUnixListener(config.Server{UnixSocket: config.UnixSocket{Path: path, Mode: "0660"}}, nil, m)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer ln.Close()
+
+	client, err := net.Dial("unix", path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer client.Close()
+
+	conn, err := ln.Accept()
+	if assert.NoError(t, err) {
+		assert.NoError(t, conn.Close())
+	}
+	metricstest.AssertMetrics(t, []string{"RecordConnectionOpen", "RecordConnectionClosed"}, mockMetrics)
+}
+
+func TestUnixListenerReplacesStaleSockets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prebid-cache.sock")
+	cfg := config.Server{UnixSocket: config.UnixSocket{Path: path, Mode: "0660"}}
+
+	// A socket file left behind by a process that didn't close its listener
+	stale, err := net.Listen("unix", path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := newUnixListener(cfg, nil, nil)
+	if assert.NoError(t, err, "Stale socket") {
+		ln.Close()
+	}
+
+	regularFile := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, ioutil.WriteFile(regularFile, nil, 0600))
+	cfg.UnixSocket.Path = regularFile
+	_, err = newUnixListener(cfg, nil, nil)
+	assert.EqualError(t, err, regularFile+" already exists and is not a socket", "Regular file")
 }
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/endpoints"
+)
+
+// shutdown stops Prebid Cache gracefully. "/status" starts reporting that the server is not ready
+// and requests keep being served for the pre-stop delay, so load balancers can take this instance
+// out of rotation. Then the servers stop accepting connections and wait for the in-flight requests
+// to finish, including the ones in h2cRequests. PutHandler waits for its backend writes before
+// responding, so those are drained as well. Finally, the connections to the storage service are closed.
+func shutdown(cfg config.Shutdown, readiness *endpoints.Readiness, servers []*http.Server, h2cRequests *sync.WaitGroup, backend backends.Backend) {
+	readiness.SetShuttingDown()
+	for _, server := range servers {
+		// Clients reconnect for their next request, which load balancers send elsewhere
+		server.SetKeepAlivesEnabled(false)
+	}
+
+	if delay := cfg.PreStopDelay(); delay > 0 {
+		log.Infof("Reporting not ready on /status for %v before stopping the servers", delay)
+		time.Sleep(delay)
+	}
+
+	drainServers(servers, h2cRequests, cfg.DrainTimeout())
+	closeBackend(backend, cfg.CloseTimeout())
+}
+
+// drainServers shuts every server down at the same time and waits until all of them, and the
+// requests served over h2c, are done or the timeout expires
+func drainServers(servers []*http.Server, h2cRequests *sync.WaitGroup, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(len(servers))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			defer waitGroup.Done()
+			log.Infof("Stopping %s", server.Addr)
+			if err := server.Shutdown(ctx); err != nil {
+				log.Errorf("Failed to shutdown %s: %v", server.Addr, err)
+			}
+		}(server)
+	}
+	waitGroup.Wait()
+
+	h2cDone := make(chan struct{})
+	go func() {
+		h2cRequests.Wait()
+		close(h2cDone)
+	}()
+	select {
+	case <-h2cDone:
+	case <-ctx.Done():
+		log.Errorf("Requests received over h2c were still in progress after %v", timeout)
+	}
+}
+
+// closeBackend closes the connections of the backend, if it keeps any, without waiting longer than timeout
+func closeBackend(backend backends.Backend, timeout time.Duration) {
+	closer, ok := backend.(io.Closer)
+	if !ok {
+		return
+	}
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- closer.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			log.Errorf("Failed to close the backend connections: %v", err)
+			return
+		}
+		log.Info("Backend connections closed")
+	case <-time.After(timeout):
+		log.Errorf("Backend connections were not closed after %v", timeout)
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/endpoints"
+	"github.com/sirupsen/logrus"
+	testLogrus "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	baseURL := "http://" + listener.Addr().String()
+
+	events := make(chan string, 10)
+	putStarted := make(chan struct{})
+	releasePut := make(chan struct{})
+
+	readiness := &endpoints.Readiness{}
+	router := httprouter.New()
+	router.GET("/status", endpoints.NewStatusHandler(readiness))
+	router.POST("/cache", func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		// Stands for a put waiting for its backend writes
+		close(putStarted)
+		<-releasePut
+		events <- "put finished"
+	})
+	server := &http.Server{Handler: router}
+	go server.Serve(listener)
+
+	putStatus := make(chan int, 1)
+	go func() {
+		resp, err := http.Post(baseURL+"/cache", "application/json", nil)
+		if err != nil {
+			putStatus <- 0
+			return
+		}
+		resp.Body.Close()
+		putStatus <- resp.StatusCode
+	}()
+	<-putStarted
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		cfg := config.Shutdown{PreStopDelayMillis: 200, DrainTimeoutMillis: 5000, CloseTimeoutMillis: 1000}
+		shutdown(cfg, readiness, []*http.Server{server}, &sync.WaitGroup{}, &fakeClosingBackend{events: events})
+		close(shutdownDone)
+	}()
+
+	// During the pre-stop delay, the server keeps serving requests and reports it's not ready
+	assert.Eventually(t, func() bool { return !readiness.Ready() }, time.Second, time.Millisecond)
+	resp, err := http.Get(baseURL + "/status")
+	if assert.NoError(t, err, "Requests should be served during the pre-stop delay") {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	// The in-flight put finishes before the backend connections are closed
+	close(releasePut)
+	assert.Equal(t, http.StatusOK, <-putStatus)
+	<-shutdownDone
+	close(events)
+
+	var order []string
+	for event := range events {
+		order = append(order, event)
+	}
+	assert.Equal(t, []string{"put finished", "backend closed"}, order)
+
+	_, err = http.Get(baseURL + "/status")
+	assert.Error(t, err, "The server should not accept connections after shutting down")
+}
+
+func TestDrainServersWaitsForH2CRequests(t *testing.T) {
+	hook := testLogrus.NewGlobal()
+
+	var h2cRequests sync.WaitGroup
+	h2cRequests.Add(1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		h2cRequests.Done()
+	}()
+	drainServers(nil, &h2cRequests, time.Second)
+	assert.Empty(t, hook.Entries, "Requests finishing before the timeout")
+
+	h2cRequests.Add(1)
+	drainServers(nil, &h2cRequests, 10*time.Millisecond)
+	if assert.Len(t, hook.Entries, 1, "Requests still in progress after the timeout") {
+		assert.Equal(t, "Requests received over h2c were still in progress after 10ms", hook.LastEntry().Message)
+	}
+	h2cRequests.Done()
+}
+
+func TestCloseBackend(t *testing.T) {
+	hook := testLogrus.NewGlobal()
+
+	testCases := []struct {
+		desc             string
+		backend          backends.Backend
+		expectedLogLevel logrus.Level
+		expectedLogMsg   string
+	}{
+		{
+			desc:    "Backend without connections",
+			backend: backends.NewMemoryBackend(),
+		},
+		{
+			desc:             "Backend connections closed",
+			backend:          &fakeClosingBackend{},
+			expectedLogLevel: logrus.InfoLevel,
+			expectedLogMsg:   "Backend connections closed",
+		},
+		{
+			desc:             "Backend fails to close its connections",
+			backend:          &fakeClosingBackend{err: errors.New("connection reset")},
+			expectedLogLevel: logrus.ErrorLevel,
+			expectedLogMsg:   "Failed to close the backend connections: connection reset",
+		},
+		{
+			desc:             "Backend takes longer than the timeout to close its connections",
+			backend:          &fakeClosingBackend{delay: time.Second},
+			expectedLogLevel: logrus.ErrorLevel,
+			expectedLogMsg:   "Backend connections were not closed after 10ms",
+		},
+	}
+
+	for _, tc := range testCases {
+		closeBackend(tc.backend, 10*time.Millisecond)
+
+		if tc.expectedLogMsg == "" {
+			assert.Empty(t, hook.Entries, tc.desc)
+		} else if assert.Len(t, hook.Entries, 1, tc.desc) {
+			assert.Equal(t, tc.expectedLogLevel, hook.LastEntry().Level, tc.desc)
+			assert.Equal(t, tc.expectedLogMsg, hook.LastEntry().Message, tc.desc)
+		}
+		hook.Reset()
+	}
+}
+
+type fakeClosingBackend struct {
+	events chan<- string
+	delay  time.Duration
+	err    error
+}
+
+func (b *fakeClosingBackend) Get(ctx context.Context, key string) (string, error) {
+	return "", nil
+}
+
+func (b *fakeClosingBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	return nil
+}
+
+func (b *fakeClosingBackend) Close() error {
+	time.Sleep(b.delay)
+	if b.events != nil {
+		b.events <- "backend closed"
+	}
+	return b.err
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/prebid/prebid-cache/config"
+)
+
+// tlsLoader builds the TLS configuration of a server from its certificate, key and client CA
+// files. Every handshake uses the latest configuration loaded, so renewed certificates are picked
+// up without restarting the server.
+type tlsLoader struct {
+	cfg config.TLS
+
+	// current holds the *tls.Config used for new handshakes
+	current atomic.Value
+
+	// modTimes are the modification times of the files when they were last loaded. They're only
+	// accessed by reloadIfChanged, which mutex serializes.
+	mutex    sync.Mutex
+	modTimes []time.Time
+}
+
+func newTLSLoader(cfg config.TLS) (*tlsLoader, error) {
+	loader := &tlsLoader{cfg: cfg}
+	if err := loader.load(); err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+// serverConfig returns the TLS configuration to set on the http.Server
+func (l *tlsLoader) serverConfig() *tls.Config {
+	serverCfg := l.current.Load().(*tls.Config).Clone()
+	serverCfg.GetConfigForClient = l.getConfigForClient
+	return serverCfg
+}
+
+func (l *tlsLoader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return l.current.Load().(*tls.Config), nil
+}
+
+// watch reloads the files whenever they change until the process exits
+func (l *tlsLoader) watch(name string) {
+	if l.cfg.ReloadInterval() <= 0 {
+		return
+	}
+	for range time.Tick(l.cfg.ReloadInterval()) {
+		l.reloadIfChanged(name)
+	}
+}
+
+// reloadIfChanged loads the files again if any of their modification times changed. If they can't
+// be loaded, for example because the certificate was replaced before its key, the previous
+// configuration is kept and the next check tries again.
+func (l *tlsLoader) reloadIfChanged(name string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	modTimes, err := l.fileModTimes()
+	if err != nil {
+		log.Errorf("%s server TLS files could not be checked for changes: %v", name, err)
+		return
+	}
+	if equalTimes(modTimes, l.modTimes) {
+		return
+	}
+	if err := l.load(); err != nil {
+		log.Errorf("%s server TLS files could not be reloaded, the previous certificate stays in use: %v", name, err)
+		return
+	}
+	log.Infof("%s server TLS certificate reloaded from %s", name, l.cfg.CertFile)
+}
+
+// load reads the files and makes the resulting configuration current
+func (l *tlsLoader) load() error {
+	modTimes, err := l.fileModTimes()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(l.cfg.CertFile, l.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the certificate: %v", err)
+	}
+
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   l.cfg.Version(),
+		CipherSuites: l.cfg.CipherSuiteIDs(),
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if l.cfg.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(l.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the client CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in the client CA file %s", l.cfg.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	l.current.Store(tlsCfg)
+	l.modTimes = modTimes
+	return nil
+}
+
+func (l *tlsLoader) fileModTimes() ([]time.Time, error) {
+	files := []string{l.cfg.CertFile, l.cfg.KeyFile}
+	if l.cfg.ClientCAFile != "" {
+		files = append(files, l.cfg.ClientCAFile)
+	}
+
+	modTimes := make([]time.Time, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSServer(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	writeServerCertificate(t, dir, ca, "first")
+
+	baseURL := startTLSServer(t, config.TLS{
+		Enabled:    true,
+		CertFile:   filepath.Join(dir, "server.crt"),
+		KeyFile:    filepath.Join(dir, "server.key"),
+		MinVersion: "1.2",
+	})
+
+	client := newTLSClient(ca, nil, 0)
+	resp, err := client.Get(baseURL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "HTTP/2.0", resp.Proto, "HTTP/2 should be negotiated")
+	}
+
+	_, err = newTLSClient(ca, nil, tls.VersionTLS11).Get(baseURL)
+	assert.Error(t, err, "TLS versions older than min_version should be rejected")
+
+	resp, err = http.Get("http" + baseURL[len("https"):])
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Plain HTTP requests should be rejected")
+	}
+}
+
+func TestTLSServerClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	writeServerCertificate(t, dir, ca, "first")
+	writePEM(t, filepath.Join(dir, "ca.crt"), "CERTIFICATE", ca.cert.Raw)
+
+	baseURL := startTLSServer(t, config.TLS{
+		Enabled:      true,
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		MinVersion:   "1.2",
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	})
+
+	_, err := newTLSClient(ca, nil, 0).Get(baseURL)
+	assert.Error(t, err, "Clients without a certificate should be rejected")
+
+	untrustedCA := newTestCA(t)
+	untrustedCert := untrustedCA.issue(t, "prebid-server", x509.ExtKeyUsageClientAuth)
+	_, err = newTLSClient(ca, &untrustedCert, 0).Get(baseURL)
+	assert.Error(t, err, "Clients with a certificate from an unknown authority should be rejected")
+
+	clientCert := ca.issue(t, "prebid-server", x509.ExtKeyUsageClientAuth)
+	resp, err := newTLSClient(ca, &clientCert, 0).Get(baseURL)
+	if assert.NoError(t, err, "Clients with a trusted certificate should be accepted") {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+}
+
+func TestTLSLoaderReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	writeServerCertificate(t, dir, ca, "first")
+
+	loader, err := newTLSLoader(config.TLS{
+		Enabled:    true,
+		CertFile:   filepath.Join(dir, "server.crt"),
+		KeyFile:    filepath.Join(dir, "server.key"),
+		MinVersion: "1.2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "first", currentCertificateName(t, loader))
+
+	// Unchanged files are not loaded again
+	loader.reloadIfChanged("Main")
+	assert.Equal(t, "first", currentCertificateName(t, loader))
+
+	// A renewed certificate is picked up
+	writeServerCertificate(t, dir, ca, "second")
+	touchFiles(t, time.Now().Add(time.Minute), filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	loader.reloadIfChanged("Main")
+	assert.Equal(t, "second", currentCertificateName(t, loader))
+
+	// A certificate that doesn't match its key is ignored
+	writePEM(t, filepath.Join(dir, "server.crt"), "CERTIFICATE", ca.issue(t, "third", x509.ExtKeyUsageServerAuth).Certificate[0])
+	touchFiles(t, time.Now().Add(2*time.Minute), filepath.Join(dir, "server.crt"))
+	loader.reloadIfChanged("Main")
+	assert.Equal(t, "second", currentCertificateName(t, loader))
+}
+
+func TestNewTLSLoaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	writeServerCertificate(t, dir, ca, "first")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "empty.crt"), []byte("not a certificate"), 0600))
+
+	testCases := []struct {
+		desc string
+		cfg  config.TLS
+	}{
+		{
+			desc: "Missing certificate file",
+			cfg:  config.TLS{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: filepath.Join(dir, "server.key"), MinVersion: "1.2"},
+		},
+		{
+			desc: "Key that doesn't match the certificate",
+			cfg:  config.TLS{CertFile: filepath.Join(dir, "server.crt"), KeyFile: filepath.Join(dir, "empty.crt"), MinVersion: "1.2"},
+		},
+		{
+			desc: "Client CA file without certificates",
+			cfg:  config.TLS{CertFile: filepath.Join(dir, "server.crt"), KeyFile: filepath.Join(dir, "server.key"), MinVersion: "1.2", ClientCAFile: filepath.Join(dir, "empty.crt")},
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := newTLSLoader(tc.cfg)
+		assert.Error(t, err, tc.desc)
+	}
+}
+
+// startTLSServer serves "/" with TLS on a random local port and returns its base URL
+func startTLSServer(t *testing.T, cfg config.TLS) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &http.Server{
+		Addr: listener.Addr().String(),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+	require.NoError(t, enableTLS(server, "Test", cfg))
+	go runServer(server, "Test", listener)
+	t.Cleanup(func() { server.Close() })
+
+	return "https://" + listener.Addr().String()
+}
+
+func newTLSClient(ca *testCA, cert *tls.Certificate, maxVersion uint16) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	tlsCfg := &tls.Config{RootCAs: roots, MaxVersion: maxVersion}
+	if cert != nil {
+		tlsCfg.Certificates = []tls.Certificate{*cert}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg, ForceAttemptHTTP2: true}}
+}
+
+func currentCertificateName(t *testing.T, loader *tlsLoader) string {
+	t.Helper()
+	tlsCfg, err := loader.serverConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(tlsCfg.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+// writeServerCertificate writes server.crt and server.key, for 127.0.0.1, in dir
+func writeServerCertificate(t *testing.T, dir string, ca *testCA, name string) {
+	t.Helper()
+	cert := ca.issue(t, name, x509.ExtKeyUsageServerAuth)
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "server.crt"), "CERTIFICATE", cert.Certificate[0])
+	writePEM(t, filepath.Join(dir, "server.key"), "PRIVATE KEY", key)
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+}
+
+// touchFiles sets the modification time of the files, since rewriting them quickly may not change it
+func touchFiles(t *testing.T, modTime time.Time, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
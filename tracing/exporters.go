@@ -0,0 +1,280 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/version"
+)
+
+// Exporter sends ended spans somewhere they can be looked at. The tracer calls ExportSpans from
+// a single goroutine.
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []*Span) error
+	// Shutdown releases the resources held by the exporter once the last spans were exported
+	Shutdown(ctx context.Context) error
+}
+
+// NewExporter creates the exporter selected in cfg
+func NewExporter(cfg config.Tracing) (Exporter, error) {
+	switch cfg.Exporter.Type {
+	case config.TracingExporterStdout:
+		return NewJSONExporter(os.Stdout, cfg.ServiceName), nil
+	case config.TracingExporterFile:
+		file, err := os.OpenFile(cfg.Exporter.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the tracing file: %v", err)
+		}
+		return NewJSONExporter(file, cfg.ServiceName), nil
+	case config.TracingExporterOTLPHTTP:
+		return NewOTLPExporter(cfg.Exporter.OTLP, cfg.ServiceName), nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", cfg.Exporter.Type)
+	}
+}
+
+// jsonExporter writes a JSON line per span
+type jsonExporter struct {
+	mu          sync.Mutex
+	out         io.Writer
+	serviceName string
+}
+
+// NewJSONExporter writes a JSON line per span to out. If out is also an io.Closer other than
+// the standard output, it's closed on shutdown.
+func NewJSONExporter(out io.Writer, serviceName string) Exporter {
+	return &jsonExporter{out: out, serviceName: serviceName}
+}
+
+type jsonSpan struct {
+	Service      string                 `json:"service"`
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Name         string                 `json:"name"`
+	Kind         string                 `json:"kind"`
+	Start        time.Time              `json:"start"`
+	DurationMs   float64                `json:"duration_ms"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+func (e *jsonExporter) ExportSpans(ctx context.Context, spans []*Span) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, span := range spans {
+		line := jsonSpan{
+			Service:    e.serviceName,
+			TraceID:    span.SpanContext.TraceID.String(),
+			SpanID:     span.SpanContext.SpanID.String(),
+			Name:       span.Name,
+			Kind:       span.Kind.String(),
+			Start:      span.StartTime.UTC(),
+			DurationMs: float64(span.EndTime.Sub(span.StartTime).Microseconds()) / 1000,
+			Attributes: span.Attributes,
+			Error:      span.Err,
+		}
+		if span.ParentSpanID.isValid() {
+			line.ParentSpanID = span.ParentSpanID.String()
+		}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.out.Write(buf.Bytes())
+	return err
+}
+
+func (e *jsonExporter) Shutdown(ctx context.Context) error {
+	if closer, ok := e.out.(io.Closer); ok && e.out != os.Stdout {
+		return closer.Close()
+	}
+	return nil
+}
+
+// otlpExporter posts the spans to an OpenTelemetry collector, encoded in the JSON variant of
+// the OTLP/HTTP protocol
+type otlpExporter struct {
+	client        *http.Client
+	endpoint      string
+	authorization string
+	serviceName   string
+}
+
+// NewOTLPExporter sends the spans to the OTLP/HTTP endpoint in cfg
+func NewOTLPExporter(cfg config.OTLPTraceExporter, serviceName string) Exporter {
+	return &otlpExporter{
+		client:        &http.Client{Timeout: cfg.Timeout()},
+		endpoint:      cfg.Endpoint,
+		authorization: cfg.Authorization,
+		serviceName:   serviceName,
+	}
+}
+
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []*Span) error {
+	body, err := json.Marshal(e.newRequest(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if e.authorization != "" {
+		req.Header.Set("Authorization", e.authorization)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector responded with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	// Read the rest of the response so the connection can be reused
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// The types below follow the JSON encoding of the OTLP ExportTraceServiceRequest message, where
+// IDs are hex strings and 64-bit integers are decimal strings
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	TraceState        string         `json:"traceState,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpStatusError is the STATUS_CODE_ERROR value. Spans without an error keep STATUS_CODE_UNSET.
+const otlpStatusError = 2
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func (e *otlpExporter) newRequest(spans []*Span) otlpRequest {
+	resourceAttributes := []otlpKeyValue{newOTLPKeyValue("service.name", e.serviceName)}
+	if version.Ver != "" {
+		resourceAttributes = append(resourceAttributes, newOTLPKeyValue("service.version", version.Ver))
+	}
+
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			TraceState:        span.SpanContext.TraceState,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+		}
+		if span.ParentSpanID.isValid() {
+			s.ParentSpanID = span.ParentSpanID.String()
+		}
+		for key, value := range span.Attributes {
+			s.Attributes = append(s.Attributes, newOTLPKeyValue(key, value))
+		}
+		if span.Err != "" {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: span.Err}
+		}
+		otlpSpans = append(otlpSpans, s)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: resourceAttributes},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/prebid/prebid-cache/tracing", Version: version.Ver},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+}
+
+func newOTLPKeyValue(key string, value interface{}) otlpKeyValue {
+	var v otlpAnyValue
+	switch value := value.(type) {
+	case string:
+		v.StringValue = &value
+	case bool:
+		v.BoolValue = &value
+	case int:
+		s := strconv.Itoa(value)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(value, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &value
+	default:
+		s := fmt.Sprint(value)
+		v.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
@@ -0,0 +1,206 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSpans() []*Span {
+	start := time.Date(2022, 5, 4, 10, 11, 12, 0, time.UTC)
+	traceID := TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	return []*Span{
+		{
+			Name:         "POST /cache",
+			Kind:         SpanKindServer,
+			SpanContext:  SpanContext{TraceID: traceID, SpanID: SpanID{1, 2, 3, 4, 5, 6, 7, 8}, Sampled: true, TraceState: "vendor=value"},
+			ParentSpanID: SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			StartTime:    start,
+			EndTime:      start.Add(1500 * time.Microsecond),
+			Attributes:   map[string]interface{}{"http.status_code": 500},
+			Err:          "Internal Server Error",
+		},
+		{
+			Name:        "backend.put",
+			Kind:        SpanKindClient,
+			SpanContext: SpanContext{TraceID: traceID, SpanID: SpanID{8, 7, 6, 5, 4, 3, 2, 1}, Sampled: true},
+			StartTime:   start,
+			EndTime:     start.Add(time.Millisecond),
+			Attributes:  map[string]interface{}{"db.system": "redis", "cache.record_exists": true, "ratio": 0.5},
+		},
+	}
+}
+
+func TestJSONExporter(t *testing.T) {
+	var out bytes.Buffer
+	exporter := NewJSONExporter(&out, "prebid-cache")
+
+	assert.NoError(t, exporter.ExportSpans(context.Background(), newTestSpans()))
+	assert.NoError(t, exporter.Shutdown(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+	assert.JSONEq(t, `{
+		"service": "prebid-cache",
+		"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id": "0102030405060708",
+		"parent_span_id": "00f067aa0ba902b7",
+		"name": "POST /cache",
+		"kind": "server",
+		"start": "2022-05-04T10:11:12Z",
+		"duration_ms": 1.5,
+		"attributes": {"http.status_code": 500},
+		"error": "Internal Server Error"
+	}`, lines[0])
+	assert.JSONEq(t, `{
+		"service": "prebid-cache",
+		"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id": "0807060504030201",
+		"name": "backend.put",
+		"kind": "client",
+		"start": "2022-05-04T10:11:12Z",
+		"duration_ms": 1,
+		"attributes": {"db.system": "redis", "cache.record_exists": true, "ratio": 0.5}
+	}`, lines[1])
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	cfg := config.Tracing{
+		ServiceName: "prebid-cache",
+		Exporter:    config.TracingExporter{Type: config.TracingExporterFile, FilePath: path},
+	}
+
+	// Spans are appended to the file across restarts
+	for i := 0; i < 2; i++ {
+		exporter, err := NewExporter(cfg)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, exporter.ExportSpans(context.Background(), newTestSpans()[:1]))
+		assert.NoError(t, exporter.Shutdown(context.Background()))
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(contents), `"name":"POST /cache"`))
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var receivedPath, receivedContentType, receivedAuthorization string
+	var received map[string]interface{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		receivedContentType = r.Header.Get("Content-Type")
+		receivedAuthorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer collector.Close()
+
+	exporter, err := NewExporter(config.Tracing{
+		ServiceName: "prebid-cache",
+		Exporter: config.TracingExporter{
+			Type: config.TracingExporterOTLPHTTP,
+			OTLP: config.OTLPTraceExporter{Endpoint: collector.URL + "/v1/traces", Authorization: "Bearer token", TimeoutMillis: 1000},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, exporter.ExportSpans(context.Background(), newTestSpans()))
+	assert.NoError(t, exporter.Shutdown(context.Background()))
+
+	assert.Equal(t, "/v1/traces", receivedPath)
+	assert.Equal(t, "application/json", receivedContentType)
+	assert.Equal(t, "Bearer token", receivedAuthorization)
+
+	resourceSpans := received["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "prebid-cache"}},
+	}, resourceSpans["resource"].(map[string]interface{})["attributes"])
+
+	scopeSpans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})
+	spans := scopeSpans["spans"].([]interface{})
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	assert.Equal(t, map[string]interface{}{
+		"traceId":           "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":            "0102030405060708",
+		"parentSpanId":      "00f067aa0ba902b7",
+		"traceState":        "vendor=value",
+		"name":              "POST /cache",
+		"kind":              float64(2),
+		"startTimeUnixNano": "1651659072000000000",
+		"endTimeUnixNano":   "1651659072001500000",
+		"attributes": []interface{}{
+			map[string]interface{}{"key": "http.status_code", "value": map[string]interface{}{"intValue": "500"}},
+		},
+		"status": map[string]interface{}{"code": float64(2), "message": "Internal Server Error"},
+	}, spans[0])
+
+	backendSpan := spans[1].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"code": float64(0)}, backendSpan["status"])
+	assert.NotContains(t, backendSpan, "parentSpanId")
+	assert.ElementsMatch(t, []interface{}{
+		map[string]interface{}{"key": "db.system", "value": map[string]interface{}{"stringValue": "redis"}},
+		map[string]interface{}{"key": "cache.record_exists", "value": map[string]interface{}{"boolValue": true}},
+		map[string]interface{}{"key": "ratio", "value": map[string]interface{}{"doubleValue": 0.5}},
+	}, backendSpan["attributes"])
+}
+
+func TestOTLPExporterErrors(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(config.OTLPTraceExporter{Endpoint: collector.URL, TimeoutMillis: 1000}, "prebid-cache")
+
+	err := exporter.ExportSpans(context.Background(), newTestSpans())
+
+	assert.EqualError(t, err, "collector responded with status 429: quota exceeded")
+}
+
+func TestTracerExportsToCollector(t *testing.T) {
+	received := make(chan int, 10)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request otlpRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		received <- len(request.ResourceSpans[0].ScopeSpans[0].Spans)
+	}))
+	defer collector.Close()
+
+	cfg := newTestTracingConfig()
+	cfg.Exporter = config.TracingExporter{
+		Type: config.TracingExporterOTLPHTTP,
+		OTLP: config.OTLPTraceExporter{Endpoint: collector.URL + "/v1/traces", TimeoutMillis: 1000},
+	}
+	tracer, err := NewTracer(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, span := tracer.StartRequestSpan(context.Background(), "GET /cache", SpanContext{})
+	_, child := StartSpan(ctx, "backend.get", SpanKindClient)
+	child.End()
+	span.End()
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	assert.Equal(t, 2, <-received)
+}
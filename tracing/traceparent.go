@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader and TracestateHeader carry the trace context between services, as defined
+// by https://www.w3.org/TR/trace-context/
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// maxTracestateLength is the longest tracestate header that gets propagated. Longer values are
+// dropped, as the specification allows.
+const maxTracestateLength = 512
+
+// TraceID identifies a trace across every service it goes through
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id TraceID) isValid() bool {
+	return id != TraceID{}
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanID) isValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext is the part of a span that is propagated to other services
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string
+}
+
+// IsValid tells whether the span context identifies a span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.isValid() && sc.SpanID.isValid()
+}
+
+// Traceparent formats the span context as a version 00 traceparent header
+func (sc SpanContext) Traceparent() string {
+	flags := 0
+	if sc.Sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent reads the span context sent by a caller in the traceparent and tracestate
+// headers. It returns false if the traceparent is missing or malformed, in which case the
+// tracestate is ignored too.
+func ParseTraceparent(traceparent, tracestate string) (SpanContext, bool) {
+	// version "-" trace-id "-" parent-id "-" trace-flags. Versions after 00 may append fields,
+	// which are ignored.
+	const length = 55
+	if len(traceparent) < length {
+		return SpanContext{}, false
+	}
+	version := traceparent[0:2]
+	if !isLowerHex(version) || version == "ff" {
+		return SpanContext{}, false
+	}
+	if len(traceparent) > length && (version == "00" || traceparent[length] != '-') {
+		return SpanContext{}, false
+	}
+	if traceparent[2] != '-' || traceparent[35] != '-' || traceparent[52] != '-' {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	traceID, parentID, flags := traceparent[3:35], traceparent[36:52], traceparent[53:55]
+	if !isLowerHex(traceID) || !isLowerHex(parentID) || !isLowerHex(flags) {
+		return SpanContext{}, false
+	}
+	hex.Decode(sc.TraceID[:], []byte(traceID))
+	hex.Decode(sc.SpanID[:], []byte(parentID))
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+
+	var flagBits [1]byte
+	hex.Decode(flagBits[:], []byte(flags))
+	sc.Sampled = flagBits[0]&1 == 1
+
+	if tracestate = strings.TrimSpace(tracestate); len(tracestate) <= maxTracestateLength {
+		sc.TraceState = tracestate
+	}
+	return sc, true
+}
+
+// InjectHeaders sets the traceparent and tracestate headers of an outgoing request, so the
+// service it calls joins the trace of the span ctx carries. Nothing is set without a span.
+func InjectHeaders(ctx context.Context, header http.Header) {
+	span := SpanFromContext(ctx)
+	if span == nil || !span.SpanContext.IsValid() {
+		return
+	}
+	header.Set(TraceparentHeader, span.SpanContext.Traceparent())
+	if span.SpanContext.TraceState != "" {
+		header.Set(TracestateHeader, span.SpanContext.TraceState)
+	}
+}
+
+// isLowerHex tells whether s only holds lowercase hexadecimal digits, which is all the
+// traceparent header allows
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	traceID := TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID := SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+
+	testCases := []struct {
+		desc          string
+		inTraceparent string
+		inTracestate  string
+		expectedOK    bool
+		expected      SpanContext
+	}{
+		{
+			desc:          "Sampled version 00 traceparent with a tracestate",
+			inTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			inTracestate:  "vendor=value",
+			expectedOK:    true,
+			expected:      SpanContext{TraceID: traceID, SpanID: spanID, Sampled: true, TraceState: "vendor=value"},
+		},
+		{
+			desc:          "Not sampled traceparent",
+			inTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			expectedOK:    true,
+			expected:      SpanContext{TraceID: traceID, SpanID: spanID},
+		},
+		{
+			desc:          "Future version with additional fields, which are ignored",
+			inTraceparent: "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-09-extra",
+			expectedOK:    true,
+			expected:      SpanContext{TraceID: traceID, SpanID: spanID, Sampled: true},
+		},
+		{
+			desc:          "Tracestate too long to be propagated",
+			inTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			inTracestate:  strings.Repeat("a", maxTracestateLength+1),
+			expectedOK:    true,
+			expected:      SpanContext{TraceID: traceID, SpanID: spanID, Sampled: true},
+		},
+		{
+			desc:          "Missing traceparent",
+			inTraceparent: "",
+		},
+		{
+			desc:          "Version 00 with additional fields",
+			inTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		},
+		{
+			desc:          "Forbidden version ff",
+			inTraceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			desc:          "Uppercase hexadecimal digits",
+			inTraceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		},
+		{
+			desc:          "All zero trace ID",
+			inTraceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		},
+		{
+			desc:          "All zero parent ID",
+			inTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		},
+		{
+			desc:          "Wrong separators",
+			inTraceparent: "00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",
+		},
+	}
+
+	for _, tc := range testCases {
+		sc, ok := ParseTraceparent(tc.inTraceparent, tc.inTracestate)
+
+		assert.Equal(t, tc.expectedOK, ok, tc.desc)
+		assert.Equal(t, tc.expected, sc, tc.desc)
+	}
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, ok := ParseTraceparent(header, "")
+
+	assert.True(t, ok)
+	assert.Equal(t, header, sc.Traceparent())
+}
+
+func TestInjectHeaders(t *testing.T) {
+	sc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=value")
+	span := &Span{SpanContext: sc}
+
+	header := http.Header{}
+	InjectHeaders(ContextWithSpan(context.Background(), span), header)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", header.Get(TraceparentHeader))
+	assert.Equal(t, "vendor=value", header.Get(TracestateHeader))
+
+	header = http.Header{}
+	InjectHeaders(context.Background(), header)
+	assert.Empty(t, header, "Nothing is propagated without a span")
+}
@@ -0,0 +1,287 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/prebid/prebid-cache/config"
+)
+
+// SpanKind tells the role of a span in a trace, with the values used by OpenTelemetry
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// Span times an operation. Spans are not safe for concurrent use: each one must be modified
+// and ended by the goroutine that started it. The methods of a nil span do nothing, so code
+// that runs with tracing disabled doesn't need to check.
+type Span struct {
+	Name         string
+	Kind         SpanKind
+	SpanContext  SpanContext
+	ParentSpanID SpanID
+	StartTime    time.Time
+	EndTime      time.Time
+	// Attributes hold string, bool, int, int64 or float64 values
+	Attributes map[string]interface{}
+	// Err is the message of the error that made the operation fail, if any
+	Err string
+
+	tracer *Tracer
+	ended  bool
+}
+
+// SetAttribute adds a key-value pair that describes the operation
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]interface{})
+	}
+	s.Attributes[key] = value
+}
+
+// SetError marks the operation as failed
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.Err = err.Error()
+}
+
+// End records the duration of the span and queues it for export, if it was sampled
+func (s *Span) End() {
+	if s == nil || s.ended {
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	if s.SpanContext.Sampled {
+		s.tracer.enqueue(s)
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx that carries span, so the spans started from it are its children
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span ctx carries, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// StartSpan starts a child of the span ctx carries. Without a span in ctx, which is the case
+// when tracing is disabled, nothing is recorded and a nil span is returned.
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	span := parent.tracer.newSpan(name, kind, parent.SpanContext)
+	span.ParentSpanID = parent.SpanContext.SpanID
+	return ContextWithSpan(ctx, span), span
+}
+
+// Tracer creates the spans of the requests Prebid Cache serves and exports them in batches, in
+// the background. The methods of a nil tracer do nothing.
+type Tracer struct {
+	exporter      Exporter
+	sampleRatio   float64
+	batchSize     int
+	flushInterval time.Duration
+
+	queue   chan *Span
+	dropped int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewTracer creates the tracer and the exporter described by cfg. It returns a nil tracer if
+// tracing is disabled.
+func NewTracer(cfg config.Tracing) (*Tracer, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	exporter, err := NewExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewTracerWithExporter(cfg, exporter), nil
+}
+
+// NewTracerWithExporter creates a tracer that sends its spans to exporter instead of the one
+// selected in cfg
+func NewTracerWithExporter(cfg config.Tracing, exporter Exporter) *Tracer {
+	t := &Tracer{
+		exporter:      exporter,
+		sampleRatio:   cfg.SampleRatio,
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval(),
+		queue:         make(chan *Span, cfg.QueueSize),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// StartRequestSpan starts the span of an incoming request. If the caller sent a valid span
+// context, the span joins its trace and follows its sampling decision. Otherwise, a new trace
+// is started and sampled according to the configured ratio.
+func (t *Tracer) StartRequestSpan(ctx context.Context, name string, remote SpanContext) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	if !remote.IsValid() {
+		remote = SpanContext{TraceID: newTraceID()}
+		remote.Sampled = t.sample(remote.TraceID)
+	}
+	span := t.newSpan(name, SpanKindServer, remote)
+	span.ParentSpanID = remote.SpanID
+	return ContextWithSpan(ctx, span), span
+}
+
+// Shutdown exports the spans still queued and releases the exporter. Spans ended afterwards are dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.stopOnce.Do(func() { close(t.stop) })
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+func (t *Tracer) newSpan(name string, kind SpanKind, parent SpanContext) *Span {
+	return &Span{
+		Name: name,
+		Kind: kind,
+		SpanContext: SpanContext{
+			TraceID:    parent.TraceID,
+			SpanID:     newSpanID(),
+			Sampled:    parent.Sampled,
+			TraceState: parent.TraceState,
+		},
+		StartTime: time.Now(),
+		tracer:    t,
+	}
+}
+
+// sample keeps the share of traces given by the sample ratio, deciding on the trace ID alone so
+// every service using the same ratio agrees
+func (t *Tracer) sample(traceID TraceID) bool {
+	if t.sampleRatio >= 1 {
+		return true
+	}
+	bound := uint64(t.sampleRatio * (1 << 63))
+	return binary.BigEndian.Uint64(traceID[8:])>>1 < bound
+}
+
+// enqueue adds an ended span to the export queue, without blocking the request it belongs to
+func (t *Tracer) enqueue(span *Span) {
+	select {
+	case <-t.stop:
+		atomic.AddInt64(&t.dropped, 1)
+		return
+	default:
+	}
+	select {
+	case t.queue <- span:
+	default:
+		atomic.AddInt64(&t.dropped, 1)
+	}
+}
+
+// run exports the queued spans whenever a batch fills up or the flush interval elapses
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, t.batchSize)
+	for {
+		select {
+		case span := <-t.queue:
+			batch = append(batch, span)
+			if len(batch) >= t.batchSize {
+				batch = t.export(batch)
+			}
+		case <-ticker.C:
+			batch = t.export(batch)
+		case <-t.stop:
+			for {
+				select {
+				case span := <-t.queue:
+					batch = append(batch, span)
+					if len(batch) >= t.batchSize {
+						batch = t.export(batch)
+					}
+				default:
+					t.export(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// export sends the batch to the exporter and returns an empty batch to fill next
+func (t *Tracer) export(batch []*Span) []*Span {
+	if dropped := atomic.SwapInt64(&t.dropped, 0); dropped > 0 {
+		log.Warnf("Dropped %d spans because the tracing queue was full", dropped)
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := t.exporter.ExportSpans(context.Background(), batch); err != nil {
+		log.Errorf("Failed to export %d spans: %v", len(batch), err)
+	}
+	return make([]*Span, 0, t.batchSize)
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.isValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.isValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
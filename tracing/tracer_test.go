@@ -0,0 +1,209 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingExporter struct {
+	mu       sync.Mutex
+	batches  [][]*Span
+	shutdown bool
+}
+
+func (e *recordingExporter) ExportSpans(ctx context.Context, spans []*Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.batches = append(e.batches, spans)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdown = true
+	return nil
+}
+
+func (e *recordingExporter) spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var spans []*Span
+	for _, batch := range e.batches {
+		spans = append(spans, batch...)
+	}
+	return spans
+}
+
+func newTestTracingConfig() config.Tracing {
+	return config.Tracing{
+		Enabled:             true,
+		ServiceName:         "prebid-cache",
+		SampleRatio:         1,
+		QueueSize:           100,
+		BatchSize:           10,
+		FlushIntervalMillis: 60000,
+	}
+}
+
+func TestStartRequestSpan(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracerWithExporter(newTestTracingConfig(), exporter)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=value")
+	ctx, requestSpan := tracer.StartRequestSpan(context.Background(), "POST /cache", remote)
+	_, backendSpan := StartSpan(ctx, "backend.put", SpanKindClient)
+	backendSpan.SetAttribute("db.system", "memory")
+	backendSpan.SetError(errors.New("connection refused"))
+	backendSpan.End()
+	requestSpan.End()
+	requestSpan.End()
+
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	spans := exporter.spans()
+	if !assert.Len(t, spans, 2, "Ending a span twice must export it once") {
+		return
+	}
+	assert.True(t, exporter.shutdown)
+
+	assert.Equal(t, "backend.put", spans[0].Name)
+	assert.Equal(t, SpanKindClient, spans[0].Kind)
+	assert.Equal(t, remote.TraceID, spans[0].SpanContext.TraceID)
+	assert.Equal(t, requestSpan.SpanContext.SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, map[string]interface{}{"db.system": "memory"}, spans[0].Attributes)
+	assert.Equal(t, "connection refused", spans[0].Err)
+
+	assert.Equal(t, "POST /cache", spans[1].Name)
+	assert.Equal(t, SpanKindServer, spans[1].Kind)
+	assert.Equal(t, remote.TraceID, spans[1].SpanContext.TraceID)
+	assert.Equal(t, remote.SpanID, spans[1].ParentSpanID)
+	assert.Equal(t, "vendor=value", spans[1].SpanContext.TraceState)
+	assert.NotEqual(t, remote.SpanID, spans[1].SpanContext.SpanID)
+	assert.False(t, spans[1].EndTime.Before(spans[1].StartTime))
+}
+
+func TestSampling(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		inSampleRatio    float64
+		inTraceparent    string
+		expectedExported bool
+	}{
+		{
+			desc:             "New trace with a ratio of 1 is exported",
+			inSampleRatio:    1,
+			expectedExported: true,
+		},
+		{
+			desc:             "New trace with a ratio of 0 is not exported",
+			inSampleRatio:    0,
+			expectedExported: false,
+		},
+		{
+			desc:             "Caller sampled the trace. It's exported regardless of the ratio",
+			inSampleRatio:    0,
+			inTraceparent:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expectedExported: true,
+		},
+		{
+			desc:             "Caller didn't sample the trace. It's not exported regardless of the ratio",
+			inSampleRatio:    1,
+			inTraceparent:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			expectedExported: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := newTestTracingConfig()
+		cfg.SampleRatio = tc.inSampleRatio
+		exporter := &recordingExporter{}
+		tracer := NewTracerWithExporter(cfg, exporter)
+
+		remote, _ := ParseTraceparent(tc.inTraceparent, "")
+		ctx, span := tracer.StartRequestSpan(context.Background(), "GET /cache", remote)
+		_, child := StartSpan(ctx, "backend.get", SpanKindClient)
+		child.End()
+		span.End()
+		tracer.Shutdown(context.Background())
+
+		if tc.expectedExported {
+			assert.Len(t, exporter.spans(), 2, tc.desc)
+		} else {
+			assert.Empty(t, exporter.spans(), tc.desc)
+		}
+	}
+}
+
+func TestSampleRatio(t *testing.T) {
+	tracer := &Tracer{sampleRatio: 0.25}
+
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		if tracer.sample(newTraceID()) {
+			sampled++
+		}
+	}
+
+	assert.InDelta(t, 2500, sampled, 300)
+}
+
+func TestBatching(t *testing.T) {
+	cfg := newTestTracingConfig()
+	cfg.BatchSize = 2
+	cfg.FlushIntervalMillis = 20
+	exporter := &recordingExporter{}
+	tracer := NewTracerWithExporter(cfg, exporter)
+	defer tracer.Shutdown(context.Background())
+
+	for i := 0; i < 3; i++ {
+		_, span := tracer.StartRequestSpan(context.Background(), "GET /cache", SpanContext{})
+		span.End()
+	}
+
+	// A full batch is exported right away, the remaining span once the flush interval elapses
+	assert.Eventually(t, func() bool { return len(exporter.spans()) == 3 }, time.Second, 5*time.Millisecond)
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	assert.Len(t, exporter.batches[0], 2)
+}
+
+func TestFullQueueDropsSpans(t *testing.T) {
+	cfg := newTestTracingConfig()
+	cfg.QueueSize = 1
+	tracer := &Tracer{
+		sampleRatio: 1,
+		queue:       make(chan *Span, cfg.QueueSize),
+		stop:        make(chan struct{}),
+	}
+
+	for i := 0; i < 3; i++ {
+		_, span := tracer.StartRequestSpan(context.Background(), "GET /cache", SpanContext{})
+		span.End()
+	}
+
+	assert.Len(t, tracer.queue, 1)
+	assert.Equal(t, int64(2), tracer.dropped)
+}
+
+func TestDisabledTracing(t *testing.T) {
+	tracer, err := NewTracer(config.Tracing{Enabled: false})
+	assert.NoError(t, err)
+	assert.Nil(t, tracer)
+
+	// Nothing is recorded and nothing panics
+	ctx, span := tracer.StartRequestSpan(context.Background(), "GET /cache", SpanContext{})
+	assert.Nil(t, span)
+	_, child := StartSpan(ctx, "backend.get", SpanKindClient)
+	assert.Nil(t, child)
+	child.SetAttribute("db.system", "memory")
+	child.SetError(errors.New("failed"))
+	child.End()
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+}
@@ -0,0 +1,48 @@
+package tracingtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/tracing"
+)
+
+// RecordingExporter keeps the spans it's given in memory, so tests can assert on them
+type RecordingExporter struct {
+	mu    sync.Mutex
+	spans []*tracing.Span
+}
+
+func (e *RecordingExporter) ExportSpans(ctx context.Context, spans []*tracing.Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *RecordingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the spans exported so far, in the order they ended
+func (e *RecordingExporter) Spans() []*tracing.Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*tracing.Span(nil), e.spans...)
+}
+
+// NewTracer returns a tracer that samples every trace and sends the spans to a RecordingExporter.
+// Shut the tracer down to flush its spans before asserting on them.
+func NewTracer() (*tracing.Tracer, *RecordingExporter) {
+	exporter := &RecordingExporter{}
+	tracer := tracing.NewTracerWithExporter(config.Tracing{
+		Enabled:             true,
+		ServiceName:         "prebid-cache",
+		SampleRatio:         1,
+		QueueSize:           100,
+		BatchSize:           10,
+		FlushIntervalMillis: 1000,
+	}, exporter)
+	return tracer, exporter
+}
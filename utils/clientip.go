@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies holds the networks of the load balancers and reverse proxies whose forwarding
+// headers can be believed when resolving the address of the client that originated a request.
+type TrustedProxies struct {
+	networks []*net.IPNet
+}
+
+// NewTrustedProxies parses a list of IP addresses and CIDR blocks. Single IP addresses are
+// treated as /32 or /128 networks.
+func NewTrustedProxies(entries []string) (*TrustedProxies, error) {
+	tp := &TrustedProxies{networks: make([]*net.IPNet, 0, len(entries))}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid CIDR block", entry)
+			}
+			tp.networks = append(tp.networks, network)
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("%q is not a valid IP address", entry)
+		}
+		bits := 128
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 32
+		}
+		tp.networks = append(tp.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+
+	return tp, nil
+}
+
+// Contains returns true if ip belongs to any of the trusted networks
+func (tp *TrustedProxies) Contains(ip net.IP) bool {
+	if tp == nil || ip == nil {
+		return false
+	}
+	for _, network := range tp.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that originated the request. Forwarding headers
+// are only honored when the immediate peer is a trusted proxy. In that case, X-Forwarded-For
+// is walked from right to left and the first address that doesn't belong to a trusted proxy is
+// returned. If every hop is trusted, the left-most one is returned. X-Real-IP is used as a
+// fallback when X-Forwarded-For is not present.
+func (tp *TrustedProxies) ClientIP(r *http.Request) string {
+	remoteIP := parseHostIP(r.RemoteAddr)
+	if remoteIP == nil {
+		return ""
+	}
+	if !tp.Contains(remoteIP) {
+		return remoteIP.String()
+	}
+
+	hops := forwardedFor(r)
+	if len(hops) == 0 {
+		if realIP := parseHostIP(r.Header.Get("X-Real-IP")); realIP != nil {
+			return realIP.String()
+		}
+		return remoteIP.String()
+	}
+
+	clientIP := remoteIP
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseHostIP(hops[i])
+		if hop == nil {
+			// A malformed entry means we can't know what was appended to the left of it.
+			// Stick with the closest hop we could make sense of.
+			break
+		}
+		clientIP = hop
+		if !tp.Contains(hop) {
+			break
+		}
+	}
+	return clientIP.String()
+}
+
+// forwardedFor returns the hops listed in every X-Forwarded-For header of the request, in order
+func forwardedFor(r *http.Request) []string {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// parseHostIP parses an IP address that may come with a port, such as "192.0.2.1:25" or "[2001:db8::1]:80"
+func parseHostIP(address string) net.IP {
+	address = strings.TrimSpace(address)
+	if ip := net.ParseIP(address); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return net.ParseIP(host)
+	}
+	return net.ParseIP(strings.Trim(address, "[]"))
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTrustedProxies(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		in            []string
+		expectedError string
+		trustedIPs    []string
+		untrustedIPs  []string
+	}{
+		{
+			desc:         "No entries, trust nobody",
+			in:           []string{},
+			untrustedIPs: []string{"10.0.0.1", "::1"},
+		},
+		{
+			desc:         "CIDR blocks and single addresses",
+			in:           []string{"10.0.0.0/8", " 192.0.2.10 ", "2001:db8::/32"},
+			trustedIPs:   []string{"10.1.2.3", "192.0.2.10", "2001:db8::1"},
+			untrustedIPs: []string{"192.0.2.11", "11.0.0.1", "2001:db9::1"},
+		},
+		{
+			desc:          "Malformed CIDR block",
+			in:            []string{"10.0.0.0/33"},
+			expectedError: `"10.0.0.0/33" is not a valid CIDR block`,
+		},
+		{
+			desc:          "Malformed IP address",
+			in:            []string{"10.0.0.300"},
+			expectedError: `"10.0.0.300" is not a valid IP address`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tp, err := NewTrustedProxies(tc.in)
+		if len(tc.expectedError) > 0 {
+			assert.EqualError(t, err, tc.expectedError, tc.desc)
+			continue
+		}
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+		for _, ip := range tc.trustedIPs {
+			assert.True(t, tp.Contains(net.ParseIP(ip)), "%s: %s should be trusted", tc.desc, ip)
+		}
+		for _, ip := range tc.untrustedIPs {
+			assert.False(t, tp.Contains(net.ParseIP(ip)), "%s: %s should not be trusted", tc.desc, ip)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		expectedIP   string
+		trustNoProxy bool
+	}{
+		{
+			desc:       "Untrusted peer without forwarding headers",
+			remoteAddr: "203.0.113.5:4321",
+			expectedIP: "203.0.113.5",
+		},
+		{
+			desc:         "Untrusted peer spoofing forwarding headers. Ignore them",
+			remoteAddr:   "203.0.113.5:4321",
+			forwardedFor: []string{"198.51.100.1"},
+			realIP:       "198.51.100.2",
+			expectedIP:   "203.0.113.5",
+		},
+		{
+			desc:         "No trusted proxies configured. Ignore forwarding headers even from private networks",
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"198.51.100.1"},
+			expectedIP:   "10.0.0.2",
+			trustNoProxy: true,
+		},
+		{
+			desc:         "Trusted peer forwards for a single client",
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"198.51.100.1"},
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			desc:         "Client prepends a spoofed address. Walk right to left and stop at the first untrusted hop",
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"1.2.3.4, 198.51.100.1, 10.0.0.3"},
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			desc:         "Hops split across multiple headers",
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"1.2.3.4", "198.51.100.1, 10.0.0.3"},
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			desc:         "Every hop is trusted. Return the left-most one",
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"10.0.0.5, 10.0.0.3"},
+			expectedIP:   "10.0.0.5",
+		},
+		{
+			desc:         "Malformed hop. Return the closest hop that could be parsed",
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"198.51.100.1, not-an-ip, 10.0.0.3"},
+			expectedIP:   "10.0.0.3",
+		},
+		{
+			desc:         "Hops that come with ports",
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: []string{"[2001:db8::1]:443, 10.0.0.3:80"},
+			expectedIP:   "2001:db8::1",
+		},
+		{
+			desc:       "Trusted peer sends X-Real-IP only",
+			remoteAddr: "10.0.0.2:4321",
+			realIP:     "198.51.100.2",
+			expectedIP: "198.51.100.2",
+		},
+		{
+			desc:       "Trusted peer without forwarding headers",
+			remoteAddr: "10.0.0.2:4321",
+			expectedIP: "10.0.0.2",
+		},
+		{
+			desc:       "Unparsable remote address",
+			remoteAddr: "@",
+			expectedIP: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		entries := []string{"10.0.0.0/8"}
+		if tc.trustNoProxy {
+			entries = nil
+		}
+		tp, err := NewTrustedProxies(entries)
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+
+		r := &http.Request{RemoteAddr: tc.remoteAddr, Header: http.Header{}}
+		for _, hops := range tc.forwardedFor {
+			r.Header.Add("X-Forwarded-For", hops)
+		}
+		if len(tc.realIP) > 0 {
+			r.Header.Set("X-Real-IP", tc.realIP)
+		}
+
+		assert.Equal(t, tc.expectedIP, tp.ClientIP(r), tc.desc)
+	}
+}
@@ -13,5 +13,38 @@ const (
 	RATE_LIMITER_NUM_REQUESTS        = 100
 	REQUEST_MAX_SIZE_BYTES           = 10 * 1024
 	REQUEST_MAX_NUM_VALUES           = 10
+	REQUEST_MAX_BODY_SIZE_BYTES      = 1024 * 1024
+	REQUEST_MAX_DECOMPRESSED_BYTES   = 1024 * 1024
 	REQUEST_MAX_TTL_SECONDS          = 3600
+	PROXY_PROTOCOL_HEADER_TIMEOUT_MS = 5000
+	SHUTDOWN_DRAIN_TIMEOUT_MS        = 10000
+	SHUTDOWN_CLOSE_TIMEOUT_MS        = 5000
+	TLS_RELOAD_INTERVAL_SECONDS      = 60
+	MAIN_SERVER_TIMEOUT_MS           = 15000
+	TCP_KEEP_ALIVE_PERIOD_SECONDS    = 180
+	TRACING_QUEUE_SIZE               = 2048
+	TRACING_BATCH_SIZE               = 512
+	TRACING_FLUSH_INTERVAL_MS        = 5000
+	TRACING_OTLP_TIMEOUT_MS          = 10000
+	STATSD_PORT                      = 8125
+	STATSD_FLUSH_INTERVAL_MS         = 100
+	STATSD_MAX_PACKET_BYTES          = 1432
+	INFLUX_FLUSH_INTERVAL_MS         = 10000
+	INFLUX_BUFFER_SIZE               = 10000
+	INFLUX_MAX_RETRIES               = 3
+	INFLUX_RETRY_BACKOFF_MS          = 1000
+	PEER_TIMEOUT_MS                  = 200
+	PEER_MAX_RESPONSE_SIZE_BYTES     = 1024 * 1024
+)
+
+// The following histogram buckets serve as configuration defaults for the Prometheus metrics
+var (
+	// Duration buckets in seconds, from one millisecond to one second
+	PROMETHEUS_DURATION_BUCKETS = []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1}
+	// Size buckets in bytes, from empty values up to 1MB
+	PROMETHEUS_SIZE_BUCKETS = []float64{0, 4096, 8192, 16384, 32768, 65536, 131072, 262144, 524288, 1048576}
+	// TTL buckets for 1 second, half a minute as well as one, ten, fifteen, thirty minutes and 1, 2, and 3 and 10 hours
+	PROMETHEUS_TTL_BUCKETS = []float64{0.001, 1, 30, 60, 600, 900, 1800, 3600, 7200, 10800, 36000}
+	// Age buckets for 1, 5 and 30 seconds, one, five, ten, fifteen and thirty minutes, and 1, 2, 3 and 10 hours
+	PROMETHEUS_AGE_BUCKETS = []float64{1, 5, 30, 60, 300, 600, 900, 1800, 3600, 7200, 10800, 36000}
 )
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"net/http"
 )
 
@@ -15,6 +16,8 @@ const (
 	UNSUPPORTED_DATA_TO_STORE        // PUT http.StatusBadRequest 400
 	MISSING_VALUE                    // PUT http.StatusBadRequest 400
 	BAD_PAYLOAD_SIZE                 // PUT http.StatusBadRequest 400
+	PUT_BODY_TOO_LARGE               // PUT http.StatusRequestEntityTooLarge 413
+	PUT_UNSUPPORTED_ENCODING         // PUT http.StatusUnsupportedMediaType 415
 	KEY_NOT_FOUND                    // GET http.StatusNotFound 404
 	KEY_LENGTH                       // GET http.StatusNotFound 404
 	UNKNOWN_STORED_DATA_TYPE         // GET http.StatusInternalServerError 500
@@ -37,6 +40,8 @@ var errToStatusCodes map[int]int = map[int]int{
 	UNSUPPORTED_DATA_TO_STORE: http.StatusBadRequest,
 	MISSING_VALUE:             http.StatusBadRequest,
 	BAD_PAYLOAD_SIZE:          http.StatusBadRequest,
+	PUT_BODY_TOO_LARGE:        http.StatusRequestEntityTooLarge,
+	PUT_UNSUPPORTED_ENCODING:  http.StatusUnsupportedMediaType,
 	UNKNOWN_STORED_DATA_TYPE:  http.StatusInternalServerError,
 	PUT_INTERNAL_SERVER:       http.StatusInternalServerError,
 	MARSHAL_RESPONSE:          http.StatusInternalServerError,
@@ -59,11 +64,36 @@ var errToMsgs map[int]string = map[int]string{
 	PUT_DEADLINE_EXCEEDED:    "timeout writing value to the backend.",
 }
 
+// Map Prebid Cache's error types to the stable codes written in the JSON error responses
+var errToCodes map[int]string = map[int]string{
+	MISSING_KEY:               "MISSING_KEY",
+	RECORD_EXISTS:             "RECORD_EXISTS",
+	PUT_MAX_NUM_VALUES:        "PUT_MAX_NUM_VALUES",
+	PUT_BAD_REQUEST:           "PUT_BAD_REQUEST",
+	NEGATIVE_TTL:              "NEGATIVE_TTL",
+	MALFORMED_XML:             "MALFORMED_XML",
+	UNSUPPORTED_DATA_TO_STORE: "UNSUPPORTED_DATA_TO_STORE",
+	MISSING_VALUE:             "MISSING_VALUE",
+	BAD_PAYLOAD_SIZE:          "BAD_PAYLOAD_SIZE",
+	PUT_BODY_TOO_LARGE:        "PUT_BODY_TOO_LARGE",
+	PUT_UNSUPPORTED_ENCODING:  "PUT_UNSUPPORTED_ENCODING",
+	KEY_NOT_FOUND:             "KEY_NOT_FOUND",
+	KEY_LENGTH:                "KEY_LENGTH",
+	UNKNOWN_STORED_DATA_TYPE:  "UNKNOWN_STORED_DATA_TYPE",
+	PUT_INTERNAL_SERVER:       "PUT_INTERNAL_SERVER",
+	MARSHAL_RESPONSE:          "MARSHAL_RESPONSE",
+	PUT_DEADLINE_EXCEEDED:     "PUT_DEADLINE_EXCEEDED",
+}
+
+// InternalErrorCode is the code of the errors that aren't PBCErrors
+const InternalErrorCode = "INTERNAL_ERROR"
+
 // PBCError implements the error interface
 type PBCError struct {
 	Type       int
 	StatusCode int
 	msg        string
+	cause      error
 }
 
 // NewPBCError returns an error with either a custom error message or not. The only
@@ -91,6 +121,14 @@ func NewPBCError(errType int, msgs ...string) PBCError {
 	return re
 }
 
+// WrapPBCError returns an error of type errType caused by cause, which errors.Is and errors.As
+// can find. As with NewPBCError, a custom error message can be given.
+func WrapPBCError(errType int, cause error, msgs ...string) PBCError {
+	re := NewPBCError(errType, msgs...)
+	re.cause = cause
+	return re
+}
+
 // Error() implementation
 func (e PBCError) Error() string {
 	// If msg field was populated, use it
@@ -103,7 +141,42 @@ func (e PBCError) Error() string {
 		return msg
 	}
 
+	// Fall back to the message of the cause, if any
+	if e.cause != nil {
+		return e.cause.Error()
+	}
+
 	// If we couldn't find an error message for this errType and error
 	// didn't come with a msg field, return an empty string
 	return ""
 }
+
+// Unwrap returns the error that caused this one, if any
+func (e PBCError) Unwrap() error {
+	return e.cause
+}
+
+// Is tells errors.Is that a PBCError matches any other PBCError of the same type, whatever
+// their messages are
+func (e PBCError) Is(target error) bool {
+	t, ok := target.(PBCError)
+	return ok && t.Type == e.Type
+}
+
+// Code returns the stable string code of the error type
+func (e PBCError) Code() string {
+	if code, exists := errToCodes[e.Type]; exists {
+		return code
+	}
+	return InternalErrorCode
+}
+
+// ErrorCode returns the code of the first PBCError in the chain of err, or InternalErrorCode if
+// there's none
+func ErrorCode(err error) string {
+	var pbcErr PBCError
+	if errors.As(err, &pbcErr) {
+		return pbcErr.Code()
+	}
+	return InternalErrorCode
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -90,3 +93,55 @@ func TestPBCError(t *testing.T) {
 		assert.Equal(t, tc.expected.Error(), pbcError.Error(), tc.desc)
 	}
 }
+
+func TestPBCErrorWrapping(t *testing.T) {
+	cause := context.DeadlineExceeded
+	wrapped := fmt.Errorf("element 2: %w", WrapPBCError(PUT_DEADLINE_EXCEEDED, cause))
+
+	assert.True(t, errors.Is(wrapped, cause), "The cause is found through the wrapping errors")
+	assert.True(t, errors.Is(wrapped, NewPBCError(PUT_DEADLINE_EXCEEDED)), "PBCErrors of the same type match")
+	assert.False(t, errors.Is(wrapped, NewPBCError(PUT_INTERNAL_SERVER)), "PBCErrors of other types don't")
+
+	var pbcErr PBCError
+	if assert.True(t, errors.As(wrapped, &pbcErr)) {
+		assert.Equal(t, PUT_DEADLINE_EXCEEDED, pbcErr.Type)
+		assert.Equal(t, HTTPDependencyTimeout, pbcErr.StatusCode)
+		assert.Equal(t, "timeout writing value to the backend.", pbcErr.Error())
+	}
+
+	assert.Equal(t, "Server memory error", WrapPBCError(PUT_INTERNAL_SERVER, errors.New("Server memory error")).Error(), "The message of the cause is used if there's no other")
+	assert.Equal(t, "custom", WrapPBCError(PUT_INTERNAL_SERVER, errors.New("Server memory error"), "custom").Error())
+}
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		inError      error
+		expectedCode string
+	}{
+		{
+			desc:         "PBCError",
+			inError:      NewPBCError(KEY_NOT_FOUND),
+			expectedCode: "KEY_NOT_FOUND",
+		},
+		{
+			desc:         "Wrapped PBCError",
+			inError:      fmt.Errorf("wrapped: %w", NewPBCError(RECORD_EXISTS)),
+			expectedCode: "RECORD_EXISTS",
+		},
+		{
+			desc:         "PBCError of an unknown type",
+			inError:      NewPBCError(100),
+			expectedCode: InternalErrorCode,
+		},
+		{
+			desc:         "Other error",
+			inError:      errors.New("Server memory error"),
+			expectedCode: InternalErrorCode,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedCode, ErrorCode(tc.inError), tc.desc)
+	}
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// IDGenerator generates the keys of the values put without a key of their own. Valid is meant as
+// a quick check of the keys of get requests, which filters out most of the keys the generator
+// couldn't have returned before they're looked up in the backend.
+type IDGenerator interface {
+	// NewID returns a new unique ID
+	NewID() (string, error)
+	// ContentID returns the ID derived from a value, type prefix included, and its time-to-live.
+	// Values put with the same type, content and time-to-live get the same ID.
+	ContentID(value string, ttlSeconds int) string
+	// Valid tells whether the ID has the shape of the IDs the generator returns
+	Valid(id string) bool
+}
+
+// contentNamespace is the namespace of the name based UUIDs derived from the content of values
+var contentNamespace = uuid.NewV5(uuid.NamespaceURL, "https://prebid.org/prebid-cache/content")
+
+// uuidGenerator generates UUIDs of the version newUUID returns. Content IDs are version 5 UUIDs.
+type uuidGenerator struct {
+	newUUID func() (uuid.UUID, error)
+}
+
+// NewUUIDv4Generator returns a generator of random, version 4 UUIDs
+func NewUUIDv4Generator() IDGenerator {
+	return uuidGenerator{newUUID: uuid.NewV4}
+}
+
+// NewUUIDv7Generator returns a generator of version 7 UUIDs, as described in RFC 9562. They start
+// with the time they were generated at, so they sort in the order they were generated in.
+func NewUUIDv7Generator() IDGenerator {
+	return uuidGenerator{newUUID: newUUIDv7}
+}
+
+func (g uuidGenerator) NewID() (string, error) {
+	u, err := g.newUUID()
+	return u.String(), err
+}
+
+func (g uuidGenerator) ContentID(value string, ttlSeconds int) string {
+	return uuid.NewV5(contentNamespace, contentName(value, ttlSeconds)).String()
+}
+
+// Valid only checks that the ID is 36 characters long, so any version of UUID is valid. Values
+// stored under UUIDs of another version, before the generator changed, can still be read.
+func (g uuidGenerator) Valid(id string) bool {
+	return len(id) == 36
+}
+
+// newUUIDv7 returns a UUID made of the Unix time in milliseconds followed by random bits
+func newUUIDv7() (uuid.UUID, error) {
+	var u uuid.UUID
+	if _, err := rand.Read(u[6:]); err != nil {
+		return uuid.Nil, err
+	}
+	putMillis(u[:6], time.Now())
+	u.SetVersion(uuid.V7)
+	u.SetVariant(uuid.VariantRFC4122)
+	return u, nil
+}
+
+// crockfordAlphabet is the Base32 alphabet ULIDs are encoded with
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator generates ULIDs: 26 characters that encode the Unix time in milliseconds followed
+// by 80 random bits. Content IDs encode a hash of the content instead.
+type ulidGenerator struct{}
+
+// NewULIDGenerator returns a generator of ULIDs, which sort in the order they were generated in
+func NewULIDGenerator() IDGenerator {
+	return ulidGenerator{}
+}
+
+func (g ulidGenerator) NewID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[6:]); err != nil {
+		return "", err
+	}
+	putMillis(id[:6], time.Now())
+	return encodeULID(id), nil
+}
+
+func (g ulidGenerator) ContentID(value string, ttlSeconds int) string {
+	var id [16]byte
+	sum := sha256.Sum256([]byte(contentName(value, ttlSeconds)))
+	copy(id[:], sum[:])
+	return encodeULID(id)
+}
+
+// Valid checks that the ID is made of 26 characters of the ULID alphabet and that it doesn't encode
+// more than 128 bits. Lowercase IDs are rejected: keys are stored as generated, in uppercase, so
+// they would never be found.
+func (g ulidGenerator) Valid(id string) bool {
+	if len(id) != 26 || id[0] > '7' {
+		return false
+	}
+	for _, c := range id {
+		if !strings.ContainsRune(crockfordAlphabet, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeULID encodes the 128 bits of the ID in 26 characters of 5 bits, starting with the most
+// significant ones. The first character only holds 3 bits.
+func encodeULID(id [16]byte) string {
+	var encoded [26]byte
+	for i := range encoded {
+		// Position of the least significant bit of the character, counting from the end of the ID
+		lowBit := (len(encoded) - 1 - i) * 5
+		var value byte
+		for bit := 0; bit < 5 && lowBit+bit < 128; bit++ {
+			position := lowBit + bit
+			if id[15-position/8]>>(uint(position)%8)&1 == 1 {
+				value |= 1 << uint(bit)
+			}
+		}
+		encoded[i] = crockfordAlphabet[value]
+	}
+	return string(encoded[:])
+}
+
+// putMillis writes the Unix time in milliseconds in the 6 bytes of dst, in big endian order
+func putMillis(dst []byte, t time.Time) {
+	millis := uint64(t.UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		dst[i] = byte(millis)
+		millis >>= 8
+	}
+}
+
+// contentName is what content IDs are derived from
+func contentName(value string, ttlSeconds int) string {
+	return strconv.Itoa(ttlSeconds) + ":" + value
+}
+
+// prefixedGenerator prepends a prefix, such as the name of a datacenter or a shard, to the IDs of
+// another generator
+type prefixedGenerator struct {
+	IDGenerator
+	prefix string
+}
+
+// WithIDPrefix returns a generator of the IDs of the generator, prepended with the prefix. Only
+// IDs that start with the prefix are valid.
+func WithIDPrefix(generator IDGenerator, prefix string) IDGenerator {
+	if prefix == "" {
+		return generator
+	}
+	return prefixedGenerator{IDGenerator: generator, prefix: prefix}
+}
+
+func (g prefixedGenerator) NewID() (string, error) {
+	id, err := g.IDGenerator.NewID()
+	if err != nil {
+		return "", err
+	}
+	return g.prefix + id, nil
+}
+
+func (g prefixedGenerator) ContentID(value string, ttlSeconds int) string {
+	return g.prefix + g.IDGenerator.ContentID(value, ttlSeconds)
+}
+
+func (g prefixedGenerator) Valid(id string) bool {
+	return strings.HasPrefix(id, g.prefix) && g.IDGenerator.Valid(id[len(g.prefix):])
+}
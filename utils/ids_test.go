@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUUIDGenerators(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		generator       IDGenerator
+		expectedVersion byte
+	}{
+		{
+			desc:            "Version 4",
+			generator:       NewUUIDv4Generator(),
+			expectedVersion: uuid.V4,
+		},
+		{
+			desc:            "Version 7",
+			generator:       NewUUIDv7Generator(),
+			expectedVersion: uuid.V7,
+		},
+	}
+
+	for _, tc := range testCases {
+		id, err := tc.generator.NewID()
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+		parsed, err := uuid.FromString(id)
+		if assert.NoError(t, err, tc.desc) {
+			assert.Equal(t, tc.expectedVersion, parsed.Version(), tc.desc)
+			assert.Equal(t, byte(uuid.VariantRFC4122), parsed.Variant(), tc.desc)
+		}
+		assert.True(t, tc.generator.Valid(id), tc.desc)
+
+		contentID := tc.generator.ContentID("xml<VAST/>", 60)
+		parsed, err = uuid.FromString(contentID)
+		if assert.NoError(t, err, tc.desc) {
+			assert.Equal(t, byte(uuid.V5), parsed.Version(), tc.desc)
+		}
+		assert.True(t, tc.generator.Valid(contentID), tc.desc)
+	}
+}
+
+func TestUUIDv7Order(t *testing.T) {
+	generator := NewUUIDv7Generator()
+
+	before := time.Now().UnixNano() / int64(time.Millisecond)
+	first, _ := generator.NewID()
+	time.Sleep(2 * time.Millisecond)
+	second, _ := generator.NewID()
+	after := time.Now().UnixNano() / int64(time.Millisecond)
+
+	assert.True(t, first < second, "Later IDs must sort after earlier ones")
+	parsed, err := uuid.FromString(first)
+	if assert.NoError(t, err) {
+		var millis int64
+		for _, b := range parsed[:6] {
+			millis = millis<<8 | int64(b)
+		}
+		assert.True(t, millis >= before && millis <= after, "The ID must start with the time it was generated at")
+	}
+}
+
+func TestULIDGenerator(t *testing.T) {
+	generator := NewULIDGenerator()
+
+	first, err := generator.NewID()
+	assert.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	second, err := generator.NewID()
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 26)
+	assert.True(t, generator.Valid(first))
+	assert.True(t, first < second, "Later IDs must sort after earlier ones")
+	assert.True(t, generator.Valid(generator.ContentID("xml<VAST/>", 60)))
+}
+
+func TestEncodeULID(t *testing.T) {
+	// The timestamp of the example of the ULID specification
+	var id [16]byte
+	putMillis(id[:6], time.Unix(0, 1469918176385*int64(time.Millisecond)))
+	assert.Equal(t, "01ARYZ6S41", encodeULID(id)[:10])
+
+	var max [16]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	assert.Equal(t, "00000000000000000000000000", encodeULID([16]byte{}))
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID(max))
+}
+
+func TestIDValidation(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		generator IDGenerator
+		inID      string
+		expected  bool
+	}{
+		{
+			desc:      "UUID",
+			generator: NewUUIDv4Generator(),
+			inID:      "fdd9405b-ef2b-46da-a55a-2f526d338e16",
+			expected:  true,
+		},
+		{
+			desc:      "UUID of another version",
+			generator: NewUUIDv7Generator(),
+			inID:      "fdd9405b-ef2b-46da-a55a-2f526d338e16",
+			expected:  true,
+		},
+		{
+			desc:      "Too short for a UUID",
+			generator: NewUUIDv4Generator(),
+			inID:      "fdd9405b-ef2b-46da-a55a",
+		},
+		{
+			desc:      "ULID",
+			generator: NewULIDGenerator(),
+			inID:      "01ARYZ6S41TSV4RRFFQ69G5FAV",
+			expected:  true,
+		},
+		{
+			desc:      "Lower case ULID",
+			generator: NewULIDGenerator(),
+			inID:      "01aryz6s41tsv4rrffq69g5fav",
+		},
+		{
+			desc:      "ULID with a character out of its alphabet",
+			generator: NewULIDGenerator(),
+			inID:      "01ARYZ6S41TSV4RRFFQ69G5FAU",
+		},
+		{
+			desc:      "ULID larger than 128 bits",
+			generator: NewULIDGenerator(),
+			inID:      "81ARYZ6S41TSV4RRFFQ69G5FAV",
+		},
+		{
+			desc:      "UUID where a ULID is expected",
+			generator: NewULIDGenerator(),
+			inID:      "fdd9405b-ef2b-46da-a55a-2f526d338e16",
+		},
+		{
+			desc:      "Prefixed ULID",
+			generator: WithIDPrefix(NewULIDGenerator(), "use1-"),
+			inID:      "use1-01ARYZ6S41TSV4RRFFQ69G5FAV",
+			expected:  true,
+		},
+		{
+			desc:      "ULID without the prefix",
+			generator: WithIDPrefix(NewULIDGenerator(), "use1-"),
+			inID:      "01ARYZ6S41TSV4RRFFQ69G5FAV",
+		},
+		{
+			desc:      "ULID with another prefix",
+			generator: WithIDPrefix(NewULIDGenerator(), "use1-"),
+			inID:      "euw1-01ARYZ6S41TSV4RRFFQ69G5FAV",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.generator.Valid(tc.inID), tc.desc)
+	}
+}
+
+func TestPrefixedIDs(t *testing.T) {
+	generator := WithIDPrefix(NewUUIDv4Generator(), "use1-")
+
+	id, err := generator.NewID()
+	assert.NoError(t, err)
+	assert.Regexp(t, "^use1-[0-9a-f-]{36}$", id)
+	assert.True(t, generator.Valid(id))
+
+	contentID := generator.ContentID("xml<VAST/>", 60)
+	assert.Equal(t, "use1-"+NewUUIDv4Generator().ContentID("xml<VAST/>", 60), contentID)
+	assert.True(t, generator.Valid(contentID))
+
+	assert.Equal(t, NewULIDGenerator(), WithIDPrefix(NewULIDGenerator(), ""), "An empty prefix leaves the generator as it is")
+}
+
+func TestContentIDs(t *testing.T) {
+	for _, generator := range []IDGenerator{NewUUIDv4Generator(), NewULIDGenerator()} {
+		id := generator.ContentID("xml<VAST/>", 60)
+
+		assert.Equal(t, id, generator.ContentID("xml<VAST/>", 60), "The same value and time-to-live must get the same ID")
+		assert.NotEqual(t, id, generator.ContentID("json<VAST/>", 60), "Values of different types must get different IDs")
+		assert.NotEqual(t, id, generator.ContentID("xml<VAST/>", 300), "Values with different time-to-live must get different IDs")
+	}
+}
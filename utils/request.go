@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader carries the ID that ties the log lines of a request together
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+type requestStatsKey struct{}
+
+type jsonErrorsKey struct{}
+
+type valueExpirationKey struct{}
+
+type internalReadKey struct{}
+
+// WithRequestID returns a copy of ctx that carries the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the ID of the request ctx belongs to, or an empty string
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithJSONErrors returns a copy of ctx that asks for the errors of the request to be written as JSON
+func WithJSONErrors(ctx context.Context) context.Context {
+	return context.WithValue(ctx, jsonErrorsKey{}, true)
+}
+
+// JSONErrors tells whether the errors of the request ctx belongs to should be written as JSON
+func JSONErrors(ctx context.Context) bool {
+	jsonErrors, _ := ctx.Value(jsonErrorsKey{}).(bool)
+	return jsonErrors
+}
+
+// WithInternalRead returns a copy of ctx whose backend reads are made by Prebid Cache itself rather
+// than for a client, so they're left out of the get metrics
+func WithInternalRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalReadKey{}, true)
+}
+
+// InternalRead tells whether the backend reads of ctx are made by Prebid Cache itself
+func InternalRead(ctx context.Context) bool {
+	internalRead, _ := ctx.Value(internalReadKey{}).(bool)
+	return internalRead
+}
+
+// Logger returns a logger that adds the ID of the request ctx belongs to, if any, to every line
+func Logger(ctx context.Context) *log.Entry {
+	entry := log.NewEntry(log.StandardLogger())
+	if requestID := RequestID(ctx); requestID != "" {
+		entry = entry.WithField("request_id", requestID)
+	}
+	return entry
+}
+
+// RequestStats collects what a request did for the access log. It's safe for concurrent use
+// since the values of a put request are written to the backend in parallel.
+type RequestStats struct {
+	numPuts            int64
+	backendNanoseconds int64
+}
+
+// NumPuts returns the number of values the request asked to store
+func (s *RequestStats) NumPuts() int {
+	return int(atomic.LoadInt64(&s.numPuts))
+}
+
+// BackendDuration returns the time spent in backend calls, added up
+func (s *RequestStats) BackendDuration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.backendNanoseconds))
+}
+
+// WithRequestStats returns a copy of ctx that collects the stats of its request in stats
+func WithRequestStats(ctx context.Context, stats *RequestStats) context.Context {
+	return context.WithValue(ctx, requestStatsKey{}, stats)
+}
+
+// RecordNumPuts stores the number of values the request of ctx asked to store
+func RecordNumPuts(ctx context.Context, numPuts int) {
+	if stats, ok := ctx.Value(requestStatsKey{}).(*RequestStats); ok {
+		atomic.StoreInt64(&stats.numPuts, int64(numPuts))
+	}
+}
+
+// RecordBackendDuration adds the duration of a backend call to the stats of the request of ctx
+func RecordBackendDuration(ctx context.Context, duration time.Duration) {
+	if stats, ok := ctx.Value(requestStatsKey{}).(*RequestStats); ok {
+		atomic.AddInt64(&stats.backendNanoseconds, int64(duration))
+	}
+}
+
+// ValueExpiration collects when the value read by a get request expires, if the backend knows
+type ValueExpiration struct {
+	expiresAt time.Time
+}
+
+// ExpiresAt returns the time the value expires at, and false if the backend didn't tell
+func (e *ValueExpiration) ExpiresAt() (time.Time, bool) {
+	return e.expiresAt, !e.expiresAt.IsZero()
+}
+
+// WithValueExpiration returns a copy of ctx that collects the expiration of the value its request
+// reads in expiration
+func WithValueExpiration(ctx context.Context, expiration *ValueExpiration) context.Context {
+	return context.WithValue(ctx, valueExpirationKey{}, expiration)
+}
+
+// RecordValueExpiration stores the time the value read by the request of ctx expires at
+func RecordValueExpiration(ctx context.Context, expiresAt time.Time) {
+	if expiration, ok := ctx.Value(valueExpirationKey{}).(*ValueExpiration); ok {
+		expiration.expiresAt = expiresAt
+	}
+}
+
+// DetachContext returns a context that carries the values of ctx, such as the request ID, stats
+// and trace span, but that is not canceled when ctx is and has no deadline. Backend calls use it
+// so a client going away doesn't interrupt them.
+func DetachContext(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
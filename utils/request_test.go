@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+	assert.NotContains(t, Logger(context.Background()).Data, "request_id")
+
+	ctx := WithRequestID(context.Background(), "request-1")
+	assert.Equal(t, "request-1", RequestID(ctx))
+	assert.Equal(t, "request-1", Logger(ctx).Data["request_id"])
+}
+
+func TestRequestStats(t *testing.T) {
+	// Without stats in the context, recording is a no-op
+	RecordNumPuts(context.Background(), 3)
+	RecordBackendDuration(context.Background(), time.Second)
+
+	stats := &RequestStats{}
+	ctx := WithRequestStats(context.Background(), stats)
+	RecordNumPuts(ctx, 3)
+	RecordBackendDuration(ctx, 2*time.Millisecond)
+	RecordBackendDuration(ctx, 3*time.Millisecond)
+
+	assert.Equal(t, 3, stats.NumPuts())
+	assert.Equal(t, 5*time.Millisecond, stats.BackendDuration())
+}
+
+func TestValueExpiration(t *testing.T) {
+	// Without an expiration in the context, recording is a no-op
+	RecordValueExpiration(context.Background(), time.Now())
+
+	expiration := &ValueExpiration{}
+	_, known := expiration.ExpiresAt()
+	assert.False(t, known)
+
+	expiresAt := time.Date(2022, 5, 4, 10, 12, 12, 0, time.UTC)
+	RecordValueExpiration(WithValueExpiration(context.Background(), expiration), expiresAt)
+	recorded, known := expiration.ExpiresAt()
+	assert.True(t, known)
+	assert.Equal(t, expiresAt, recorded)
+}
+
+func TestInternalRead(t *testing.T) {
+	assert.False(t, InternalRead(context.Background()))
+	assert.True(t, InternalRead(WithInternalRead(context.Background())))
+	assert.True(t, InternalRead(DetachContext(WithInternalRead(context.Background()))), "Detached contexts keep the flag")
+}
+
+func TestDetachContext(t *testing.T) {
+	type otherKey struct{}
+	stats := &RequestStats{}
+	ctx := context.WithValue(WithRequestStats(WithRequestID(context.Background(), "request-1"), stats), otherKey{}, "other")
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	cancel()
+
+	detached := DetachContext(ctx)
+
+	assert.NoError(t, detached.Err(), "Canceling the request must not cancel the detached context")
+	_, hasDeadline := detached.Deadline()
+	assert.False(t, hasDeadline, "The deadline of the request must not be inherited")
+	assert.Equal(t, "other", detached.Value(otherKey{}), "Every value of the request must be kept")
+	assert.Equal(t, "request-1", RequestID(detached))
+	RecordBackendDuration(detached, time.Millisecond)
+	assert.Equal(t, time.Millisecond, stats.BackendDuration())
+}
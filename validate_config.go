@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/prebid/prebid-cache/config"
+)
+
+// validateConfigCommand makes Prebid Cache check its configuration and exit instead of starting the servers
+const validateConfigCommand = "validate-config"
+
+// validateConfig loads the configuration the same way the servers would, either from the file at
+// the optional path in args or from the default locations, and merges it with the PBC_ environment
+// variables. The effective configuration is written to stdout with its secrets redacted, followed
+// by every validation error. The returned value is the exit code of the program.
+func validateConfig(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 1 {
+		fmt.Fprintf(stderr, "Usage: prebid-cache %s [path]\n", validateConfigCommand)
+		return 2
+	}
+
+	// Keep stdout for the configuration so it can be piped somewhere else
+	log.SetOutput(stderr)
+
+	var cfg config.Configuration
+	var err error
+	if len(args) == 1 {
+		cfg, err = config.LoadConfigFile(args[0])
+	} else {
+		cfg, err = config.LoadConfig(configFileName)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+
+	validationErr := cfg.ValidateAndLog()
+
+	out, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to print the configuration: %v\n", err)
+		return 1
+	}
+	stdout.Write(out)
+
+	if validationErr == nil {
+		fmt.Fprintln(stdout, "# Configuration is valid")
+		return 0
+	}
+
+	errs, ok := validationErr.(config.ValidationErrors)
+	if !ok {
+		errs = config.ValidationErrors{validationErr}
+	}
+	fmt.Fprintf(stdout, "# Configuration has %d error(s):\n", len(errs))
+	for _, err := range errs {
+		fmt.Fprintf(stdout, "#   - %s\n", err.Error())
+	}
+	return 1
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	defer log.SetOutput(os.Stderr)
+
+	dir, err := ioutil.TempDir("", "validate-config")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	validFile := filepath.Join(dir, "valid.yaml")
+	invalidFile := filepath.Join(dir, "invalid.yaml")
+	ioutil.WriteFile(validFile, []byte("backend:\n  type: redis\n  redis:\n    host: 127.0.0.1\n    port: 6379\n    password: s3cret\n"), 0600)
+	ioutil.WriteFile(invalidFile, []byte("request_limits:\n  max_size_bytes: -1\ncompression:\n  type: zip\n"), 0600)
+
+	testCases := []struct {
+		desc             string
+		args             []string
+		expectedExitCode int
+		expectedStdout   []string
+		unexpectedStdout []string
+		expectedStderr   string
+	}{
+		{
+			desc:             "Valid file. Secrets are redacted",
+			args:             []string{validFile},
+			expectedExitCode: 0,
+			expectedStdout:   []string{"type: redis", "password: '[REDACTED]'", "# Configuration is valid"},
+			unexpectedStdout: []string{"s3cret"},
+		},
+		{
+			desc:             "Invalid file. Every error is listed",
+			args:             []string{invalidFile},
+			expectedExitCode: 1,
+			expectedStdout: []string{
+				"max_size_bytes: -1",
+				"# Configuration has 2 error(s):",
+				"#   - invalid config.request_limits.max_size_bytes: -1. Value cannot be negative.",
+				`#   - invalid config.compression.type: zip. It must be "none" or "snappy"`,
+			},
+		},
+		{
+			desc:             "Missing file",
+			args:             []string{filepath.Join(dir, "missing.yaml")},
+			expectedExitCode: 1,
+			expectedStderr:   "Configuration file could not be read:",
+		},
+		{
+			desc:             "Too many arguments",
+			args:             []string{validFile, invalidFile},
+			expectedExitCode: 2,
+			expectedStderr:   "Usage: prebid-cache validate-config [path]",
+		},
+	}
+
+	for _, tc := range testCases {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		exitCode := validateConfig(tc.args, stdout, stderr)
+
+		assert.Equal(t, tc.expectedExitCode, exitCode, tc.desc)
+		for _, expected := range tc.expectedStdout {
+			assert.Contains(t, stdout.String(), expected, tc.desc)
+		}
+		for _, unexpected := range tc.unexpectedStdout {
+			assert.NotContains(t, stdout.String(), unexpected, tc.desc)
+		}
+		assert.True(t, strings.Contains(stderr.String(), tc.expectedStderr), tc.desc)
+	}
+}